@@ -0,0 +1,196 @@
+// Package argocd generates the Argo CD Application of a component in an
+// environment from its conf.yaml.
+package argocd
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gitpkg/deploycheck"
+	"gitpkg/inventory"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultRepoURL is the values repository the applications are rendered from.
+	DefaultRepoURL = "https://github.com/qlik-trial/helm-environment-overrides"
+	// DefaultHeoRoot is the directory of the values repository holding the
+	// components when conf.yaml does not set heoRoot.
+	DefaultHeoRoot = "qcs"
+)
+
+// Options configures the generated applications. Empty fields take the
+// defaults of GetDefaultOptions.
+type Options struct {
+	// RepoURL is the values repository the source points to.
+	RepoURL string
+	// Project is the Argo CD project of the applications.
+	Project string
+	// Namespace is the namespace the Application resources are created in.
+	Namespace string
+	// Server is the API server of the destination cluster.
+	Server string
+	// Plugin is the config management plugin rendering the values with gomplate and Helm.
+	Plugin string
+}
+
+// GetDefaultOptions returns the options used for the empty fields of Options.
+func GetDefaultOptions() Options {
+	return Options{
+		RepoURL:   DefaultRepoURL,
+		Project:   "default",
+		Namespace: "argocd",
+		Server:    "https://kubernetes.default.svc",
+		Plugin:    "gomplate-helm",
+	}
+}
+
+func (opts Options) withDefaults() Options {
+	def := GetDefaultOptions()
+	for _, f := range []struct{ value, def *string }{
+		{&opts.RepoURL, &def.RepoURL},
+		{&opts.Project, &def.Project},
+		{&opts.Namespace, &def.Namespace},
+		{&opts.Server, &def.Server},
+		{&opts.Plugin, &def.Plugin},
+	} {
+		if *f.value == "" {
+			*f.value = *f.def
+		}
+	}
+	return opts
+}
+
+// Marshal returns the Application as YAML.
+func (a *Application) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(a); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ParseConfig decodes a conf.yaml.
+func ParseConfig(data []byte) (*deploycheck.ConfigFile, error) {
+	return deploycheck.ParseConfig(data)
+}
+
+// Name returns the Application name of a component in an environment.
+func Name(component, environment string) string {
+	return component + "-" + environment
+}
+
+// Generate returns the Application of component in env. The source is the
+// component directory of the values repository at heoRevision, rendered by
+// the plugin for the chart version, and the destination is the namespace of
+// conf.yaml. The override fields of conf.yaml take precedence.
+//
+// The Redis delete hooks enabled in conf.yaml are passed to the plugin as
+// ENABLE_ARGO_HOOK_DELETE_REDIS and ENABLE_ARGO_HOOK_DELETE_REDIS_FORCE, so
+// the chart renders them as PreSync hooks of the Application itself.
+//
+// Applications of environments that are not onboarded are not synced
+// automatically, so Argo CD deploys nothing there until onboarded is true.
+func Generate(conf *deploycheck.ConfigFile, component string, env inventory.Environment, opts Options) (*Application, error) {
+	opts = opts.withDefaults()
+	version := firstNonEmpty(conf.VersionOverride, conf.Version)
+	if version == "" {
+		return nil, fmt.Errorf("version of %s in %s is not set", component, env.Name)
+	}
+	if conf.Namespace == "" {
+		return nil, fmt.Errorf("namespace of %s in %s is not set", component, env.Name)
+	}
+	if !env.Known() {
+		return nil, fmt.Errorf("tier and region of environment %s are unknown", env.Name)
+	}
+
+	vars := env.Vars()
+	vars["CHART_VERSION"] = version
+	vars["COMPONENT"] = component
+	if conf.GomplateDatasources != "" {
+		vars["GOMPLATE_DATASOURCES"] = conf.GomplateDatasources
+	}
+	if conf.EnableArgoHookDeleteRedis != "" {
+		vars["ENABLE_ARGO_HOOK_DELETE_REDIS"] = strconv.FormatBool(isTrue(conf.EnableArgoHookDeleteRedis))
+	}
+	if conf.EnableArgoHookDeleteRedisForce != "" {
+		vars["ENABLE_ARGO_HOOK_DELETE_REDIS_FORCE"] = strconv.FormatBool(isTrue(conf.EnableArgoHookDeleteRedisForce))
+	}
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pluginEnv := make([]EnvVar, 0, len(keys))
+	for _, key := range keys {
+		pluginEnv = append(pluginEnv, EnvVar{Name: key, Value: vars[key]})
+	}
+
+	name := Name(component, env.Name)
+	app := Application{
+		APIVersion: "argoproj.io/v1alpha1",
+		Kind:       "Application",
+		Metadata: Metadata{
+			Name:       name,
+			Namespace:  opts.Namespace,
+			Labels:     labels(component, env),
+			Finalizers: []string{"resources-finalizer.argocd.argoproj.io"},
+		},
+		Spec: ApplicationSpec{
+			Project: opts.Project,
+			Source: Source{
+				RepoURL:        opts.RepoURL,
+				TargetRevision: firstNonEmpty(conf.HeoRevisionOverride, conf.HeoRevision, "HEAD"),
+				Path:           path.Join(firstNonEmpty(conf.HeoRoot, DefaultHeoRoot), component),
+				Plugin:         Plugin{Name: opts.Plugin, Env: pluginEnv},
+			},
+			Destination: Destination{Server: opts.Server, Namespace: conf.Namespace},
+			SyncPolicy: SyncPolicy{
+				SyncOptions: []string{"CreateNamespace=true"},
+			},
+		},
+	}
+	if conf.IsOnboarded() {
+		app.Spec.SyncPolicy.Automated = &Automated{Prune: true, SelfHeal: true}
+	}
+	if conf.SlackNotifyChannel != "" {
+		app.Metadata.Annotations = map[string]string{
+			"notifications.argoproj.io/subscribe.on-sync-failed.slack":    conf.SlackNotifyChannel,
+			"notifications.argoproj.io/subscribe.on-sync-succeeded.slack": conf.SlackNotifyChannel,
+		}
+	}
+
+	return &app, nil
+}
+
+func labels(component string, env inventory.Environment) map[string]string {
+	return map[string]string{
+		"qlik.com/component":   component,
+		"qlik.com/environment": env.Name,
+		"qlik.com/tier":        env.Tier,
+		"qlik.com/region":      env.Region,
+	}
+}
+
+func isTrue(value string) bool {
+	return strings.EqualFold(strings.TrimSpace(value), "true")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+package argocd_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitpkg/argocd"
+	"gitpkg/deploycheck"
+	"gitpkg/inventory"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestGenerate(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		env  inventory.Environment
+	}{
+		{name: "minimal", env: inventory.FromName("qcs-stage-us-east-1")},
+		{name: "full", env: inventory.Environment{Name: "qcs-prod-fedramp-us-east-1", Tier: "prod", Region: "us-east-1", Provider: "fedramp"}},
+		{name: "redis-force", env: inventory.FromName("qlik-cloud-services-int-env")},
+	} {
+		t.Run("Generate matches testdata/"+tc.name+".golden.yaml", func(t *testing.T) {
+			// Arrange
+			data, err := os.ReadFile(filepath.Join("testdata", tc.name+".conf.yaml"))
+			assert.NoError(t, err)
+			conf, err := argocd.ParseConfig(data)
+			assert.NoError(t, err)
+			golden := filepath.Join("testdata", tc.name+".golden.yaml")
+
+			// Act
+			m, err := argocd.Generate(conf, "hello", tc.env, argocd.Options{})
+			assert.NoError(t, err)
+			out, err := m.Marshal()
+			assert.NoError(t, err)
+
+			// Assert
+			if *update {
+				assert.NoError(t, os.WriteFile(golden, out, 0644))
+			}
+			want, err := os.ReadFile(golden)
+			assert.NoError(t, err)
+			assert.Equal(t, string(want), string(out))
+		})
+	}
+
+	t.Run("Generate is deterministic", func(t *testing.T) {
+		conf := &deploycheck.ConfigFile{Version: "1.0.0", Namespace: "hello", SlackNotifyChannel: "#c", EnableArgoHookDeleteRedis: "true", GomplateDatasources: "vault=vault:///"}
+		env := inventory.FromName("qcs-prod-eu-west-1")
+		first, err := argocd.Generate(conf, "hello", env, argocd.Options{})
+		assert.NoError(t, err)
+		want, err := first.Marshal()
+		assert.NoError(t, err)
+
+		for i := 0; i < 20; i++ {
+			m, err := argocd.Generate(conf, "hello", env, argocd.Options{})
+			assert.NoError(t, err)
+			got, err := m.Marshal()
+			assert.NoError(t, err)
+			assert.Equal(t, string(want), string(got))
+		}
+	})
+
+	t.Run("Generate syncs only onboarded environments automatically", func(t *testing.T) {
+		env := inventory.FromName("qcs-stage-us-east-1")
+
+		onboarded, err := argocd.Generate(&deploycheck.ConfigFile{Version: "1.0.0", Namespace: "hello", Onboarded: "true"}, "hello", env, argocd.Options{})
+		assert.NoError(t, err)
+		assert.Equal(t, &argocd.Automated{Prune: true, SelfHeal: true}, onboarded.Spec.SyncPolicy.Automated)
+
+		m, err := argocd.Generate(&deploycheck.ConfigFile{Version: "1.0.0", Namespace: "hello", Onboarded: "false"}, "hello", env, argocd.Options{})
+		assert.NoError(t, err)
+		assert.Nil(t, m.Spec.SyncPolicy.Automated)
+		out, err := m.Marshal()
+		assert.NoError(t, err)
+		assert.NotContains(t, string(out), "automated")
+		assert.Contains(t, string(out), "CreateNamespace=true")
+	})
+
+	t.Run("Generate requires the version and namespace", func(t *testing.T) {
+		env := inventory.FromName("qcs-stage-us-east-1")
+
+		_, err := argocd.Generate(&deploycheck.ConfigFile{Namespace: "hello"}, "hello", env, argocd.Options{})
+		assert.ErrorContains(t, err, "version of hello in qcs-stage-us-east-1 is not set")
+
+		_, err = argocd.Generate(&deploycheck.ConfigFile{Version: "1.0.0"}, "hello", env, argocd.Options{})
+		assert.ErrorContains(t, err, "namespace of hello in qcs-stage-us-east-1 is not set")
+	})
+}
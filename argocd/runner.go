@@ -0,0 +1,120 @@
+package argocd
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gitpkg/inventory"
+)
+
+// Setup registers the flags of the argocd-app command; the command generates the
+// Application of every conf.yaml of the selected components and environments,
+// writing them to stdout or to <output-dir>/<component>/<environment>.yaml.
+func Setup(fset *flag.FlagSet) func(args []string) error {
+	environmentsRoot := fset.String("environments-root", "gitops-environments", "gitops-environments checkout holding the conf.yaml files")
+	inventoryFile := fset.String("inventory", "gitops-environments/"+inventory.DefaultFile, "Path of the environment inventory")
+	components := fset.String("components", "", "Comma separated components, every component when empty")
+	environments := fset.String("environments", "", "Comma separated environments, every environment of the inventory when empty")
+	outputDir := fset.String("output-dir", "", "Directory the applications are written to, stdout when empty")
+	def := GetDefaultOptions()
+	repoURL := fset.String("repo-url", def.RepoURL, "Values repository the applications are rendered from")
+	project := fset.String("project", def.Project, "Argo CD project of the applications")
+	namespace := fset.String("namespace", def.Namespace, "Namespace the applications are created in")
+	server := fset.String("server", def.Server, "API server of the destination cluster")
+	plugin := fset.String("plugin", def.Plugin, "Config management plugin rendering the values")
+	return func(args []string) error {
+
+		inv, err := inventory.Load(*inventoryFile)
+		if err != nil {
+			return err
+		}
+		opts := Options{
+			RepoURL:   *repoURL,
+			Project:   *project,
+			Namespace: *namespace,
+			Server:    *server,
+			Plugin:    *plugin,
+		}
+		root := os.DirFS(*environmentsRoot)
+		var names []string
+		if *components != "" {
+			names = strings.Split(*components, ",")
+		} else {
+			entries, err := fs.ReadDir(root, "components")
+			if err != nil {
+				return fmt.Errorf("failed to list components: %w", err)
+			}
+			for _, entry := range entries {
+				if entry.IsDir() {
+					names = append(names, entry.Name())
+				}
+			}
+		}
+		envs := inv.List()
+		if *environments != "" {
+			envs = nil
+			for _, name := range strings.Split(*environments, ",") {
+				env, ok := inv.Get(name)
+				if !ok {
+					return fmt.Errorf("environment %q is not in the inventory", name)
+				}
+				envs = append(envs, env)
+			}
+		}
+		sort.Slice(envs, func(i, j int) bool { return envs[i].Name < envs[j].Name })
+
+		var stream bytes.Buffer
+		count := 0
+		for _, component := range names {
+			for _, env := range envs {
+				data, err := fs.ReadFile(root, inventory.ConfPath(component, env.Name))
+				if errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				conf, err := ParseConfig(data)
+				if err != nil {
+					return fmt.Errorf("%s: %w", inventory.ConfPath(component, env.Name), err)
+				}
+				app, err := Generate(conf, component, env, opts)
+				if err != nil {
+					return err
+				}
+				out, err := app.Marshal()
+				if err != nil {
+					return err
+				}
+				count++
+				if *outputDir == "" {
+					if stream.Len() > 0 {
+						stream.WriteString("---\n")
+					}
+					stream.Write(out)
+					continue
+				}
+				file := filepath.Join(*outputDir, component, env.Name+".yaml")
+				if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+					return err
+				}
+				if err := os.WriteFile(file, out, 0644); err != nil {
+					return fmt.Errorf("failed to write %s: %w", file, err)
+				}
+			}
+		}
+		if *outputDir == "" {
+			_, err := os.Stdout.Write(stream.Bytes())
+			return err
+		}
+		fmt.Printf("%d applications written to %s\n", count, *outputDir)
+		return nil
+	}
+}
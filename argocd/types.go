@@ -0,0 +1,59 @@
+package argocd
+
+// The types below cover the fields of the generated resources only. Field
+// order is the order the manifests are written in; maps are written sorted.
+
+type Metadata struct {
+	Name        string            `yaml:"name"`
+	Namespace   string            `yaml:"namespace"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+	Finalizers  []string          `yaml:"finalizers,omitempty"`
+}
+
+// Application is an argoproj.io/v1alpha1 Application.
+type Application struct {
+	APIVersion string          `yaml:"apiVersion"`
+	Kind       string          `yaml:"kind"`
+	Metadata   Metadata        `yaml:"metadata"`
+	Spec       ApplicationSpec `yaml:"spec"`
+}
+
+type ApplicationSpec struct {
+	Project     string      `yaml:"project"`
+	Source      Source      `yaml:"source"`
+	Destination Destination `yaml:"destination"`
+	SyncPolicy  SyncPolicy  `yaml:"syncPolicy"`
+}
+
+type Source struct {
+	RepoURL        string `yaml:"repoURL"`
+	TargetRevision string `yaml:"targetRevision"`
+	Path           string `yaml:"path"`
+	Plugin         Plugin `yaml:"plugin"`
+}
+
+type Plugin struct {
+	Name string   `yaml:"name"`
+	Env  []EnvVar `yaml:"env,omitempty"`
+}
+
+type EnvVar struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+type Destination struct {
+	Server    string `yaml:"server"`
+	Namespace string `yaml:"namespace"`
+}
+
+type SyncPolicy struct {
+	Automated   *Automated `yaml:"automated,omitempty"`
+	SyncOptions []string   `yaml:"syncOptions,omitempty"`
+}
+
+type Automated struct {
+	Prune    bool `yaml:"prune"`
+	SelfHeal bool `yaml:"selfHeal"`
+}
@@ -0,0 +1,420 @@
+// Package cli implements the go-tools command line: the global flags shared by
+// the git commands, the commands themselves, per-command help and shell
+// completion.
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"gitpkg/deploycheck"
+	"gitpkg/logging"
+	"gitpkg/qgit"
+	"gitpkg/utilities"
+)
+
+// Exit codes of the program.
+const (
+	ExitOK    = 0
+	ExitError = 1
+	// ExitUsage is returned for unknown commands and invalid flags.
+	ExitUsage = 2
+	// ExitNoDeployment is returned by deploy-check and diff-config when the
+	// change needs no deployment, so scripts can tell it apart from errors.
+	ExitNoDeployment = 3
+	// The failures of a known deploycheck.ErrorKind have their own codes.
+	ExitAuth          = 4
+	ExitRefNotFound   = 5
+	ExitConfFiles     = 6
+	ExitInvalidConfig = 7
+	ExitNetwork       = 8
+)
+
+// exitCodes maps the kinds of failures to their exit codes. Other failures
+// exit with ExitError.
+var exitCodes = map[deploycheck.ErrorKind]int{
+	deploycheck.KindAuth:          ExitAuth,
+	deploycheck.KindRefNotFound:   ExitRefNotFound,
+	deploycheck.KindConfFiles:     ExitConfFiles,
+	deploycheck.KindInvalidConfig: ExitInvalidConfig,
+	deploycheck.KindNetwork:       ExitNetwork,
+}
+
+// ExitCode returns the exit code of a failure.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var exit *ExitCodeError
+	if errors.As(err, &exit) {
+		return exit.Code
+	}
+	var usage *utilities.UsageError
+	if errors.As(err, &usage) {
+		return ExitUsage
+	}
+	if code, ok := exitCodes[deploycheck.KindOf(err)]; ok {
+		return code
+	}
+	return ExitError
+}
+
+// Output formats of the --output flag.
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// ExitCodeError ends the program with Code. Err is printed when set.
+type ExitCodeError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitCodeError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitCodeError) Unwrap() error { return e.Err }
+
+// usageError is a command line the command cannot run with.
+func usageError(format string, args ...interface{}) error {
+	return utilities.NewUsageError(format, args...)
+}
+
+// Command is a subcommand of the program.
+type Command struct {
+	Name string
+	// Short is the one line description shown in the command list.
+	Short string
+	// Args describes the positional arguments, e.g. "<ref>".
+	Args string
+	// Setup registers the flags of the command and returns the function
+	// running it with the positional arguments.
+	Setup func(fs *flag.FlagSet) func(g *Globals, args []string) error
+}
+
+// Globals are the flags shared by the git commands.
+type Globals struct {
+	// RepoPath is the local checkout, cloned from RepoURL when missing.
+	RepoPath string
+	RepoURL  string
+	// TokenEnv names the environment variable holding the token; TokenFile,
+	// when set, is read instead.
+	TokenEnv  string
+	TokenFile string
+	Output    string
+	// LogFormat is the format of the log written to stderr, see logging.New.
+	LogFormat string
+	Quiet     bool
+	Verbose   bool
+
+	stdout io.Writer
+	stderr io.Writer
+	logger *slog.Logger
+	// set holds the names of the global flags given before the command or
+	// among its flags.
+	set map[string]bool
+}
+
+// bind registers the global flags on fs, except the ones the command
+// defines itself, e.g. the --output file of manifest-diff.
+func (g *Globals) bind(fs *flag.FlagSet) {
+	stringVar := func(p *string, name, usage string) {
+		if fs.Lookup(name) == nil {
+			fs.StringVar(p, name, *p, usage)
+		}
+	}
+	boolVar := func(p *bool, name, usage string) {
+		if fs.Lookup(name) == nil {
+			fs.BoolVar(p, name, *p, usage)
+		}
+	}
+	stringVar(&g.RepoPath, "repo", "Path of the local repository, cloned from --url when missing")
+	stringVar(&g.RepoURL, "url", "URL of the remote repository")
+	stringVar(&g.TokenEnv, "token-env", "Environment variable holding the access token")
+	stringVar(&g.TokenFile, "token-file", "File holding the access token, instead of --token-env")
+	stringVar(&g.Output, "output", "Output format: text or json")
+	stringVar(&g.LogFormat, "log-format", "Log format: text, json or github")
+	boolVar(&g.Quiet, "q", "Quiet: only log errors")
+	boolVar(&g.Verbose, "v", "Verbose: also log debug messages and the resolved options")
+}
+
+// visit records the flags given on fs.
+func (g *Globals) visit(fs *flag.FlagSet) {
+	if g.set == nil {
+		g.set = map[string]bool{}
+	}
+	fs.Visit(func(f *flag.Flag) { g.set[f.Name] = true })
+}
+
+// IsSet reports whether the named global flag was given, before the command
+// or among its flags.
+func (g *Globals) IsSet(name string) bool {
+	return g.set[name]
+}
+
+func (g *Globals) check() error {
+	if g.Output != OutputText && g.Output != OutputJSON {
+		return usageError("invalid --output %q: expected %s or %s", g.Output, OutputText, OutputJSON)
+	}
+	if g.Quiet && g.Verbose {
+		return usageError("-q and -v are exclusive")
+	}
+	level := slog.LevelInfo
+	switch {
+	case g.Quiet:
+		level = slog.LevelError
+	case g.Verbose:
+		level = slog.LevelDebug
+	}
+	logger, err := logging.New(g.stderr, logging.Options{Level: level, Format: g.LogFormat})
+	if err != nil {
+		return usageError("%v", err)
+	}
+	g.logger = logger
+	return nil
+}
+
+// Logger returns the logger configured by the flags, writing to stderr.
+func (g *Globals) Logger() *slog.Logger {
+	return logging.OrDefault(g.logger)
+}
+
+// Annotator returns the annotator of the problems found in repository files:
+// workflow commands written to stderr on GitHub Actions, nothing elsewhere.
+func (g *Globals) Annotator() logging.Annotator {
+	return logging.NewAnnotator(g.stderr)
+}
+
+// Token returns the access token from the configured source.
+func (g *Globals) Token() (string, error) {
+	if g.TokenFile != "" {
+		data, err := os.ReadFile(g.TokenFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	if g.TokenEnv == "" {
+		return "", nil
+	}
+	return os.Getenv(g.TokenEnv), nil
+}
+
+// Client opens the repository at RepoPath, cloning it from RepoURL first when
+// it does not exist.
+func (g *Globals) Client() (*qgit.Client, error) {
+	token, err := g.Token()
+	if err != nil {
+		return nil, err
+	}
+	client, err := qgit.NewClient(qgit.WithRepoPath(g.RepoPath), qgit.WithRepoUrl(g.RepoURL), qgit.WithToken(token), qgit.WithLogger(g.Logger()))
+	if err != nil {
+		return nil, err
+	}
+	if g.RepoURL != "" {
+		err = client.InitRepo()
+	} else {
+		err = client.Open()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// Print writes the result of a command: v encoded as JSON with --output json,
+// otherwise the output of text.
+func (g *Globals) Print(v interface{}, text func(w io.Writer) error) error {
+	if g.Output == OutputJSON {
+		enc := json.NewEncoder(g.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	return text(g.stdout)
+}
+
+// App is the program: its commands and where it writes.
+type App struct {
+	Name     string
+	Commands []*Command
+	Stdout   io.Writer
+	Stderr   io.Writer
+}
+
+// New returns the go-tools program with all commands.
+func New() *App {
+	a := &App{Name: "go-tools", Stdout: os.Stdout, Stderr: os.Stderr}
+	a.Commands = append(Commands(), a.helpCommand(), a.completionCommand())
+	return a
+}
+
+func (a *App) defaultGlobals() *Globals {
+	return &Globals{RepoPath: ".", TokenEnv: "GITHUB_TOKEN", Output: OutputText, LogFormat: logging.DefaultFormat(), stdout: a.Stdout, stderr: a.Stderr}
+}
+
+// Command returns the named command.
+func (a *App) Command(name string) *Command {
+	for _, c := range a.Commands {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Run runs the command line and returns the exit code. Arguments naming no
+// command but --workspace or --git-url are the legacy deploy check invocation.
+func (a *App) Run(args []string) int {
+	err := a.run(args)
+	if err == nil {
+		return ExitOK
+	}
+	var exit *ExitCodeError
+	if errors.As(err, &exit) {
+		if exit.Err != nil {
+			fmt.Fprintf(a.Stderr, "error: %v\n", exit.Err)
+		}
+		return exit.Code
+	}
+	var usage *utilities.UsageError
+	if errors.As(err, &usage) {
+		fmt.Fprintf(a.Stderr, "error: %v\n", usage)
+		return ExitUsage
+	}
+	fmt.Fprintf(a.Stderr, "error (%s): %v\n", deploycheck.KindOf(err), err)
+	return ExitCode(err)
+}
+
+func (a *App) run(args []string) error {
+	g := a.defaultGlobals()
+	if isLegacy(args) && a.commandIndex(args) < 0 {
+		return a.runCommand(deployCheckCommand(true), g, args)
+	}
+
+	fs := flag.NewFlagSet(a.Name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	g.bind(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			a.usage(a.Stdout)
+			return nil
+		}
+		a.usage(a.Stderr)
+		return usageError("%v", err)
+	}
+	g.visit(fs)
+	if fs.NArg() == 0 {
+		a.usage(a.Stderr)
+		return usageError("no command given")
+	}
+	name, rest := fs.Arg(0), fs.Args()[1:]
+	cmd := a.Command(name)
+	if cmd == nil {
+		a.usage(a.Stderr)
+		return usageError("unknown command %q", name)
+	}
+	return a.runCommand(cmd, g, rest)
+}
+
+// isLegacy reports whether args are the deploy check flags used before the
+// commands existed, which always named the workspace or the git url.
+func isLegacy(args []string) bool {
+	for _, arg := range args {
+		name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if strings.HasPrefix(arg, "-") && (name == "workspace" || name == "git-url") {
+			return true
+		}
+	}
+	return false
+}
+
+// commandIndex returns the index of the first argument naming a command, or -1.
+func (a *App) commandIndex(args []string) int {
+	for i, arg := range args {
+		if a.Command(arg) != nil {
+			return i
+		}
+	}
+	return -1
+}
+
+func (a *App) runCommand(cmd *Command, g *Globals, args []string) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	run := cmd.Setup(fs)
+	g.bind(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			a.commandUsage(a.Stdout, cmd)
+			return nil
+		}
+		a.commandUsage(a.Stderr, cmd)
+		return usageError("%v", err)
+	}
+	g.visit(fs)
+	if err := g.check(); err != nil {
+		return err
+	}
+	return run(g, fs.Args())
+}
+
+// usage prints the command list.
+func (a *App) usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s [global flags] <command> [flags] [args]\n\nCommands:\n", a.Name)
+	for _, c := range a.Commands {
+		fmt.Fprintf(w, "  %-24s %s\n", c.Name, c.Short)
+	}
+	fmt.Fprintf(w, "\nGlobal flags:\n")
+	fs := flag.NewFlagSet(a.Name, flag.ContinueOnError)
+	a.defaultGlobals().bind(fs)
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+	fmt.Fprintf(w, "\nExit codes:\n")
+	fmt.Fprintf(w, "  %d  success, or a deployment is needed\n", ExitOK)
+	fmt.Fprintf(w, "  %d  error\n", ExitError)
+	fmt.Fprintf(w, "  %d  invalid command line\n", ExitUsage)
+	fmt.Fprintf(w, "  %d  no deployment is needed\n", ExitNoDeployment)
+	for _, kind := range []deploycheck.ErrorKind{deploycheck.KindAuth, deploycheck.KindRefNotFound, deploycheck.KindConfFiles, deploycheck.KindInvalidConfig, deploycheck.KindNetwork} {
+		fmt.Fprintf(w, "  %d  %s error\n", exitCodes[kind], kind)
+	}
+	fmt.Fprintf(w, "\nRun '%s help <command>' for the flags of a command.\n", a.Name)
+}
+
+// commandUsage prints the help of a command.
+func (a *App) commandUsage(w io.Writer, cmd *Command) {
+	fmt.Fprintf(w, "Usage: %s %s [flags] %s\n\n%s\n\nFlags:\n", a.Name, cmd.Name, cmd.Args, cmd.Short)
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	cmd.Setup(fs)
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+	fmt.Fprintf(w, "\nGlobal flags are accepted before the command or among its flags.\n")
+}
+
+// flagNames returns the flags of a command, including the global flags.
+func (a *App) flagNames(cmd *Command) []string {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	cmd.Setup(fs)
+	a.defaultGlobals().bind(fs)
+	var names []string
+	fs.VisitAll(func(f *flag.Flag) {
+		if len(f.Name) == 1 {
+			names = append(names, "-"+f.Name)
+		} else {
+			names = append(names, "--"+f.Name)
+		}
+	})
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,243 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gitpkg/cli"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+const confFile = "components/hello/qcs-stage-us-east-1/conf.yaml"
+
+// commit writes the files into the work tree of repo and commits them.
+func commit(t *testing.T, repo *git.Repository, dir string, files map[string]string) string {
+	t.Helper()
+	wt, err := repo.Worktree()
+	assert.NoError(t, err)
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		assert.NoError(t, os.WriteFile(file, []byte(content), 0644))
+		_, err := wt.Add(name)
+		assert.NoError(t, err)
+	}
+	hash, err := wt.Commit("update", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	assert.NoError(t, err)
+	return hash.String()
+}
+
+// run runs the program with args and returns the exit code and what it printed.
+func run(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	app := cli.New()
+	app.Stdout, app.Stderr = &stdout, &stderr
+	code := app.Run(args)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestApp(t *testing.T) {
+	t.Run("Run lists the commands for help", func(t *testing.T) {
+		// Act
+		code, stdout, _ := run("help")
+
+		// Assert
+		assert.Equal(t, cli.ExitOK, code)
+		assert.Contains(t, stdout, "deploy-check")
+		assert.Contains(t, stdout, "diff-config")
+		assert.Contains(t, stdout, "-token-env")
+	})
+
+	t.Run("Run prints the flags of a command", func(t *testing.T) {
+		code, stdout, _ := run("drift", "-h")
+
+		assert.Equal(t, cli.ExitOK, code)
+		assert.Contains(t, stdout, "Usage: go-tools drift")
+		assert.Contains(t, stdout, "-inventory-file")
+	})
+
+	t.Run("Run fails with a usage error for unknown commands and flags", func(t *testing.T) {
+		code, _, stderr := run("deploy")
+		assert.Equal(t, cli.ExitUsage, code)
+		assert.Contains(t, stderr, `unknown command "deploy"`)
+
+		code, _, _ = run("show-file", "--output", "yaml", "conf.yaml")
+		assert.Equal(t, cli.ExitUsage, code)
+
+		code, _, _ = run("checkout", "--nope", "main")
+		assert.Equal(t, cli.ExitUsage, code)
+
+		code, _, stderr = run("sealed-secrets-seal", "--component", "hello", "--scope", "namespacewide")
+		assert.Equal(t, cli.ExitUsage, code)
+		assert.Contains(t, stderr, `invalid --scope: unknown scope "namespacewide"`)
+
+		code, _, stderr = run("sealed-secrets-seal", "--scope", "strict")
+		assert.Equal(t, cli.ExitUsage, code)
+		assert.Equal(t, "error: --component is required\n", stderr)
+
+		code, _, _ = run("sealed-secrets-seal", "--component", "hello", "--cert", "qcs/stage/us-east-1.pem")
+		assert.Equal(t, cli.ExitUsage, code)
+
+		code, _, _ = run("sealed-secrets-seal", "--component", "hello", "--nope")
+		assert.Equal(t, cli.ExitUsage, code)
+	})
+
+	t.Run("Run parses the flags of the values repository commands with the global flags", func(t *testing.T) {
+		code, _, _ := run("-q", "lint", "--output", "json", "--list-rules")
+		assert.Equal(t, cli.ExitOK, code)
+
+		code, _, stderr := run("lint", "--nope")
+		assert.Equal(t, cli.ExitUsage, code)
+		assert.Contains(t, stderr, "error: flag provided but not defined: -nope")
+
+		code, _, _ = run("validate", "--output", "json", "extra")
+		assert.Equal(t, cli.ExitUsage, code)
+
+		code, stdout, _ := run("manifest-diff", "-h")
+		assert.Equal(t, cli.ExitOK, code)
+		assert.Contains(t, stdout, "Write the markdown to this file instead of stdout")
+	})
+
+	t.Run("Run treats --workspace without a command as the legacy deploy check", func(t *testing.T) {
+		t.Setenv("GITHUB_EVENT_PATH", "")
+
+		code, _, stderr := run("--workspace", t.TempDir())
+
+		assert.Equal(t, cli.ExitUsage, code)
+		assert.Contains(t, stderr, "--workspace and --git-url must be provided")
+	})
+
+	t.Run("Run generates shell completion", func(t *testing.T) {
+		code, stdout, _ := run("completion", "bash")
+		assert.Equal(t, cli.ExitOK, code)
+		assert.Contains(t, stdout, "complete -o default -F _go_tools go-tools")
+		assert.Contains(t, stdout, "--pr-number")
+
+		code, _, _ = run("completion", "powershell")
+		assert.Equal(t, cli.ExitUsage, code)
+	})
+}
+
+func TestGitCommands(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	assert.NoError(t, err)
+	initial := commit(t, repo, dir, map[string]string{
+		confFile: "version: 1.0.0\nnamespace: hello\nheoRevision: abc\n",
+		"components/hello/qcs-stage-eu-west-1/conf.yaml": "version: 1.0.0\nnamespace: hello\nheoRevision: abc\n",
+	})
+	bump := commit(t, repo, dir, map[string]string{confFile: "version: 1.1.0\nnamespace: hello\nheoRevision: abc\n"})
+	slack := commit(t, repo, dir, map[string]string{confFile: "version: 1.1.0\nnamespace: hello\nheoRevision: abc\nslackNotifyChannel: \"#hello\"\n"})
+	broken := commit(t, repo, dir, map[string]string{confFile: "version: [\n"})
+	assert.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/master", plumbing.NewHash(slack))))
+
+	t.Run("show-file prints a file at a commit", func(t *testing.T) {
+		// Act
+		code, stdout, _ := run("--repo", dir, "show-file", "--ref", initial, confFile)
+
+		// Assert
+		assert.Equal(t, cli.ExitOK, code)
+		assert.Equal(t, "version: 1.0.0\nnamespace: hello\nheoRevision: abc\n", stdout)
+	})
+
+	t.Run("changed-files lists the files changed between two commits as json", func(t *testing.T) {
+		code, stdout, _ := run("changed-files", "--repo", dir, "--output", "json", "--base", initial, "--head", slack)
+
+		assert.Equal(t, cli.ExitOK, code)
+		var files []string
+		assert.NoError(t, json.Unmarshal([]byte(stdout), &files))
+		assert.Equal(t, []string{confFile}, files)
+	})
+
+	t.Run("diff-config reports a version change as a deployment", func(t *testing.T) {
+		code, stdout, _ := run("diff-config", "--repo", dir, "--base", initial, "--head", bump, confFile)
+
+		assert.Equal(t, cli.ExitOK, code)
+		assert.Equal(t, "version: \"1.0.0\" -> \"1.1.0\"\nDEPLOYMENT_NEEDED=true\n", stdout)
+	})
+
+	t.Run("diff-config exits with ExitNoDeployment when only other fields change", func(t *testing.T) {
+		code, stdout, _ := run("diff-config", "--repo", dir, "--base", bump, "--head", slack, "--output", "json", confFile)
+
+		assert.Equal(t, cli.ExitNoDeployment, code)
+		var diff map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(stdout), &diff))
+		assert.Equal(t, false, diff["deploymentNeeded"])
+	})
+
+	t.Run("drift lists the environments of a tier running different versions", func(t *testing.T) {
+		code, stdout, _ := run("drift", "--repo", dir)
+
+		assert.Equal(t, cli.ExitOK, code)
+		assert.Equal(t, "hello (stage):\n  qcs-stage-eu-west-1 1.0.0@abc\n  qcs-stage-us-east-1 1.1.0@abc\n", stdout)
+	})
+
+	t.Run("deploy-check exits with ExitNoDeployment when the push needs no deployment", func(t *testing.T) {
+		t.Setenv("GITHUB_EVENT_PATH", "")
+		t.Setenv("GITHUB_OUTPUT", "")
+		t.Setenv("SLACK_WEBHOOK_URL", "")
+		t.Setenv("SLACK_WEBHOOK_URLS", "")
+
+		code, stdout, _ := run("-q", "deploy-check", "--repo", dir, "--mode", "push", "--destination-branch", "master", "--before", bump, "--after", slack)
+		assert.Equal(t, cli.ExitNoDeployment, code)
+		assert.Equal(t, "No deployment of hello to qcs-stage-us-east-1 is needed\n", stdout)
+
+		code, stdout, _ = run("deploy-check", "--repo", dir, "--output", "json", "--mode", "push", "--destination-branch", "master", "--before", initial, "--after", bump)
+		assert.Equal(t, cli.ExitOK, code)
+		var decision map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(stdout), &decision))
+		assert.Equal(t, true, decision["needDeployment"])
+		assert.Equal(t, "1.0.0", decision["previousVersion"])
+	})
+
+	t.Run("deploy-check clones from a global --url given before the command", func(t *testing.T) {
+		t.Setenv("GITHUB_EVENT_PATH", "")
+		t.Setenv("GITHUB_OUTPUT", "")
+		t.Setenv("SLACK_WEBHOOK_URL", "")
+		t.Setenv("SLACK_WEBHOOK_URLS", "")
+		clone := filepath.Join(t.TempDir(), "clone")
+
+		code, stdout, stderr := run("--url", dir, "deploy-check", "--repo", clone, "--mode", "push", "--destination-branch", "master", "--before", initial, "--after", bump)
+
+		assert.Equal(t, cli.ExitOK, code, stderr)
+		assert.Equal(t, "hello 1.1.0 is deploying to qcs-stage-us-east-1\n", stdout)
+		assert.DirExists(t, filepath.Join(clone, ".git"))
+	})
+
+	t.Run("deploy-check rejects webhooks of channels that are not a JSON object", func(t *testing.T) {
+		t.Setenv("GITHUB_EVENT_PATH", "")
+		t.Setenv("SLACK_WEBHOOK_URLS", "#hello=https://hooks.slack.com/x")
+
+		code, _, stderr := run("deploy-check", "--repo", dir, "--mode", "push", "--destination-branch", "master", "--before", initial, "--after", bump)
+
+		assert.Equal(t, cli.ExitError, code)
+		assert.Contains(t, stderr, "invalid SLACK_WEBHOOK_URLS")
+	})
+
+	t.Run("Run exits with the code of the kind of the failure", func(t *testing.T) {
+		code, _, stderr := run("show-file", "--repo", dir, "--ref", "refs/heads/nope", confFile)
+		assert.Equal(t, cli.ExitRefNotFound, code)
+		assert.Contains(t, stderr, "error (ref-not-found): ")
+
+		code, _, _ = run("diff-config", "--repo", dir, "--base", initial, "--head", broken, confFile)
+		assert.Equal(t, cli.ExitInvalidConfig, code)
+	})
+
+	t.Run("Run fails with ExitError when the repository cannot be opened", func(t *testing.T) {
+		code, _, stderr := run("show-file", "--repo", t.TempDir(), confFile)
+
+		assert.Equal(t, cli.ExitError, code)
+		assert.Contains(t, stderr, "failed to open repo")
+	})
+}
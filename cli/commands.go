@@ -0,0 +1,324 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"gitpkg/argocd"
+	"gitpkg/deploycheck"
+	"gitpkg/impact"
+	"gitpkg/inventory"
+	"gitpkg/lint"
+	"gitpkg/manifestdiff"
+	"gitpkg/qgit"
+	"gitpkg/scaffold"
+	"gitpkg/sealedsecrets"
+	"gitpkg/validate"
+	"gitpkg/vaultmock"
+)
+
+// Commands returns the commands of the program, without help and completion.
+func Commands() []*Command {
+	return []*Command{
+		deployCheckCommand(false),
+		changedFilesCommand(),
+		checkoutCommand(),
+		showFileCommand(),
+		diffConfigCommand(),
+		driftCommand(),
+		{Name: "validate", Short: "Render and validate the charts of the values repository", Setup: tool(validate.Setup)},
+		{Name: "lint", Short: "Lint the values repository", Setup: tool(lint.Setup)},
+		{Name: "impact", Short: "List the environments affected by a change of the values repository", Setup: tool(impact.Setup)},
+		{Name: "manifest-diff", Short: "Diff the rendered manifests of two revisions", Setup: tool(manifestdiff.Setup)},
+		{Name: "init-component", Short: "Scaffold a component in the values repository", Setup: tool(scaffold.Setup)},
+		{Name: "argocd-app", Short: "Generate the Argo CD Application of a component", Setup: tool(argocd.Setup)},
+		{Name: "vault-mock", Short: "Generate the mock Vault datasources and report missing keys", Setup: tool(vaultmock.Setup)},
+		{Name: "sealed-secrets-coverage", Short: "Fail when a deployable environment would render without sealed secrets", Setup: tool(sealedsecrets.SetupCoverage)},
+		{Name: "sealed-secrets-check", Short: "Check that the sealed secrets of a component match across regions", Setup: tool(sealedsecrets.SetupCheck)},
+		{Name: "sealed-secrets-seal", Short: "Seal plaintext values with the certificate of each region", Setup: tool(sealedsecrets.SetupSeal)},
+		{Name: "sealed-secrets-scan", Short: "Fail when a file under qcs/ looks like an unsealed secret", Setup: tool(sealedsecrets.SetupScan)},
+	}
+}
+
+// tool adapts the setup of a values repository command. The command takes
+// the global flags but no positional arguments, and does not use the
+// repository of the git commands.
+func tool(setup func(fs *flag.FlagSet) func(args []string) error) func(fs *flag.FlagSet) func(g *Globals, args []string) error {
+	return func(fs *flag.FlagSet) func(g *Globals, args []string) error {
+		run := setup(fs)
+		return func(g *Globals, args []string) error {
+			if len(args) > 0 {
+				return usageError("%s takes no arguments", fs.Name())
+			}
+			return run(args)
+		}
+	}
+}
+
+func changedFilesCommand() *Command {
+	return &Command{
+		Name:  "changed-files",
+		Short: "List the files changed on a ref or pull request since it diverged from the base",
+		Setup: func(fs *flag.FlagSet) func(g *Globals, args []string) error {
+			base := fs.String("base", "refs/remotes/origin/"+deploycheck.DefaultDestinationBranch, "Base ref or commit")
+			head := fs.String("head", "", "Head ref or commit")
+			prNumber := fs.Int("pr-number", 0, "Pull request fetched as the head")
+			return func(g *Globals, args []string) error {
+				if len(args) > 0 {
+					return usageError("changed-files takes no arguments")
+				}
+				if (*prNumber == 0) == (*head == "") {
+					return usageError("one of --head and --pr-number is required")
+				}
+				client, err := g.Client()
+				if err != nil {
+					return err
+				}
+				if *prNumber != 0 {
+					if *head, err = client.FetchPullRequest(*prNumber); err != nil {
+						return fmt.Errorf("failed to list changed files: %w", err)
+					}
+				}
+				files, err := client.ChangedFilesFromMergeBase(*base, *head)
+				if err != nil {
+					return fmt.Errorf("failed to list changed files: %w", err)
+				}
+				if files == nil {
+					files = []string{}
+				}
+				return g.Print(files, func(w io.Writer) error {
+					for _, f := range files {
+						fmt.Fprintln(w, f)
+					}
+					return nil
+				})
+			}
+		},
+	}
+}
+
+func checkoutCommand() *Command {
+	return &Command{
+		Name:  "checkout",
+		Short: "Check out a branch, tag or commit, fetching it when missing",
+		Args:  "<ref>",
+		Setup: func(fs *flag.FlagSet) func(g *Globals, args []string) error {
+			return func(g *Globals, args []string) error {
+				if len(args) != 1 {
+					return usageError("checkout takes one ref")
+				}
+				client, err := g.Client()
+				if err != nil {
+					return err
+				}
+				if err := client.Checkout(args[0]); err != nil {
+					return fmt.Errorf("failed to check out %s: %w", args[0], err)
+				}
+				head, err := client.Head()
+				if err != nil {
+					return fmt.Errorf("failed to check out %s: %w", args[0], err)
+				}
+				result := map[string]string{"ref": args[0], "head": head}
+				return g.Print(result, func(w io.Writer) error {
+					_, err := fmt.Fprintf(w, "checked out %s at %s\n", args[0], head)
+					return err
+				})
+			}
+		},
+	}
+}
+
+func showFileCommand() *Command {
+	return &Command{
+		Name:  "show-file",
+		Short: "Print a file at a ref or commit",
+		Args:  "<file>",
+		Setup: func(fs *flag.FlagSet) func(g *Globals, args []string) error {
+			ref := fs.String("ref", "HEAD", "Ref or commit to read the file at")
+			return func(g *Globals, args []string) error {
+				if len(args) != 1 {
+					return usageError("show-file takes one file")
+				}
+				client, err := g.Client()
+				if err != nil {
+					return err
+				}
+				content, err := readFile(client, *ref, args[0])
+				if err != nil {
+					return err
+				}
+				result := map[string]string{"ref": *ref, "file": args[0], "content": string(content)}
+				return g.Print(result, func(w io.Writer) error {
+					_, err := w.Write(content)
+					return err
+				})
+			}
+		},
+	}
+}
+
+// readFile reads file at ref, a ref name or a commit hash.
+func readFile(client *qgit.Client, ref, file string) ([]byte, error) {
+	fsys, err := client.FS(ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ref, err)
+	}
+	content, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", file, ref, err)
+	}
+	return content, nil
+}
+
+// configDiff is the result of diff-config.
+type configDiff struct {
+	File             string                    `json:"file"`
+	Base             string                    `json:"base"`
+	Head             string                    `json:"head"`
+	Changes          []deploycheck.FieldChange `json:"changes"`
+	DeploymentNeeded bool                      `json:"deploymentNeeded"`
+}
+
+func diffConfigCommand() *Command {
+	return &Command{
+		Name:  "diff-config",
+		Short: "Diff a conf.yaml between two refs and tell whether it needs a deployment",
+		Args:  "<conf.yaml>",
+		Setup: func(fs *flag.FlagSet) func(g *Globals, args []string) error {
+			base := fs.String("base", "refs/remotes/origin/"+deploycheck.DefaultDestinationBranch, "Base ref or commit")
+			head := fs.String("head", "HEAD", "Head ref or commit")
+			return func(g *Globals, args []string) error {
+				if len(args) != 1 {
+					return usageError("diff-config takes one conf.yaml")
+				}
+				file := args[0]
+				if _, _, err := inventory.ParseConfPath(file); err != nil {
+					return usageError("%v", err)
+				}
+				client, err := g.Client()
+				if err != nil {
+					return err
+				}
+				previous, err := readConfig(client, *base, file)
+				if err != nil {
+					return err
+				}
+				current, err := readConfig(client, *head, file)
+				if err != nil {
+					return err
+				}
+				if current == nil {
+					return fmt.Errorf("%s does not exist at %s", file, *head)
+				}
+				diff := configDiff{
+					File:             file,
+					Base:             *base,
+					Head:             *head,
+					Changes:          deploycheck.DiffConfig(previous, current),
+					DeploymentNeeded: deploycheck.NeedsDeployment(previous, current),
+				}
+				if diff.Changes == nil {
+					diff.Changes = []deploycheck.FieldChange{}
+				}
+				err = g.Print(diff, func(w io.Writer) error {
+					for _, c := range diff.Changes {
+						fmt.Fprintf(w, "%s: %q -> %q\n", c.Field, c.Previous, c.Current)
+					}
+					_, err := fmt.Fprintf(w, "DEPLOYMENT_NEEDED=%t\n", diff.DeploymentNeeded)
+					return err
+				})
+				if err != nil {
+					return err
+				}
+				if !diff.DeploymentNeeded {
+					return &ExitCodeError{Code: ExitNoDeployment}
+				}
+				return nil
+			}
+		},
+	}
+}
+
+// readConfig reads the conf.yaml at ref. It returns nil when the file does
+// not exist there.
+func readConfig(client *qgit.Client, ref, file string) (*deploycheck.ConfigFile, error) {
+	content, err := readFile(client, ref, file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return deploycheck.ParseConfig(content)
+}
+
+func driftCommand() *Command {
+	return &Command{
+		Name:  "drift",
+		Short: "List the components whose environments of a tier run different versions",
+		Setup: func(fs *flag.FlagSet) func(g *Globals, args []string) error {
+			ref := fs.String("ref", "HEAD", "Ref or commit to read the conf.yaml files at")
+			inventoryFile := fs.String("inventory-file", "", "Path of environments.yaml in the repository; the tiers are derived from the environment names when empty")
+			tiers := fs.String("tiers", "", "Comma separated tiers to check, all when empty")
+			return func(g *Globals, args []string) error {
+				if len(args) > 0 {
+					return usageError("drift takes no arguments")
+				}
+				client, err := g.Client()
+				if err != nil {
+					return err
+				}
+				tierOf := func(name string) string { return inventory.FromName(name).Tier }
+				if *inventoryFile != "" {
+					inv, err := inventory.LoadFromRef(client, *ref, *inventoryFile)
+					if err != nil {
+						return err
+					}
+					tierOf = func(name string) string {
+						env, _ := inv.Get(name)
+						return env.Tier
+					}
+				}
+				if *tiers != "" {
+					allowed := strings.Split(*tiers, ",")
+					all := tierOf
+					tierOf = func(name string) string {
+						for _, t := range allowed {
+							if t == all(name) {
+								return t
+							}
+						}
+						return ""
+					}
+				}
+				fsys, err := client.FS(*ref)
+				if err != nil {
+					return fmt.Errorf("failed to read %s: %w", *ref, err)
+				}
+				drifts, err := deploycheck.FindDrift(fsys, tierOf)
+				if err != nil {
+					return err
+				}
+				if drifts == nil {
+					drifts = []deploycheck.Drift{}
+				}
+				return g.Print(drifts, func(w io.Writer) error {
+					if len(drifts) == 0 {
+						fmt.Fprintln(w, "no drift")
+					}
+					for _, d := range drifts {
+						fmt.Fprintf(w, "%s (%s):\n", d.Component, d.Tier)
+						for _, env := range sortedKeys(d.Versions) {
+							fmt.Fprintf(w, "  %s %s\n", env, d.Versions[env])
+						}
+					}
+					return nil
+				})
+			}
+		},
+	}
+}
@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"gitpkg/deploycheck"
+	"gitpkg/ghevent"
+	"gitpkg/report"
+)
+
+// deployCheckCommand checks the conf.yaml changed by a pull request or push.
+// The legacy invocation, `go-tools --workspace ... --git-url ...` without a
+// command, exits with ExitOK when no deployment is needed as it always did.
+func deployCheckCommand(legacy bool) *Command {
+	return &Command{
+		Name:  "deploy-check",
+		Short: "Decide whether the conf.yaml changed by a pull request or push needs a deployment",
+		Setup: func(fs *flag.FlagSet) func(g *Globals, args []string) error {
+			var prNumber int
+			var workspace, gitURL, mode, before, after, mergeSHA, sourceBranch, destinationBranch, action, prMerged, inventoryFile, branchPolicyFile, valuesPath, slackDryRunFile string
+
+			// The pull request flags override the values read from the event
+			// payload at $GITHUB_EVENT_PATH.
+			fs.StringVar(&workspace, "workspace", "", "Deprecated alias of --repo")
+			fs.StringVar(&gitURL, "git-url", "", "Deprecated alias of --url")
+			fs.StringVar(&mode, "mode", "", "pull-request or push; push events are checked in push mode by default")
+			fs.StringVar(&before, "before", "", "Commit before the push, in push mode")
+			fs.StringVar(&after, "after", "", "Commit after the push, in push mode")
+			fs.StringVar(&mergeSHA, "merge-sha", "", "Merge commit of a merged pull request; the tip of the destination branch when empty")
+			fs.IntVar(&prNumber, "pr-number", 0, "The Pull Request number")
+			fs.StringVar(&sourceBranch, "source-branch", "", "sourceBranch")
+			fs.StringVar(&destinationBranch, "destination-branch", "", "destinationBranch")
+			fs.StringVar(&action, "action", "", "Pull request action, e.g. opened or closed")
+			fs.StringVar(&prMerged, "pr-merged", "", "Whether the pull request is merged, true or false")
+			fs.StringVar(&inventoryFile, "inventory-file", "", "Path of environments.yaml in the repository; enables the inventory check")
+			fs.StringVar(&branchPolicyFile, "branch-policy-file", "", "Path of the branch policies in the repository; restricts the environments deployed from each destination branch")
+			fs.StringVar(&valuesPath, "values-path", "", "Checkout of the values repository; enables the sealed secrets check when an environment is onboarded")
+			fs.StringVar(&slackDryRunFile, "slack-dry-run-file", "", "Write the Slack message to this file instead of posting it to the webhook of the channel in $SLACK_WEBHOOK_URLS or $SLACK_WEBHOOK_URL")
+			var reports report.Outputs
+			reports.BindFlags(fs)
+
+			return func(g *Globals, args []string) error {
+				if len(args) > 0 {
+					return usageError("deploy-check takes no arguments")
+				}
+				token, err := g.Token()
+				if err != nil {
+					return err
+				}
+				webhooks, err := slackWebhooks()
+				if err != nil {
+					return err
+				}
+				opt := deploycheck.DeployCheckerOption{
+					Token:            token,
+					OutputFile:       os.Getenv("GITHUB_OUTPUT"),
+					InventoryFile:    inventoryFile,
+					BranchPolicyFile: branchPolicyFile,
+					ValuesPath:       valuesPath,
+					SlackWebhookURL:  os.Getenv("SLACK_WEBHOOK_URL"),
+					SlackWebhooks:    webhooks,
+					SlackDryRunFile:  slackDryRunFile,
+					Reports:          reports,
+				}
+				if !legacy {
+					opt.Path = g.RepoPath
+				}
+				event, err := ghevent.FromEnv()
+				if err != nil {
+					return err
+				}
+				if event != nil {
+					opt.ApplyEvent(event)
+				}
+				// the global flags may be given before the command
+				if g.IsSet("repo") {
+					opt.Path = g.RepoPath
+				}
+				if g.IsSet("url") {
+					opt.Url = g.RepoURL
+				}
+				fs.Visit(func(f *flag.Flag) {
+					switch f.Name {
+					case "workspace":
+						opt.Path = workspace
+					case "git-url":
+						opt.Url = gitURL
+					case "mode":
+						opt.Mode = mode
+					case "before":
+						opt.BaseSHA = before
+					case "after":
+						opt.HeadSHA = after
+					case "merge-sha":
+						opt.MergeSHA = mergeSHA
+					case "pr-number":
+						opt.PrNumber = prNumber
+					case "source-branch":
+						opt.SourceBranch = sourceBranch
+					case "destination-branch":
+						opt.DestinationBranch = destinationBranch
+					case "action":
+						opt.Action = action
+					case "pr-merged":
+						opt.PrMerged = prMerged
+					}
+				})
+				if legacy && (opt.Path == "" || opt.Url == "") {
+					return usageError("missing required flags: --workspace and --git-url must be provided or read from the event payload")
+				}
+				opt.Logger = g.Logger()
+				opt.Annotator = g.Annotator()
+				opt.Logger.Debug("deploy check", "options", opt)
+
+				checker, err := deploycheck.NewDeployChecker(opt)
+				if err != nil {
+					deploycheck.WriteErrorKind(opt.OutputFile, err)
+					return fmt.Errorf("failed to open the repository: %w", err)
+				}
+				if err := checker.Run(); err != nil {
+					return err
+				}
+				decision := checker.Decision()
+				err = g.Print(decision, func(w io.Writer) error {
+					_, err := fmt.Fprintln(w, decision.Summary())
+					return err
+				})
+				if err != nil {
+					return err
+				}
+				if !decision.NeedDeployment && !legacy {
+					return &ExitCodeError{Code: ExitNoDeployment}
+				}
+				return nil
+			}
+		},
+	}
+}
+
+// slackWebhooks returns the incoming webhooks of the channels read from
+// $SLACK_WEBHOOK_URLS, a JSON object such as {"#hello": "https://hooks.slack.com/..."}.
+func slackWebhooks() (map[string]string, error) {
+	value := os.Getenv("SLACK_WEBHOOK_URLS")
+	if value == "" {
+		return nil, nil
+	}
+	var webhooks map[string]string
+	if err := json.Unmarshal([]byte(value), &webhooks); err != nil {
+		return nil, fmt.Errorf("invalid SLACK_WEBHOOK_URLS: expected a JSON object of channels to webhook URLs: %w", err)
+	}
+	return webhooks, nil
+}
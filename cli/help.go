@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+func (a *App) helpCommand() *Command {
+	return &Command{
+		Name:  "help",
+		Short: "Print the commands, or the flags of a command",
+		Args:  "[command]",
+		Setup: func(fs *flag.FlagSet) func(g *Globals, args []string) error {
+			return func(g *Globals, args []string) error {
+				switch len(args) {
+				case 0:
+					a.usage(a.Stdout)
+					return nil
+				case 1:
+					cmd := a.Command(args[0])
+					if cmd == nil {
+						return usageError("unknown command %q", args[0])
+					}
+					a.commandUsage(a.Stdout, cmd)
+					return nil
+				default:
+					return usageError("help takes at most one command")
+				}
+			}
+		},
+	}
+}
+
+func (a *App) completionCommand() *Command {
+	return &Command{
+		Name:  "completion",
+		Short: "Print the shell completion script for bash, zsh or fish",
+		Args:  "<shell>",
+		Setup: func(fs *flag.FlagSet) func(g *Globals, args []string) error {
+			return func(g *Globals, args []string) error {
+				if len(args) != 1 {
+					return usageError("completion takes one shell: bash, zsh or fish")
+				}
+				switch args[0] {
+				case "bash":
+					return a.bashCompletion(a.Stdout)
+				case "zsh":
+					fmt.Fprintln(a.Stdout, "autoload -U +X bashcompinit && bashcompinit")
+					return a.bashCompletion(a.Stdout)
+				case "fish":
+					return a.fishCompletion(a.Stdout)
+				default:
+					return usageError("unsupported shell %q: expected bash, zsh or fish", args[0])
+				}
+			}
+		},
+	}
+}
+
+// commandNames returns the names of the commands, sorted.
+func (a *App) commandNames() []string {
+	var names []string
+	for _, c := range a.Commands {
+		names = append(names, c.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (a *App) bashCompletion(w io.Writer) error {
+	fn := "_" + strings.ReplaceAll(a.Name, "-", "_")
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintf(w, "  local cur=\"${COMP_WORDS[COMP_CWORD]}\" cmd=\"\" i\n")
+	fmt.Fprintf(w, "  for ((i = 1; i < COMP_CWORD; i++)); do\n")
+	fmt.Fprintf(w, "    case \"${COMP_WORDS[i]}\" in\n")
+	fmt.Fprintf(w, "      -*) ;;\n")
+	fmt.Fprintf(w, "      *) cmd=\"${COMP_WORDS[i]}\"; break ;;\n")
+	fmt.Fprintf(w, "    esac\n")
+	fmt.Fprintf(w, "  done\n")
+	fmt.Fprintf(w, "  local words\n")
+	fmt.Fprintf(w, "  case \"$cmd\" in\n")
+	for _, name := range a.commandNames() {
+		fmt.Fprintf(w, "    %s) words=%q ;;\n", name, strings.Join(a.flagNames(a.Command(name)), " "))
+	}
+	fmt.Fprintf(w, "    *) words=%q ;;\n", strings.Join(a.commandNames(), " "))
+	fmt.Fprintf(w, "  esac\n")
+	fmt.Fprintf(w, "  COMPREPLY=($(compgen -W \"$words\" -- \"$cur\"))\n")
+	fmt.Fprintf(w, "}\n")
+	_, err := fmt.Fprintf(w, "complete -o default -F %s %s\n", fn, a.Name)
+	return err
+}
+
+func (a *App) fishCompletion(w io.Writer) error {
+	fmt.Fprintf(w, "complete -c %s -f -n __fish_use_subcommand -a %q\n", a.Name, strings.Join(a.commandNames(), " "))
+	for _, name := range a.commandNames() {
+		for _, f := range a.flagNames(a.Command(name)) {
+			fmt.Fprintf(w, "complete -c %s -n '__fish_seen_subcommand_from %s' -l %s\n", a.Name, name, strings.TrimPrefix(f, "--"))
+		}
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of m, sorted.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
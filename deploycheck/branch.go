@@ -0,0 +1,87 @@
+package deploycheck
+
+import (
+	"fmt"
+	"path"
+
+	"gitpkg/inventory"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultDestinationBranch is the branch pull requests are compared with when
+// neither the flags nor the event name one.
+const DefaultDestinationBranch = "main"
+
+// BranchPolicy lists the environments that may be deployed from the
+// destination branches matching Branch.
+type BranchPolicy struct {
+	// Branch is a path.Match pattern, e.g. "main" or "release/*".
+	Branch string `yaml:"branch"`
+	// Environments are path.Match patterns of environment names, e.g. "qcs-stage-*".
+	Environments []string `yaml:"environments"`
+	// Tiers are the tiers whose environments may be deployed, e.g. "stage".
+	Tiers []string `yaml:"tiers"`
+}
+
+// BranchPolicies are the policies of a repository. The first policy matching
+// a branch applies; branches no policy matches may not deploy.
+type BranchPolicies struct {
+	Branches []BranchPolicy `yaml:"branches"`
+}
+
+// ParseBranchPolicies decodes a branch policy document and validates its patterns.
+func ParseBranchPolicies(data []byte) (*BranchPolicies, error) {
+	policies := &BranchPolicies{}
+	if err := yaml.UnmarshalStrict(data, policies); err != nil {
+		return nil, fmt.Errorf("failed to parse branch policies: %w", err)
+	}
+	for _, p := range policies.Branches {
+		if p.Branch == "" {
+			return nil, fmt.Errorf("branch policy without a branch")
+		}
+		for _, pattern := range append([]string{p.Branch}, p.Environments...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q in the policy of %s: %w", pattern, p.Branch, err)
+			}
+		}
+	}
+	return policies, nil
+}
+
+// LoadBranchPolicies reads the branch policies from file at the given git reference.
+func LoadBranchPolicies(reader inventory.FileReader, ref, file string) (*BranchPolicies, error) {
+	content, err := reader.FileContentFromBranch(ref, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read branch policies %s at %s: %w", file, ref, err)
+	}
+	return ParseBranchPolicies([]byte(content))
+}
+
+// Check fails when env may not be deployed from branch.
+func (bp *BranchPolicies) Check(branch string, env inventory.Environment) error {
+	for _, p := range bp.Branches {
+		if ok, _ := path.Match(p.Branch, branch); !ok {
+			continue
+		}
+		if p.allows(env) {
+			return nil
+		}
+		return fmt.Errorf("environment %s may not be deployed from branch %s", env.Name, branch)
+	}
+	return fmt.Errorf("no branch policy allows deployments from branch %s", branch)
+}
+
+func (p BranchPolicy) allows(env inventory.Environment) bool {
+	for _, pattern := range p.Environments {
+		if ok, _ := path.Match(pattern, env.Name); ok {
+			return true
+		}
+	}
+	for _, tier := range p.Tiers {
+		if tier == env.Tier {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package deploycheck_test
+
+import (
+	"testing"
+
+	"gitpkg/deploycheck"
+	"gitpkg/inventory"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBranchPolicies_Check(t *testing.T) {
+	policies, err := deploycheck.ParseBranchPolicies([]byte(`branches:
+  - branch: main
+    environments: ["*"]
+  - branch: release/*
+    tiers: [stage, prod]
+  - branch: FACTORY-*
+    environments: [qlik-cloud-services-int-env, qcs-stage-*]
+`))
+	assert.NoError(t, err)
+	intEnv := inventory.FromName("qlik-cloud-services-int-env")
+	stage := inventory.FromName("qcs-stage-us-east-1")
+	prod := inventory.FromName("qcs-prod-eu-west-1")
+
+	t.Run("Check allows the environments of the first policy matching the branch", func(t *testing.T) {
+		for _, tc := range []struct {
+			branch string
+			env    inventory.Environment
+		}{
+			{"main", intEnv},
+			{"main", prod},
+			{"release/2024.10", stage},
+			{"release/2024.10", prod},
+			{"FACTORY-1343", intEnv},
+			{"FACTORY-1343", stage},
+		} {
+			// Act
+			err := policies.Check(tc.branch, tc.env)
+
+			// Assert
+			assert.NoError(t, err, "%s from %s", tc.env.Name, tc.branch)
+		}
+	})
+
+	t.Run("Check rejects environments the policy of the branch does not list", func(t *testing.T) {
+		err := policies.Check("release/2024.10", intEnv)
+		assert.EqualError(t, err, "environment qlik-cloud-services-int-env may not be deployed from branch release/2024.10")
+
+		err = policies.Check("FACTORY-1343", prod)
+		assert.EqualError(t, err, "environment qcs-prod-eu-west-1 may not be deployed from branch FACTORY-1343")
+	})
+
+	t.Run("Check rejects branches without a policy", func(t *testing.T) {
+		err := policies.Check("master", stage)
+
+		assert.EqualError(t, err, "no branch policy allows deployments from branch master")
+	})
+}
+
+func TestParseBranchPolicies(t *testing.T) {
+	t.Run("ParseBranchPolicies rejects invalid patterns", func(t *testing.T) {
+		_, err := deploycheck.ParseBranchPolicies([]byte("branches:\n  - branch: main\n    environments: [\"qcs-[\"]\n"))
+
+		assert.ErrorContains(t, err, `invalid pattern "qcs-[" in the policy of main`)
+	})
+
+	t.Run("ParseBranchPolicies rejects unknown fields", func(t *testing.T) {
+		_, err := deploycheck.ParseBranchPolicies([]byte("branches:\n  - branch: main\n    environment: [\"*\"]\n"))
+
+		assert.ErrorContains(t, err, "field environment not found")
+	})
+}
@@ -2,12 +2,18 @@ package deploycheck
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"gitpkg/inventory"
+	"gitpkg/logging"
 	"gitpkg/qgit"
+	"gitpkg/report"
 	"gitpkg/utilities"
+	"io/fs"
+	"log/slog"
+	"os"
 	"strings"
-
-	"gopkg.in/yaml.v2"
+	"time"
 )
 
 // Config struct to match the conf.yaml structure
@@ -26,28 +32,128 @@ type ConfigFile struct {
 	DeploymentSchedule             string `yaml:"deploymentSchedule"`
 	DeploymentWindow               int    `yaml:"deploymentWindow"`
 }
+
+// ParseConfig decodes a conf.yaml. The error of an invalid file wraps a
+// *ConfigError locating the problem.
+func ParseConfig(data []byte) (*ConfigFile, error) {
+	conf := &ConfigFile{}
+	if err := decodeConfig(data, conf); err != nil {
+		return nil, &Error{Kind: KindInvalidConfig, Err: fmt.Errorf("failed to parse conf.yaml: %w", err)}
+	}
+	return conf, nil
+}
+
 type DeployCheckerOption struct {
-	PrNumber          int
-	Token             string
-	Url               string
-	Path              string
-	OutputFile        string
-	Action            string
-	PrMerged          string
-	SourceBranch      string
+	// Mode is ModePullRequest or ModePush. When empty, push events are
+	// checked in ModePush and everything else in ModePullRequest.
+	Mode         string
+	PrNumber     int
+	Token        string
+	Url          string
+	Path         string
+	OutputFile   string
+	Action       string
+	PrMerged     string
+	SourceBranch string
+	// DestinationBranch is the base branch of the pull request,
+	// DefaultDestinationBranch when empty.
 	DestinationBranch string
+	// EventName is the GitHub event the check runs for, e.g. pull_request.
+	EventName string
+	// BaseSHA and HeadSHA are the base and head commits of the pull request,
+	// or the commits before and after a push.
+	BaseSHA string
+	HeadSHA string
+	// MergeSHA is the merge commit of a merged pull request. The merged
+	// conf.yaml is read from the tip of the destination branch when empty,
+	// which is wrong once later commits land on it.
+	MergeSHA string
+	// Labels are the labels of the pull request.
+	Labels []string
+	// InventoryFile is the path of environments.yaml inside the repository.
+	// When set, PRs touching environments missing from the inventory are rejected.
+	InventoryFile string
+	// BranchPolicyFile is the path of the branch policies inside the
+	// repository. When set, environments may only be deployed from the
+	// destination branches their policy allows.
+	BranchPolicyFile string
+	// ValuesPath is a checkout of the values repository. When set, onboarding
+	// an environment fails if the sealed secrets of its region are missing.
+	ValuesPath string
+	// SlackWebhookURL is the incoming webhook the decision is posted to when
+	// conf.yaml sets slackNotifyChannel and SlackWebhooks has no webhook for
+	// the channel. Webhooks of Slack apps ignore the channel of the payload
+	// and always post to the channel they were created for, so a single URL
+	// only serves one channel unless it is a legacy webhook.
+	SlackWebhookURL string
+	// SlackWebhooks maps channels, e.g. #hello, to the incoming webhook
+	// posting to them.
+	SlackWebhooks map[string]string
+	// SlackDryRunFile receives the JSON payload of the Slack message instead
+	// of posting it.
+	SlackDryRunFile string
+	// Reports are the JUnit, SARIF and step summary files the result is written to.
+	Reports report.Outputs
+	// Logger receives the progress of the check, slog.Default() when nil.
+	Logger *slog.Logger
+	// Annotator receives the failure of the check, placed at the position
+	// of the conf.yaml it is about. The failure is logged instead when it is
+	// nil or disabled.
+	Annotator logging.Annotator
+}
+
+// LogValue logs the options with the token and webhook redacted.
+func (opt DeployCheckerOption) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("mode", opt.mode()),
+		slog.Int("prNumber", opt.PrNumber),
+		slog.String("url", opt.Url),
+		slog.String("path", opt.Path),
+		slog.String("token", logging.Mask(opt.Token)),
+		slog.String("action", opt.Action),
+		slog.String("prMerged", opt.PrMerged),
+		slog.String("sourceBranch", opt.SourceBranch),
+		slog.String("destinationBranch", opt.DestinationBranch),
+		slog.String("eventName", opt.EventName),
+		slog.String("baseSha", opt.BaseSHA),
+		slog.String("headSha", opt.HeadSHA),
+		slog.String("mergeSha", opt.MergeSHA),
+		slog.String("inventoryFile", opt.InventoryFile),
+		slog.String("branchPolicyFile", opt.BranchPolicyFile),
+		slog.String("valuesPath", opt.ValuesPath),
+		slog.String("slackWebhookUrl", logging.Mask(opt.SlackWebhookURL)),
+		slog.Int("slackChannels", len(opt.SlackWebhooks)),
+	)
+}
+
+// slackWebhook returns the incoming webhook posting to channel, with or
+// without its leading #, and SlackWebhookURL when it has none.
+func (opt DeployCheckerOption) slackWebhook(channel string) string {
+	name := strings.TrimPrefix(channel, "#")
+	for _, key := range []string{channel, name, "#" + name} {
+		if url, ok := opt.SlackWebhooks[key]; ok {
+			return url
+		}
+	}
+	return opt.SlackWebhookURL
 }
 
 type DeployChecker struct {
-	gitClient      *qgit.Client
-	outputWriter   *utilities.FileOutputWriter
-	option         DeployCheckerOption
-	component      string
-	environment    string
-	needDeployment bool
-	version        string
-	heoRevision    string
-	isRelease      string
+	gitClient       *qgit.Client
+	outputWriter    *utilities.FileOutputWriter
+	option          DeployCheckerOption
+	file            string
+	component       string
+	environment     string
+	needDeployment  bool
+	version         string
+	heoRevision     string
+	isRelease       string
+	env             inventory.Environment
+	lifecycle       Lifecycle
+	conf            *ConfigFile
+	previousVersion string
+	state           PRState
 }
 
 func (gr *DeployChecker) GetConfFileChangedByPRNumber() ([]string, error) {
@@ -64,40 +170,130 @@ func (gr *DeployChecker) GetComponentConfFileChangedByPRNumber() (string, error)
 	//sourceBranch := "refs/remotes/origin/igboma-patch-40" // For remote branch
 	//refs/heads/"+ref
 	//files, err := gr.gitClient.ChangedFiles("main", "igboma-patch-40")
-	files, err := gr.gitClient.GetChangedFilesByPRNumber(gr.option.PrNumber)
-	fmt.Println(err)
-	fmt.Println(files)
+	files, err := gr.changedFiles()
+	if err != nil {
+		return "", err
+	}
+	gr.log().Debug("changed files", "files", files)
 
+	return confFile(files)
+}
+
+// confFile returns the conf.yaml of a change. A deployable change modifies a
+// single components/<component>/<environment>/conf.yaml.
+func confFile(files []string) (string, error) {
 	if len(files) < 1 {
-		return "", fmt.Errorf("no files found")
+		return "", &Error{Kind: KindConfFiles, Err: fmt.Errorf("no files found")}
 	}
 	if len(files) > 1 {
-		return "", fmt.Errorf("more than one file was changed")
+		return "", &Error{Kind: KindConfFiles, Err: fmt.Errorf("more than one file was changed")}
 	}
 
 	file := files[0]
 	// Ensure the file is a conf.yaml file
 	if !strings.Contains(file, "components/") || !strings.HasSuffix(file, "conf.yaml") {
-		return "", fmt.Errorf("the file is not a conf.yaml file")
+		return "", &Error{Kind: KindConfFiles, Err: fmt.Errorf("the file is not a conf.yaml file")}
 	}
 
 	return file, nil
 }
 
 func (gr *DeployChecker) getConfigData(file, ref string) (configData *ConfigFile, err error) {
-	fmt.Println("file ==>", file)
-	fmt.Println("ref ==>", ref)
+	gr.log().Debug("reading conf file", "file", file, "ref", ref)
 	configContent, err := gr.gitClient.FileContentFromBranch(ref, file)
-	fmt.Printf("content ==> %v \n", configContent)
 	if err != nil {
 		return
 	}
+	return ParseConfig([]byte(configContent))
+}
 
-	err = yaml.Unmarshal([]byte(configContent), &configData)
+// checkInventory fails if the environment of the changed conf.yaml is not
+// declared in the inventory at the given ref.
+func (gr *DeployChecker) checkInventory(ref string) error {
+	if gr.option.InventoryFile == "" {
+		return nil
+	}
+	inv, err := inventory.LoadFromRef(gr.gitClient, ref, gr.option.InventoryFile)
 	if err != nil {
-		return
+		return &Error{Kind: KindInvalidConfig, Err: err}
 	}
-	return
+	env, ok := inv.Get(gr.environment)
+	if !ok {
+		return &Error{Kind: KindInvalidConfig, Err: fmt.Errorf("environment %q is not declared in %s", gr.environment, gr.option.InventoryFile)}
+	}
+	gr.env = env
+	return nil
+}
+
+// destinationBranch returns the base branch of the pull request.
+func (gr *DeployChecker) destinationBranch() string {
+	if gr.option.DestinationBranch != "" {
+		return gr.option.DestinationBranch
+	}
+	return DefaultDestinationBranch
+}
+
+// remoteDestinationRef returns the remote-tracking ref of the destination branch.
+func (gr *DeployChecker) remoteDestinationRef() string {
+	return "refs/remotes/origin/" + gr.destinationBranch()
+}
+
+// mergedDestinationRef returns the ref holding the merge of the pull request:
+// the local destination branch, or its remote-tracking ref when the branch is
+// not checked out, e.g. a release branch in a clone of the default branch.
+func (gr *DeployChecker) mergedDestinationRef() string {
+	local := "refs/heads/" + gr.destinationBranch()
+	if gr.gitClient.HasRef(local) {
+		return local
+	}
+	return gr.remoteDestinationRef()
+}
+
+// mergeRef returns the merge commit of a merged pull request, or the ref of
+// the destination branch holding it when the event does not name it.
+func (gr *DeployChecker) mergeRef() string {
+	if gr.option.MergeSHA != "" {
+		return gr.option.MergeSHA
+	}
+	return gr.mergedDestinationRef()
+}
+
+// changedFiles returns the files of the pull request: the changes of its head
+// since it diverged from the destination branch. After a merge the branch
+// contains the head, so the first parent of the merge commit is used instead.
+func (gr *DeployChecker) changedFiles() ([]string, error) {
+	prRef, err := gr.gitClient.FetchPullRequest(gr.option.PrNumber)
+	if err != nil {
+		return nil, err
+	}
+	base := gr.remoteDestinationRef()
+	if gr.state.Step() == StepMerged {
+		base = gr.mergeRef()
+		parent, err := gr.gitClient.ParentCommit(base)
+		if err != nil {
+			return nil, err
+		}
+		if parent != "" {
+			base = parent
+		}
+	}
+	return gr.gitClient.ChangedFilesFromMergeBase(base, prRef)
+}
+
+// checkBranchPolicy fails if the environment of the changed conf.yaml may not
+// be deployed from the destination branch, per the policies at the given ref.
+func (gr *DeployChecker) checkBranchPolicy(ref string) error {
+	if gr.option.BranchPolicyFile == "" {
+		return nil
+	}
+	policies, err := LoadBranchPolicies(gr.gitClient, ref, gr.option.BranchPolicyFile)
+	if err != nil {
+		return &Error{Kind: KindInvalidConfig, Err: err}
+	}
+	if err := policies.Check(gr.destinationBranch(), gr.env); err != nil {
+		return &Error{Kind: KindInvalidConfig, Err: err}
+	}
+	return nil
 }
 
 func (gr *DeployChecker) RemoveVersionAndHeoRevision(config *ConfigFile) string {
@@ -113,83 +309,318 @@ func (gr *DeployChecker) GetSourceAndDestimationConf(file string, prNumber int,
 	if err != nil {
 		return
 	}
-	destRef := fmt.Sprintf("refs/remotes/origin/%v", destimationBranch)
+	destRef := "refs/remotes/origin/" + destimationBranch
 	previousConfig, err = gr.getConfigData(file, destRef)
-	if err != nil {
-		return
+	if errors.Is(err, qgit.ErrFileNotFound) {
+		// the PR adds the conf.yaml
+		return currentConfig, nil, nil
 	}
 	return
 }
 
+// getPreviousConfigData reads the conf.yaml from the first parent of the
+// commit ref points to. It returns nil when the file did not exist there.
+func (gr *DeployChecker) getPreviousConfigData(file, ref string) (*ConfigFile, error) {
+	parent, err := gr.gitClient.ParentCommit(ref)
+	if err != nil || parent == "" {
+		return nil, err
+	}
+	return gr.getConfigDataFromCommit(file, parent)
+}
+
+// checkLifecycle evaluates the onboarded flag of the changed conf.yaml. An
+// onboarding environment must pass CheckOnboarding, and deployments to an
+// environment that is not onboarded are suppressed.
+func (gr *DeployChecker) checkLifecycle(previous, current *ConfigFile) error {
+	gr.lifecycle = NewLifecycle(gr.environment, previous, current)
+	if gr.lifecycle.Onboarding {
+		var values fs.FS
+		if gr.option.ValuesPath != "" {
+			values = os.DirFS(gr.option.ValuesPath)
+		} else {
+			gr.log().Warn("no values path is set, the sealed secrets of the region are not checked", "environment", gr.environment)
+		}
+		if err := CheckOnboarding(current, gr.component, gr.env, values); err != nil {
+			return &Error{Kind: KindInvalidConfig, Err: fmt.Errorf("onboarding check failed for %s: %w", gr.environment, err)}
+		}
+	}
+	if gr.lifecycle.Suppressed() {
+		gr.needDeployment = false
+	}
+	return nil
+}
+
 func (gr *DeployChecker) WriteOutput(key, value string) error {
 	return gr.outputWriter.WriteOutput(key, value)
 }
 
+// Run checks the PR and writes the outputs and the reports, also when the check fails.
 func (gr *DeployChecker) Run() error {
-	file, err := gr.GetComponentConfFileChangedByPRNumber()
-	fmt.Printf("file: %v\n", file)
+	defer logging.Group(gr.log(), "Deploy check")()
+	start := time.Now()
+	err := gr.run()
+	res := gr.Result(err)
+	res.Duration = time.Since(start)
+
+	rep := report.New("Deploy check")
+	rep.Add(res)
+	if reportErr := gr.option.Reports.Write(rep); reportErr != nil && err == nil {
+		err = reportErr
+	}
+	if err == nil && gr.announces() {
+		gr.notify()
+	}
 	if err != nil {
-		return fmt.Errorf("error getting conf file %w", err)
+		if a := gr.annotator(); a.Enabled() && gr.file != "" {
+			a.Annotate(gr.Result(err).Annotation())
+		} else {
+			gr.log().Error(err.Error(), "kind", KindOf(err), "file", gr.file)
+		}
+		gr.outputWriter.WriteOutput("ERROR_KIND", string(KindOf(err)))
+	}
+	return err
+}
+
+// announces reports whether the decision is announced on Slack: only once
+// the change lands, on pushes and merged pull requests, not for every
+// update of an open pull request.
+func (gr *DeployChecker) announces() bool {
+	return gr.option.mode() == ModePush || gr.state.Step() == StepMerged
+}
+
+// notify announces the decision on the channel of the component. Failing to
+// reach Slack does not fail the check, the decision is already made.
+func (gr *DeployChecker) notify() {
+	d := gr.Decision()
+	webhookURL := gr.option.slackWebhook(d.Channel)
+	if d.Channel != "" && webhookURL == "" && gr.option.SlackDryRunFile == "" {
+		gr.log().Info("no slack webhook is configured, the channel is not notified", "channel", d.Channel)
+	}
+	if err := Notify(d, webhookURL, gr.option.SlackDryRunFile); err != nil {
+		gr.log().Warn("failed to notify slack", "channel", d.Channel, "error", err)
+	}
+}
+
+// Decision returns the deployment decision of the last check.
+func (gr *DeployChecker) Decision() Decision {
+	d := Decision{
+		Component:        gr.component,
+		Environment:      gr.environment,
+		PreviousVersion:  gr.previousVersion,
+		Version:          gr.version,
+		IsRelease:        gr.isRelease == "true",
+		NeedDeployment:   gr.needDeployment,
+		Onboarding:       gr.lifecycle.Onboarding,
+		SuppressedReason: gr.suppressedReason(),
+		PullRequestURL:   pullRequestURL(gr.option.Url, gr.option.PrNumber),
+	}
+	if gr.conf != nil {
+		d.Channel = gr.conf.SlackNotifyChannel
+	}
+	return d
+}
+
+// Result returns the outcome of the check as a report result.
+func (gr *DeployChecker) Result(err error) report.Result {
+	res := report.Result{
+		Component:   gr.component,
+		Environment: gr.environment,
+		Region:      inventory.FromName(gr.environment).Region,
+		Version:     gr.version,
+		Stage:       report.StageDeployCheck,
+		Status:      report.StatusPass,
+		File:        gr.file,
+	}
+	switch {
+	case err != nil:
+		res.Status = report.StatusFail
+		res.Message = err.Error()
+		res.Line, res.Column = PositionOf(err)
+	case gr.suppressedReason() != "":
+		res.Status = report.StatusSkip
+		res.Message = "deployment suppressed: " + gr.suppressedReason()
+	case gr.needDeployment:
+		res.Message = "deployment needed"
+	default:
+		res.Message = "no deployment needed"
+	}
+	return res
+}
+
+// suppressedReason explains why the check reports no deployment regardless of
+// the conf.yaml change, or is empty.
+func (gr *DeployChecker) suppressedReason() string {
+	if gr.state == StateClosedUnmerged {
+		return fmt.Sprintf("pull request #%d was closed without merging", gr.option.PrNumber)
+	}
+	return gr.lifecycle.SuppressedReason
+}
+
+func (gr *DeployChecker) run() error {
+	var err error
+	switch mode := gr.option.mode(); mode {
+	case ModePullRequest:
+		err = gr.runPullRequest()
+	case ModePush:
+		err = gr.runPush()
+	default:
+		err = fmt.Errorf("unsupported mode %q: expected %s or %s", mode, ModePullRequest, ModePush)
+	}
+	if err != nil {
+		return err
 	}
+	gr.writeOutputs()
+	return nil
+}
+
+// setFile records the changed conf.yaml and the component and environment it belongs to.
+func (gr *DeployChecker) setFile(file string) error {
+	gr.file = file
 	if len(strings.Split(file, "/")) > 2 {
 		gr.component = strings.Split(file, "/")[1]
 		gr.environment = strings.Split(file, "/")[2]
 	} else {
-		return fmt.Errorf("invalid config file")
+		return &Error{Kind: KindConfFiles, Err: fmt.Errorf("invalid config file")}
+	}
+	gr.env = inventory.FromName(gr.environment)
+	return nil
+}
+
+// compare decides whether the change of a conf.yaml from previous to current
+// needs a deployment. previous is nil when the change adds the file.
+func (gr *DeployChecker) compare(previous, current *ConfigFile) error {
+	if previous == nil {
+		gr.log().Info("comparing conf file", "file", gr.file, "version", current.Version, "previous", "added")
+	} else {
+		gr.log().Info("comparing conf file", "file", gr.file, "version", current.Version, "previous", previous.Version)
 	}
-	fmt.Printf("gr.option.: %v\n", gr.option)
-	if gr.option.Action == "closed" && gr.option.PrMerged == "true" {
-		//fmt.Println("PR is merged...")
-		configData, err := gr.getConfigData(file, "refs/heads/main")
-		fmt.Printf("configData: %v\n", configData)
+	gr.needDeployment = NeedsDeployment(previous, current)
+	if err := gr.checkLifecycle(previous, current); err != nil {
+		return err
+	}
+	gr.conf = current
+	if previous != nil {
+		gr.previousVersion = previous.Version
+	}
+
+	gr.version = current.Version
+	gr.heoRevision = current.HeoRevision
+
+	// Compare non-version and non-heoRevision fields
+	jsonCurrentOtherFields := gr.RemoveVersionAndHeoRevision(current)
+	jsonPreviousOtherFields := "null"
+	if previous != nil {
+		jsonPreviousOtherFields = gr.RemoveVersionAndHeoRevision(previous)
+	}
+
+	gr.log().Debug("other fields", "current", jsonCurrentOtherFields, "previous", jsonPreviousOtherFields)
+	return nil
+}
+
+func (gr *DeployChecker) runPullRequest() error {
+	if gr.option.PrNumber == 0 {
+		return fmt.Errorf("a pull request number is required in %s mode", ModePullRequest)
+	}
+	state, err := ParsePRState(gr.option.Action, gr.option.PrMerged)
+	if err != nil {
+		return err
+	}
+	gr.state = state
+	gr.log().Info("pull request", "number", gr.option.PrNumber, "state", gr.state)
+
+	file, err := gr.GetComponentConfFileChangedByPRNumber()
+	if err != nil {
+		return fmt.Errorf("error getting conf file %w", err)
+	}
+	if err := gr.setFile(file); err != nil {
+		return err
+	}
+	gr.log().Info("conf file", "file", file)
+	gr.log().Debug("options", "options", gr.option)
+	switch gr.state.Step() {
+	case StepSkip:
+		gr.log().Info("the pull request was closed without merging, no deployment is needed", "number", gr.option.PrNumber)
+	case StepMerged:
+		ref := gr.mergeRef()
+		if err := gr.checkInventory(ref); err != nil {
+			return fmt.Errorf("inventory check failed: %w", err)
+		}
+		if err := gr.checkBranchPolicy(ref); err != nil {
+			return fmt.Errorf("branch policy check failed: %w", err)
+		}
+		configData, err := gr.getConfigData(file, ref)
 		if err != nil {
 			return fmt.Errorf("failed to get version and heoRevision: %w", err)
 		}
-		gr.version = configData.Version
-		gr.heoRevision = configData.HeoRevision
-	} else {
-		source, destination, err := gr.GetSourceAndDestimationConf(file, gr.option.PrNumber, "main")
+		gr.log().Info("merged conf file", "file", file, "version", configData.Version, "heoRevision", configData.HeoRevision)
+
+		previous, err := gr.getPreviousConfigData(file, ref)
+		if err != nil {
+			return fmt.Errorf("failed to get the previous conf file: %w", err)
+		}
+		if err := gr.compare(previous, configData); err != nil {
+			return err
+		}
+	case StepCompare:
+		if err := gr.checkInventory(gr.remoteDestinationRef()); err != nil {
+			return fmt.Errorf("inventory check failed: %w", err)
+		}
+		if err := gr.checkBranchPolicy(gr.remoteDestinationRef()); err != nil {
+			return fmt.Errorf("branch policy check failed: %w", err)
+		}
+		source, destination, err := gr.GetSourceAndDestimationConf(file, gr.option.PrNumber, gr.destinationBranch())
 		if err != nil {
 			return fmt.Errorf("error checking version and heoRevision: %w", err)
 		}
-		fmt.Printf("\nsource: %v\n", source.Version)
-		fmt.Printf("destination: %v\n", destination.Version)
-
-		gr.needDeployment = source.Version != destination.Version || source.HeoRevision != destination.HeoRevision
-
-		gr.version = source.Version
-		gr.heoRevision = source.HeoRevision
-
-		// Compare non-version and non-heoRevision fields
-		jsonCurrentOtherFields := gr.RemoveVersionAndHeoRevision(source)
-		jsonPreviousOtherFields := gr.RemoveVersionAndHeoRevision(destination)
-
-		fmt.Printf("+version: %s\n", gr.version)
-		fmt.Printf("jsonCurrentOtherFields: %s\n", jsonCurrentOtherFields)
-		fmt.Printf("jsonPreviousOtherFields: %s\n", jsonPreviousOtherFields)
+		if err := gr.compare(destination, source); err != nil {
+			return err
+		}
 	}
 
+	return nil
+}
+
+// writeOutputs writes the decision to $GITHUB_OUTPUT and the log.
+func (gr *DeployChecker) writeOutputs() {
 	// Determine if it is a release version
 	gr.isRelease = "true"
-	if strings.Contains(gr.version, "-") {
+	if gr.version == "" || strings.Contains(gr.version, "-") {
 		gr.isRelease = "false"
 	}
 
+	gr.outputWriter.WriteOutput("PR_STATE", string(gr.state))
 	gr.outputWriter.WriteOutput("COMPONENT", gr.component)
 	gr.outputWriter.WriteOutput("ENVIRONMENT", gr.environment)
 	gr.outputWriter.WriteOutput("VERSION", gr.version)
 	gr.outputWriter.WriteOutput("IS_RELEASE", gr.isRelease)
 	gr.outputWriter.WriteOutput("HEO_REVISION", gr.heoRevision)
 	gr.outputWriter.WriteOutput("DEPLOYMENT_NEEDED", fmt.Sprintf("%t", gr.needDeployment))
+	gr.outputWriter.WriteOutput("ONBOARDING", fmt.Sprintf("%t", gr.lifecycle.Onboarding))
+	gr.outputWriter.WriteOutput("DEPLOYMENT_SUPPRESSED_REASON", gr.suppressedReason())
 
-	fmt.Printf("COMPONENT=%s\n", gr.component)
-	fmt.Printf("ENVIRONMENT=%s\n", gr.environment)
-	fmt.Printf("VERSION=%s\n", gr.version)
-	fmt.Printf("IS_RELEASE=%s\n", gr.isRelease)
-	fmt.Printf("HEO_REVISION=%s\n", gr.heoRevision)
-	fmt.Printf("DEPLOYMENT_NEEDED=%s\n", fmt.Sprintf("%t", gr.needDeployment))
+	gr.log().Info("deploy check outputs",
+		"PR_STATE", gr.state,
+		"COMPONENT", gr.component,
+		"ENVIRONMENT", gr.environment,
+		"VERSION", gr.version,
+		"IS_RELEASE", gr.isRelease,
+		"HEO_REVISION", gr.heoRevision,
+		"DEPLOYMENT_NEEDED", gr.needDeployment,
+		"ONBOARDING", gr.lifecycle.Onboarding,
+		"DEPLOYMENT_SUPPRESSED_REASON", gr.suppressedReason(),
+	)
+}
 
-	return nil
+// log returns the logger of the options.
+func (gr *DeployChecker) log() *slog.Logger {
+	return logging.OrDefault(gr.option.Logger)
+}
+
+func (gr *DeployChecker) annotator() logging.Annotator {
+	if gr.option.Annotator == nil {
+		return logging.NopAnnotator()
+	}
+	return gr.option.Annotator
 }
 
 func NewDeployChecker(opt DeployCheckerOption) (*DeployChecker, error) {
@@ -197,16 +628,12 @@ func NewDeployChecker(opt DeployCheckerOption) (*DeployChecker, error) {
 		qgit.WithRepoPath(opt.Path),
 		qgit.WithRepoUrl(opt.Url),
 		qgit.WithToken(opt.Token),
+		qgit.WithLogger(opt.Logger),
 	)
-
 	if err != nil {
-		fmt.Println("Error NewClient Repo")
 		return nil, err
 	}
-	err = client.InitRepo()
-
-	if err != nil {
-		fmt.Println("Error init Repo")
+	if err := client.InitRepo(); err != nil {
 		return nil, err
 	}
 
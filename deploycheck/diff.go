@@ -0,0 +1,113 @@
+package deploycheck
+
+import (
+	"fmt"
+	"io/fs"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FieldChange is a conf.yaml field whose value differs between two versions of the file.
+type FieldChange struct {
+	// Field is the yaml key, e.g. heoRevision.
+	Field    string `json:"field"`
+	Previous string `json:"previous"`
+	Current  string `json:"current"`
+}
+
+// DiffConfig returns the fields changed from previous to current, in the
+// order of ConfigFile. A nil config has no fields set.
+func DiffConfig(previous, current *ConfigFile) []FieldChange {
+	prev, cur := reflect.ValueOf(ConfigFile{}), reflect.ValueOf(ConfigFile{})
+	if previous != nil {
+		prev = reflect.ValueOf(*previous)
+	}
+	if current != nil {
+		cur = reflect.ValueOf(*current)
+	}
+	var changes []FieldChange
+	t := prev.Type()
+	for i := 0; i < t.NumField(); i++ {
+		p, c := fmt.Sprint(prev.Field(i).Interface()), fmt.Sprint(cur.Field(i).Interface())
+		if p == c {
+			continue
+		}
+		field, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		changes = append(changes, FieldChange{Field: field, Previous: p, Current: c})
+	}
+	return changes
+}
+
+// NeedsDeployment reports whether changing a conf.yaml from previous to
+// current needs a deployment: the file is added, or its version or
+// heoRevision changed.
+func NeedsDeployment(previous, current *ConfigFile) bool {
+	if previous == nil {
+		return true
+	}
+	return current.Version != previous.Version || current.HeoRevision != previous.HeoRevision
+}
+
+// Drift is a component whose environments of the same tier run different
+// versions or heoRevisions.
+type Drift struct {
+	Component string `json:"component"`
+	Tier      string `json:"tier"`
+	// Versions maps the environments of the tier to "<version>@<heoRevision>".
+	Versions map[string]string `json:"versions"`
+}
+
+// FindDrift reads the components/<component>/<environment>/conf.yaml files of
+// fsys and returns the drifting components, sorted by component and tier.
+// tier returns the tier of an environment; environments without a tier and
+// environments that are not onboarded are ignored.
+func FindDrift(fsys fs.FS, tier func(environment string) string) ([]Drift, error) {
+	files, err := fs.Glob(fsys, "components/*/*/conf.yaml")
+	if err != nil {
+		return nil, err
+	}
+	byTier := map[[2]string]map[string]string{}
+	for _, file := range files {
+		parts := strings.Split(file, "/")
+		component, environment := parts[1], parts[2]
+		t := tier(environment)
+		if t == "" {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		conf, err := ParseConfig(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if !conf.IsOnboarded() {
+			continue
+		}
+		key := [2]string{component, t}
+		if byTier[key] == nil {
+			byTier[key] = map[string]string{}
+		}
+		byTier[key][environment] = conf.Version + "@" + conf.HeoRevision
+	}
+
+	var drifts []Drift
+	for key, versions := range byTier {
+		distinct := map[string]bool{}
+		for _, v := range versions {
+			distinct[v] = true
+		}
+		if len(distinct) > 1 {
+			drifts = append(drifts, Drift{Component: key[0], Tier: key[1], Versions: versions})
+		}
+	}
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].Component != drifts[j].Component {
+			return drifts[i].Component < drifts[j].Component
+		}
+		return drifts[i].Tier < drifts[j].Tier
+	})
+	return drifts, nil
+}
@@ -0,0 +1,79 @@
+package deploycheck_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"gitpkg/deploycheck"
+	"gitpkg/inventory"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffConfig(t *testing.T) {
+	t.Run("DiffConfig returns the changed fields by yaml key", func(t *testing.T) {
+		// Arrange
+		previous := &deploycheck.ConfigFile{Version: "1.0.0", Namespace: "hello", DeploymentWindow: 30}
+		current := &deploycheck.ConfigFile{Version: "1.1.0", Namespace: "hello", HeoRevision: "abc", DeploymentWindow: 30}
+
+		// Act
+		changes := deploycheck.DiffConfig(previous, current)
+
+		// Assert
+		assert.Equal(t, []deploycheck.FieldChange{
+			{Field: "version", Previous: "1.0.0", Current: "1.1.0"},
+			{Field: "heoRevision", Previous: "", Current: "abc"},
+		}, changes)
+	})
+
+	t.Run("DiffConfig treats a nil previous config as empty", func(t *testing.T) {
+		changes := deploycheck.DiffConfig(nil, &deploycheck.ConfigFile{Namespace: "hello"})
+
+		assert.Equal(t, []deploycheck.FieldChange{{Field: "namespace", Previous: "", Current: "hello"}}, changes)
+	})
+
+	t.Run("NeedsDeployment ignores fields other than version and heoRevision", func(t *testing.T) {
+		previous := &deploycheck.ConfigFile{Version: "1.0.0", HeoRevision: "abc"}
+
+		assert.True(t, deploycheck.NeedsDeployment(nil, previous))
+		assert.True(t, deploycheck.NeedsDeployment(previous, &deploycheck.ConfigFile{Version: "1.0.0", HeoRevision: "def"}))
+		assert.False(t, deploycheck.NeedsDeployment(previous, &deploycheck.ConfigFile{Version: "1.0.0", HeoRevision: "abc", SlackNotifyChannel: "#hello"}))
+	})
+}
+
+func TestFindDrift(t *testing.T) {
+	// Arrange
+	fsys := fstest.MapFS{
+		"components/hello/qcs-stage-us-east-1/conf.yaml": {Data: []byte("version: 1.1.0\nheoRevision: abc\n")},
+		"components/hello/qcs-stage-eu-west-1/conf.yaml": {Data: []byte("version: 1.0.0\nheoRevision: abc\n")},
+		"components/hello/qcs-prod-us-east-1/conf.yaml":  {Data: []byte("version: 1.0.0\nheoRevision: abc\n")},
+		"components/hello/qcs-prod-eu-west-1/conf.yaml":  {Data: []byte("version: 1.0.0\nheoRevision: abc\n")},
+		"components/hello/qcs-prod-ap-south-1/conf.yaml": {Data: []byte("version: 0.9.0\nonboarded: \"false\"\n")},
+		"components/world/qcs-stage-us-east-1/conf.yaml": {Data: []byte("version: 2.0.0\n")},
+	}
+	tier := func(name string) string { return inventory.FromName(name).Tier }
+
+	t.Run("FindDrift reports the tiers whose environments differ", func(t *testing.T) {
+		// Act
+		drifts, err := deploycheck.FindDrift(fsys, tier)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []deploycheck.Drift{{
+			Component: "hello",
+			Tier:      "stage",
+			Versions: map[string]string{
+				"qcs-stage-us-east-1": "1.1.0@abc",
+				"qcs-stage-eu-west-1": "1.0.0@abc",
+			},
+		}}, drifts)
+	})
+
+	t.Run("FindDrift fails on an invalid conf.yaml", func(t *testing.T) {
+		broken := fstest.MapFS{"components/hello/qcs-stage-us-east-1/conf.yaml": {Data: []byte("version: [")}}
+
+		_, err := deploycheck.FindDrift(broken, tier)
+
+		assert.ErrorContains(t, err, "components/hello/qcs-stage-us-east-1/conf.yaml")
+	})
+}
@@ -0,0 +1,57 @@
+package deploycheck
+
+import (
+	"errors"
+
+	"gitpkg/qgit"
+	"gitpkg/utilities"
+)
+
+// ErrorKind classifies why a deploy check failed. It is written to the
+// ERROR_KIND output so workflows can react to the failure, e.g. retry
+// network errors.
+type ErrorKind string
+
+const (
+	KindAuth        = ErrorKind(qgit.KindAuth)
+	KindRefNotFound = ErrorKind(qgit.KindRefNotFound)
+	KindNetwork     = ErrorKind(qgit.KindNetwork)
+	// KindConfFiles is a change that does not modify exactly one conf.yaml.
+	KindConfFiles ErrorKind = "conf-files"
+	// KindInvalidConfig is a conf.yaml that cannot be parsed, is incomplete, or
+	// whose environment the inventory or the branch policies reject.
+	KindInvalidConfig ErrorKind = "invalid-config"
+	// KindInternal is every other failure.
+	KindInternal ErrorKind = "internal"
+)
+
+// Error is a deploy check failure of a known kind.
+type Error struct {
+	Kind ErrorKind
+	Err  error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// KindOf returns the kind of a failure of the deploy check: the kind of the
+// git failure it wraps, else the kind of its *Error, else KindInternal.
+func KindOf(err error) ErrorKind {
+	if err == nil {
+		return ""
+	}
+	if kind := qgit.KindOf(err); kind != "" {
+		return ErrorKind(kind)
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindInternal
+}
+
+// WriteErrorKind writes the kind of err as the ERROR_KIND output to outputFile.
+func WriteErrorKind(outputFile string, err error) error {
+	return utilities.NewFileOutputWriter(outputFile).WriteOutput("ERROR_KIND", string(KindOf(err)))
+}
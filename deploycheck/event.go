@@ -0,0 +1,36 @@
+package deploycheck
+
+import (
+	"strconv"
+
+	"gitpkg/ghevent"
+)
+
+// ApplyEvent sets the pull request, branches, commits and labels of the
+// options from the event of the workflow run. Fields the event does not
+// carry are left unchanged. A workflow_dispatch event checks the pull request
+// it names as if it was updated: the head is compared with the destination
+// branch and nothing is announced.
+func (opt *DeployCheckerOption) ApplyEvent(e *ghevent.Event) {
+	opt.EventName = e.Name
+	if e.RepoURL != "" {
+		opt.Url = e.RepoURL
+	}
+	if e.PRNumber != 0 {
+		opt.PrNumber = e.PRNumber
+	}
+	if e.Name == ghevent.PullRequest {
+		opt.Action = e.Action
+		opt.PrMerged = strconv.FormatBool(e.Merged)
+		opt.MergeSHA = e.MergeSHA
+		opt.Labels = e.Labels
+	}
+	if e.Name == ghevent.WorkflowDispatch {
+		opt.Action = string(StateSynchronize)
+		opt.PrMerged = "false"
+	}
+	opt.SourceBranch = e.HeadRef
+	opt.DestinationBranch = e.BaseRef
+	opt.HeadSHA = e.HeadSHA
+	opt.BaseSHA = e.BaseSHA
+}
@@ -0,0 +1,75 @@
+package deploycheck_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gitpkg/deploycheck"
+	"gitpkg/ghevent"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployCheckerOption_ApplyEvent(t *testing.T) {
+	t.Run("ApplyEvent sets the pull request fields of a pull_request event", func(t *testing.T) {
+		// Arrange
+		e, err := ghevent.Load(ghevent.PullRequest, filepath.Join("..", "ghevent", "testdata", "pull_request_opened.json"))
+		assert.NoError(t, err)
+		opt := deploycheck.DeployCheckerOption{Path: "/workspace"}
+
+		// Act
+		opt.ApplyEvent(e)
+
+		// Assert
+		assert.Equal(t, deploycheck.DeployCheckerOption{
+			Path:              "/workspace",
+			EventName:         ghevent.PullRequest,
+			Url:               "https://github.com/qlik-trial/gitops-environments.git",
+			PrNumber:          7,
+			Action:            "opened",
+			PrMerged:          "false",
+			SourceBranch:      "feature",
+			DestinationBranch: "release/2024.10",
+			HeadSHA:           "3333333333333333333333333333333333333333",
+			BaseSHA:           "4444444444444444444444444444444444444444",
+		}, opt)
+	})
+
+	t.Run("ApplyEvent sets the merge commit of a merged pull request", func(t *testing.T) {
+		e, err := ghevent.Load(ghevent.PullRequest, filepath.Join("..", "ghevent", "testdata", "pull_request_closed.json"))
+		assert.NoError(t, err)
+		opt := deploycheck.DeployCheckerOption{}
+
+		opt.ApplyEvent(e)
+
+		assert.Equal(t, "true", opt.PrMerged)
+		assert.Equal(t, "9f3c1b2a4d5e6f708192a3b4c5d6e7f809a1b2c3", opt.MergeSHA)
+	})
+
+	t.Run("ApplyEvent keeps the pull request fields a push does not carry", func(t *testing.T) {
+		e, err := ghevent.Load(ghevent.Push, filepath.Join("..", "ghevent", "testdata", "push.json"))
+		assert.NoError(t, err)
+		opt := deploycheck.DeployCheckerOption{PrNumber: 3, Action: "opened"}
+
+		opt.ApplyEvent(e)
+
+		assert.Equal(t, 3, opt.PrNumber)
+		assert.Equal(t, "opened", opt.Action)
+		assert.Equal(t, "main", opt.DestinationBranch)
+		assert.Equal(t, "6666666666666666666666666666666666666666", opt.HeadSHA)
+	})
+
+	t.Run("ApplyEvent checks the pull request of a workflow_dispatch event as updated", func(t *testing.T) {
+		e, err := ghevent.Load(ghevent.WorkflowDispatch, filepath.Join("..", "ghevent", "testdata", "workflow_dispatch.json"))
+		assert.NoError(t, err)
+		opt := deploycheck.DeployCheckerOption{}
+
+		opt.ApplyEvent(e)
+
+		assert.Equal(t, 42, opt.PrNumber)
+		assert.Equal(t, "main", opt.DestinationBranch)
+		state, err := deploycheck.ParsePRState(opt.Action, opt.PrMerged)
+		assert.NoError(t, err)
+		assert.Equal(t, deploycheck.StepCompare, state.Step())
+	})
+}
@@ -0,0 +1,102 @@
+package deploycheck
+
+import (
+	"fmt"
+	"strings"
+
+	"gitpkg/slack"
+)
+
+// Decision is the outcome of a deploy check announced to the Slack channel of
+// the component.
+type Decision struct {
+	Component       string `json:"component"`
+	Environment     string `json:"environment"`
+	Channel         string `json:"channel,omitempty"`
+	PreviousVersion string `json:"previousVersion"`
+	Version         string `json:"version"`
+	IsRelease       bool   `json:"isRelease"`
+	NeedDeployment  bool   `json:"needDeployment"`
+	Onboarding      bool   `json:"onboarding"`
+	// SuppressedReason is set when deployments to the environment are suppressed.
+	SuppressedReason string `json:"suppressedReason,omitempty"`
+	// PullRequestURL links the pull request the decision was made for.
+	PullRequestURL string `json:"pullRequestUrl,omitempty"`
+}
+
+// Summary returns the one line description of the decision, used as the
+// notification text of the message.
+func (d Decision) Summary() string {
+	switch {
+	case d.SuppressedReason != "":
+		return fmt.Sprintf("Deployment of %s to %s is suppressed", d.Component, d.Environment)
+	case d.Onboarding:
+		return fmt.Sprintf("%s is onboarding to %s with %s", d.Component, d.Environment, d.Version)
+	case d.NeedDeployment:
+		return fmt.Sprintf("%s %s is deploying to %s", d.Component, d.Version, d.Environment)
+	default:
+		return fmt.Sprintf("No deployment of %s to %s is needed", d.Component, d.Environment)
+	}
+}
+
+// Message returns the Block Kit message describing the decision.
+func (d Decision) Message() slack.Message {
+	release := "pre-release"
+	if d.IsRelease {
+		release = "release"
+	}
+	previous := d.PreviousVersion
+	if previous == "" {
+		previous = "none"
+	}
+	blocks := []slack.Block{
+		slack.Header(d.Summary()),
+		slack.Fields(
+			"*Component*\n"+slack.Escape(d.Component),
+			"*Environment*\n"+slack.Escape(d.Environment),
+			"*Version*\n"+slack.Escape(fmt.Sprintf("%s → %s", previous, d.Version)),
+			"*Type*\n"+release,
+		),
+	}
+	if d.SuppressedReason != "" {
+		blocks = append(blocks, slack.Section(":no_entry: "+slack.Escape(d.SuppressedReason)))
+	}
+	var context []string
+	if d.PullRequestURL != "" {
+		context = append(context, slack.Link(d.PullRequestURL, "Pull request"))
+	}
+	context = append(context, fmt.Sprintf("Deployment needed: *%t*", d.NeedDeployment))
+	blocks = append(blocks, slack.Context(context...))
+	return slack.Message{Channel: d.Channel, Text: d.Summary(), Blocks: blocks}
+}
+
+// Notify announces the decision on its channel: it posts the message to
+// webhookURL or, when dryRunFile is set, writes the payload to that file.
+// Decisions without a channel, or without a webhook or file to send them
+// to, are not announced.
+func Notify(d Decision, webhookURL, dryRunFile string) error {
+	if d.Channel == "" {
+		return nil
+	}
+	msg := d.Message()
+	if dryRunFile != "" {
+		return slack.WriteFile(dryRunFile, msg)
+	}
+	if webhookURL == "" {
+		return nil
+	}
+	webhook := &slack.Webhook{URL: webhookURL}
+	if err := webhook.Post(msg); err != nil {
+		return fmt.Errorf("failed to notify %s: %w", d.Channel, err)
+	}
+	return nil
+}
+
+// pullRequestURL returns the web URL of a pull request of the repository
+// cloned from gitURL.
+func pullRequestURL(gitURL string, prNumber int) string {
+	if gitURL == "" || prNumber == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s/pull/%d", strings.TrimSuffix(strings.TrimSuffix(gitURL, "/"), ".git"), prNumber)
+}
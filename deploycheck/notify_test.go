@@ -0,0 +1,100 @@
+package deploycheck_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitpkg/deploycheck"
+	"gitpkg/slack"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecision_Message(t *testing.T) {
+	t.Run("Message describes the versions, release type and pull request", func(t *testing.T) {
+		// Arrange
+		d := deploycheck.Decision{
+			Component:       "hello",
+			Environment:     "qcs-stage-us-east-1",
+			Channel:         "#hello",
+			PreviousVersion: "1.0.0",
+			Version:         "1.1.0-rc.1",
+			NeedDeployment:  true,
+			PullRequestURL:  "https://github.com/org/repo/pull/12",
+		}
+
+		// Act
+		msg := d.Message()
+
+		// Assert
+		assert.Equal(t, "#hello", msg.Channel)
+		assert.Equal(t, "hello 1.1.0-rc.1 is deploying to qcs-stage-us-east-1", msg.Text)
+		assert.Equal(t, []slack.Block{
+			slack.Header("hello 1.1.0-rc.1 is deploying to qcs-stage-us-east-1"),
+			slack.Fields("*Component*\nhello", "*Environment*\nqcs-stage-us-east-1", "*Version*\n1.0.0 → 1.1.0-rc.1", "*Type*\npre-release"),
+			slack.Context("<https://github.com/org/repo/pull/12|Pull request>", "Deployment needed: *true*"),
+		}, msg.Blocks)
+	})
+
+	t.Run("Message explains suppressed deployments", func(t *testing.T) {
+		d := deploycheck.Decision{Component: "hello", Environment: "qcs-stage-us-east-1", Version: "1.0.0", IsRelease: true, SuppressedReason: "not onboarded"}
+
+		msg := d.Message()
+
+		assert.Equal(t, "Deployment of hello to qcs-stage-us-east-1 is suppressed", msg.Text)
+		assert.Contains(t, msg.Blocks, slack.Section(":no_entry: not onboarded"))
+		assert.Contains(t, msg.Blocks, slack.Fields("*Component*\nhello", "*Environment*\nqcs-stage-us-east-1", "*Version*\nnone → 1.0.0", "*Type*\nrelease"))
+	})
+}
+
+func TestNotify(t *testing.T) {
+	d := deploycheck.Decision{Component: "hello", Environment: "qcs-stage-us-east-1", Channel: "#hello", Version: "1.0.0"}
+
+	t.Run("Notify posts the message to the webhook", func(t *testing.T) {
+		// Arrange
+		var got slack.Message
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		}))
+		defer server.Close()
+
+		// Act
+		err := deploycheck.Notify(d, server.URL, "")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, d.Message(), got)
+	})
+
+	t.Run("Notify writes the payload to the dry-run file instead of posting it", func(t *testing.T) {
+		posted := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { posted = true }))
+		defer server.Close()
+		file := filepath.Join(t.TempDir(), "slack.json")
+
+		err := deploycheck.Notify(d, server.URL, file)
+
+		assert.NoError(t, err)
+		assert.False(t, posted)
+		var got slack.Message
+		data, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		assert.NoError(t, json.Unmarshal(data, &got))
+		assert.Equal(t, d.Message(), got)
+	})
+
+	t.Run("Notify skips decisions without a channel", func(t *testing.T) {
+		d := d
+		d.Channel = ""
+		file := filepath.Join(t.TempDir(), "slack.json")
+
+		err := deploycheck.Notify(d, "", file)
+
+		assert.NoError(t, err)
+		assert.NoFileExists(t, file)
+	})
+}
@@ -0,0 +1,84 @@
+package deploycheck
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gitpkg/inventory"
+	"gitpkg/sealedsecrets"
+)
+
+// IsOnboarded reports whether deployments to the environment of the conf.yaml
+// are enabled. Only an explicit `onboarded: "false"` disables them, so files
+// written before the flag existed keep deploying.
+func (c *ConfigFile) IsOnboarded() bool {
+	return c == nil || !strings.EqualFold(strings.TrimSpace(c.Onboarded), "false")
+}
+
+// Lifecycle is the onboarding state of an environment derived from a conf.yaml change.
+type Lifecycle struct {
+	// Onboarding is set when onboarded changes from false to true.
+	Onboarding bool
+	// SuppressedReason explains why deployments to the environment are
+	// suppressed. It is empty when deployments proceed.
+	SuppressedReason string
+}
+
+// Suppressed reports whether deployments to the environment are suppressed.
+func (l Lifecycle) Suppressed() bool {
+	return l.SuppressedReason != ""
+}
+
+// NewLifecycle compares the conf.yaml of an environment before and after a
+// change. previous is nil when the change adds the file.
+func NewLifecycle(environment string, previous, current *ConfigFile) Lifecycle {
+	if !current.IsOnboarded() {
+		return Lifecycle{SuppressedReason: fmt.Sprintf("environment %s is not onboarded: set onboarded to \"true\" in its conf.yaml to enable deployments", environment)}
+	}
+	return Lifecycle{Onboarding: previous != nil && !previous.IsOnboarded()}
+}
+
+// CheckOnboarding verifies an environment is ready to receive its first
+// deployment: the namespace is declared in conf.yaml and, when the values of
+// the component use sealed secrets for the environment, the sealed-secrets
+// file of its region exists in values. values is the root of the values
+// repository; the sealed secrets are not checked when it is nil.
+func CheckOnboarding(conf *ConfigFile, component string, env inventory.Environment, values fs.FS) error {
+	var errs []error
+	if strings.TrimSpace(conf.Namespace) == "" {
+		errs = append(errs, fmt.Errorf("namespace is not declared in conf.yaml"))
+	}
+	if values != nil {
+		if err := checkSealedSecrets(component, env, values); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func checkSealedSecrets(component string, env inventory.Environment, values fs.FS) error {
+	if !env.Known() {
+		return fmt.Errorf("tier and region of environment %s are unknown", env.Name)
+	}
+	valuesFile := path.Join("qcs", component, "values.yaml")
+	text, err := fs.ReadFile(values, valuesFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", valuesFile, err)
+	}
+	uses, err := sealedsecrets.UsesSealedSecrets(string(text), env)
+	if err != nil {
+		return err
+	}
+	if !uses {
+		return nil
+	}
+	file := filepath.ToSlash(sealedsecrets.File(component, env.Tier, env.Region))
+	if _, err := fs.Stat(values, file); err != nil {
+		return fmt.Errorf("sealed secrets of %s/%s do not exist: %s is missing", env.Tier, env.Region, file)
+	}
+	return nil
+}
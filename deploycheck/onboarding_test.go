@@ -0,0 +1,98 @@
+package deploycheck_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"gitpkg/deploycheck"
+	"gitpkg/inventory"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLifecycle(t *testing.T) {
+	notOnboarded := &deploycheck.ConfigFile{Onboarded: "false"}
+	onboarded := &deploycheck.ConfigFile{Onboarded: "true"}
+	legacy := &deploycheck.ConfigFile{}
+
+	t.Run("NewLifecycle detects onboarded changing from false to true", func(t *testing.T) {
+		// Act
+		lifecycle := deploycheck.NewLifecycle("qcs-stage-us-east-1", notOnboarded, onboarded)
+
+		// Assert
+		assert.True(t, lifecycle.Onboarding)
+		assert.False(t, lifecycle.Suppressed())
+	})
+
+	t.Run("NewLifecycle suppresses deployments to environments that are not onboarded", func(t *testing.T) {
+		for _, previous := range []*deploycheck.ConfigFile{nil, notOnboarded, onboarded} {
+			lifecycle := deploycheck.NewLifecycle("qcs-stage-us-east-1", previous, notOnboarded)
+
+			assert.False(t, lifecycle.Onboarding)
+			assert.True(t, lifecycle.Suppressed())
+			assert.Contains(t, lifecycle.SuppressedReason, "environment qcs-stage-us-east-1 is not onboarded")
+		}
+	})
+
+	t.Run("NewLifecycle treats conf files without the flag and new files as onboarded", func(t *testing.T) {
+		for _, previous := range []*deploycheck.ConfigFile{nil, legacy, onboarded} {
+			lifecycle := deploycheck.NewLifecycle("qcs-stage-us-east-1", previous, legacy)
+
+			assert.Equal(t, deploycheck.Lifecycle{}, lifecycle)
+		}
+	})
+}
+
+func TestCheckOnboarding(t *testing.T) {
+	values := `{{ $environment := (getenv "ENVIRONMENT") -}}
+{{ $region := (getenv "REGION") -}}
+{{ $provider := (getenv "PROVIDER") -}}
+{{ $useSealedSecrets := (ne $provider "fedramp") -}}
+image: hello
+`
+	env := inventory.FromName("qcs-stage-us-east-1")
+
+	t.Run("CheckOnboarding passes when the namespace and the sealed secrets of the region exist", func(t *testing.T) {
+		// Arrange
+		fsys := fstest.MapFS{
+			"qcs/hello/values.yaml": {Data: []byte(values)},
+			"qcs/hello/sealed-secrets/stage/us-east-1/sealed-secrets.yaml": {Data: []byte("sealedSecrets: {}\n")},
+		}
+
+		// Act
+		err := deploycheck.CheckOnboarding(&deploycheck.ConfigFile{Namespace: "hello"}, "hello", env, fsys)
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("CheckOnboarding reports a missing namespace and missing sealed secrets", func(t *testing.T) {
+		// Arrange
+		fsys := fstest.MapFS{
+			"qcs/hello/values.yaml": {Data: []byte(values)},
+			"qcs/hello/sealed-secrets/prod/us-east-1/sealed-secrets.yaml": {Data: []byte("sealedSecrets: {}\n")},
+		}
+
+		// Act
+		err := deploycheck.CheckOnboarding(&deploycheck.ConfigFile{}, "hello", env, fsys)
+
+		// Assert
+		assert.ErrorContains(t, err, "namespace is not declared in conf.yaml")
+		assert.ErrorContains(t, err, "sealed secrets of stage/us-east-1 do not exist")
+	})
+
+	t.Run("CheckOnboarding skips the sealed secrets of environments not using them", func(t *testing.T) {
+		fsys := fstest.MapFS{"qcs/hello/values.yaml": {Data: []byte(values)}}
+		fedramp := inventory.Environment{Name: "qcs-prod-fedramp-us-east-1", Tier: "prod", Region: "us-east-1", Provider: "fedramp"}
+
+		err := deploycheck.CheckOnboarding(&deploycheck.ConfigFile{Namespace: "hello"}, "hello", fedramp, fsys)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("CheckOnboarding only checks the namespace without a values repository", func(t *testing.T) {
+		err := deploycheck.CheckOnboarding(&deploycheck.ConfigFile{Namespace: "hello"}, "hello", env, nil)
+
+		assert.NoError(t, err)
+	})
+}
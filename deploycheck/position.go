@@ -0,0 +1,95 @@
+package deploycheck
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	yamlv2 "gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigError is a problem at a position of a conf.yaml.
+type ConfigError struct {
+	// Line and Col are 1-based; Col is 0 when only the line is known and
+	// both are 0 when the position is unknown.
+	Line int
+	Col  int
+	Err  error
+}
+
+func (e *ConfigError) Error() string {
+	switch {
+	case e.Col > 0:
+		return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Col, e.Err)
+	case e.Line > 0:
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	default:
+		return e.Err.Error()
+	}
+}
+
+func (e *ConfigError) Unwrap() error { return e.Err }
+
+// PositionOf returns the line and column of the conf.yaml problem err wraps,
+// zeros when there is none.
+func PositionOf(err error) (line, col int) {
+	var e *ConfigError
+	if errors.As(err, &e) {
+		return e.Line, e.Col
+	}
+	return 0, 0
+}
+
+// syntaxLine matches the line yaml reports syntax errors at.
+var syntaxLine = regexp.MustCompile(`^yaml: line (\d+): `)
+
+// typeLine matches the line yaml.v2 reports a value of the wrong type at.
+var typeLine = regexp.MustCompile(`^line (\d+): `)
+
+// decodeConfig decodes a conf.yaml into conf with yaml.v2, like every reader
+// of conf.yaml files. Only when that fails is the yaml.v3 node tree used to
+// locate the value that cannot be decoded; syntax errors only report the
+// line yaml.v2 found them at.
+func decodeConfig(data []byte, conf *ConfigFile) error {
+	err := yamlv2.Unmarshal(data, conf)
+	if err == nil {
+		return nil
+	}
+	if m := syntaxLine.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return &ConfigError{Line: line, Err: errors.New(strings.TrimPrefix(err.Error(), m[0]))}
+	}
+	var doc yaml.Node
+	if yaml.Unmarshal(data, &doc) != nil || len(doc.Content) == 0 {
+		return &ConfigError{Err: err}
+	}
+	return locate(doc.Content[0], err)
+}
+
+// locate returns the position of the first value of root failing to decode.
+func locate(root *yaml.Node, err error) error {
+	if root.Kind != yaml.MappingNode {
+		return &ConfigError{Line: root.Line, Col: root.Column, Err: fmt.Errorf("expected a mapping of fields, got %s", root.ShortTag())}
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		pair := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{key, value}}
+		var typeErr *yaml.TypeError
+		if errors.As(pair.Decode(&ConfigFile{}), &typeErr) && len(typeErr.Errors) > 0 {
+			_, msg, _ := strings.Cut(typeErr.Errors[0], ": ")
+			return &ConfigError{Line: value.Line, Col: value.Column, Err: fmt.Errorf("%s: %s", key.Value, msg)}
+		}
+	}
+	// yaml.v3 decodes the value yaml.v2 rejected, keep the line of yaml.v2
+	var typeErr *yamlv2.TypeError
+	if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+		if m := typeLine.FindStringSubmatch(typeErr.Errors[0]); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			return &ConfigError{Line: line, Err: errors.New(strings.TrimPrefix(typeErr.Errors[0], m[0]))}
+		}
+	}
+	return &ConfigError{Err: err}
+}
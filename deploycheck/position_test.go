@@ -0,0 +1,58 @@
+package deploycheck_test
+
+import (
+	"testing"
+
+	"gitpkg/deploycheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		line, col int
+		message   string
+	}{
+		{"ParseConfig locates values of the wrong type", "namespace: hello\nversion:\n  - 1.0.0\n", 3, 3, "line 3, column 3: version: cannot unmarshal !!seq into string"},
+		{"ParseConfig locates fields of the wrong type", "version: 1.0.0\ndeploymentWindow: soon\n", 2, 19, "line 2, column 19: deploymentWindow: cannot unmarshal !!str `soon` into int"},
+		{"ParseConfig locates documents that are not mappings", "- version\n", 1, 1, "line 1, column 1: expected a mapping of fields, got !!seq"},
+		{"ParseConfig reports the line of syntax errors", "version: 1.0.0\nnamespace: [hello\n", 2, 0, "line 2: did not find expected ',' or ']'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := deploycheck.ParseConfig([]byte(tt.data))
+
+			// Assert
+			assert.EqualError(t, err, "failed to parse conf.yaml: "+tt.message)
+			assert.Equal(t, deploycheck.KindInvalidConfig, deploycheck.KindOf(err))
+			line, col := deploycheck.PositionOf(err)
+			assert.Equal(t, tt.line, line)
+			assert.Equal(t, tt.col, col)
+		})
+	}
+
+	t.Run("ParseConfig decodes valid files", func(t *testing.T) {
+		conf, err := deploycheck.ParseConfig([]byte("version: 1.0.0\nonboarded: true\ndeploymentWindow: 30\n"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, &deploycheck.ConfigFile{Version: "1.0.0", Onboarded: "true", DeploymentWindow: 30}, conf)
+	})
+
+	t.Run("ParseConfig accepts duplicate keys like yaml.v2 always did", func(t *testing.T) {
+		conf, err := deploycheck.ParseConfig([]byte("version: 1.0.0\nnamespace: hello\nversion: 1.1.0\n"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "1.1.0", conf.Version)
+	})
+
+	t.Run("ParseConfig locates the wrong value of a file with duplicate keys", func(t *testing.T) {
+		_, err := deploycheck.ParseConfig([]byte("version: 1.0.0\nversion: 1.1.0\ndeploymentWindow: soon\n"))
+
+		line, col := deploycheck.PositionOf(err)
+		assert.Equal(t, 3, line)
+		assert.Equal(t, 19, col)
+	})
+}
@@ -0,0 +1,66 @@
+package deploycheck
+
+import "fmt"
+
+// PRState is the state of the pull request a deploy check runs for, derived
+// from the action of the pull_request event that triggered the workflow.
+type PRState string
+
+const (
+	StateOpened         PRState = "opened"
+	StateSynchronize    PRState = "synchronize"
+	StateReopened       PRState = "reopened"
+	StateLabeled        PRState = "labeled"
+	StateClosedMerged   PRState = "closed-merged"
+	StateClosedUnmerged PRState = "closed-unmerged"
+)
+
+// PRStep is what a deploy check does for a pull request state.
+type PRStep int
+
+const (
+	// StepCompare compares the conf.yaml of the pull request head with the
+	// destination branch and reports whether a deployment is needed.
+	StepCompare PRStep = iota
+	// StepMerged reads the merged conf.yaml from the destination branch and
+	// compares it with the commit before the merge.
+	StepMerged
+	// StepSkip reports the component and environment of the pull request
+	// without a deployment.
+	StepSkip
+)
+
+// prSteps defines the step of every state.
+var prSteps = map[PRState]PRStep{
+	StateOpened:         StepCompare,
+	StateSynchronize:    StepCompare,
+	StateReopened:       StepCompare,
+	StateLabeled:        StepCompare,
+	StateClosedMerged:   StepMerged,
+	StateClosedUnmerged: StepSkip,
+}
+
+// ParsePRState returns the state of a pull request from the action of its
+// event and the merged flag of the pull request. It fails for actions the
+// deploy check does not handle, e.g. "edited" or "unlabeled", rather than
+// guessing a behaviour.
+func ParsePRState(action, merged string) (PRState, error) {
+	switch action {
+	case "opened", "synchronize", "reopened", "labeled":
+		return PRState(action), nil
+	case "closed":
+		if merged == "true" {
+			return StateClosedMerged, nil
+		}
+		return StateClosedUnmerged, nil
+	case "":
+		return "", fmt.Errorf("the pull request action is not set")
+	default:
+		return "", fmt.Errorf("unsupported pull request action %q: expected one of opened, synchronize, reopened, labeled or closed", action)
+	}
+}
+
+// Step returns the step of the deploy check for the state.
+func (s PRState) Step() PRStep {
+	return prSteps[s]
+}
@@ -0,0 +1,49 @@
+package deploycheck_test
+
+import (
+	"testing"
+
+	"gitpkg/deploycheck"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePRState(t *testing.T) {
+	t.Run("ParsePRState maps every supported action to its step", func(t *testing.T) {
+		for _, tc := range []struct {
+			action, merged string
+			state          deploycheck.PRState
+			step           deploycheck.PRStep
+		}{
+			{"opened", "false", deploycheck.StateOpened, deploycheck.StepCompare},
+			{"synchronize", "false", deploycheck.StateSynchronize, deploycheck.StepCompare},
+			{"reopened", "false", deploycheck.StateReopened, deploycheck.StepCompare},
+			{"labeled", "", deploycheck.StateLabeled, deploycheck.StepCompare},
+			{"closed", "true", deploycheck.StateClosedMerged, deploycheck.StepMerged},
+			{"closed", "false", deploycheck.StateClosedUnmerged, deploycheck.StepSkip},
+			{"closed", "", deploycheck.StateClosedUnmerged, deploycheck.StepSkip},
+		} {
+			// Act
+			state, err := deploycheck.ParsePRState(tc.action, tc.merged)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tc.state, state)
+			assert.Equal(t, tc.step, state.Step())
+		}
+	})
+
+	t.Run("ParsePRState rejects unknown actions", func(t *testing.T) {
+		for _, action := range []string{"edited", "unlabeled", "closed-merged", "Opened"} {
+			_, err := deploycheck.ParsePRState(action, "false")
+
+			assert.ErrorContains(t, err, `unsupported pull request action "`+action+`"`)
+		}
+	})
+
+	t.Run("ParsePRState rejects a missing action", func(t *testing.T) {
+		_, err := deploycheck.ParsePRState("", "")
+
+		assert.EqualError(t, err, "the pull request action is not set")
+	})
+}
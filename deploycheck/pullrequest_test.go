@@ -0,0 +1,159 @@
+package deploycheck_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gitpkg/deploycheck"
+	"gitpkg/ghevent"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// pullRequestOrigin is a repository standing in for GitHub: pull request 1
+// bumps the version and is merged into master, another component changes
+// after it, and pull request 2 bumps the version again and is still open.
+type pullRequestOrigin struct {
+	dir  string
+	repo *git.Repository
+	// merge is the merge commit of pull request 1, tip the tip of master and
+	// open the head of pull request 2.
+	merge string
+	tip   string
+	open  string
+}
+
+// newPullRequestOrigin creates the origin repository of the pull request checks.
+func newPullRequestOrigin(t *testing.T) pullRequestOrigin {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	assert.NoError(t, err)
+	base := commit(t, repo, dir, map[string]string{confFile: "version: 1.0.0\nnamespace: hello\nheoRevision: abc\nslackNotifyChannel: \"#hello\"\n"})
+	first := commit(t, repo, dir, map[string]string{confFile: "version: 1.1.0\nnamespace: hello\nheoRevision: abc\nslackNotifyChannel: \"#hello\"\n"})
+	setRef(t, repo, "refs/pull/1/head", first)
+	setRef(t, repo, "refs/heads/master", base)
+	wt, err := repo.Worktree()
+	assert.NoError(t, err)
+	merge, err := wt.Commit("merge pull request 1", &git.CommitOptions{
+		Author:  &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		Parents: []plumbing.Hash{plumbing.NewHash(base), plumbing.NewHash(first)},
+	})
+	assert.NoError(t, err)
+	tip := commit(t, repo, dir, map[string]string{"components/other/qcs-stage-us-east-1/conf.yaml": "version: 2.0.0\nnamespace: other\n"})
+	second := commit(t, repo, dir, map[string]string{confFile: "version: 1.2.0\nnamespace: hello\nheoRevision: abc\nslackNotifyChannel: \"#hello\"\n"})
+	setRef(t, repo, "refs/pull/2/head", second)
+	setRef(t, repo, "refs/heads/master", tip)
+	return pullRequestOrigin{dir: dir, repo: repo, merge: merge.String(), tip: tip, open: second}
+}
+
+// setRef points the ref name of repo at the commit hash.
+func setRef(t *testing.T, repo *git.Repository, name, hash string) {
+	t.Helper()
+	ref := plumbing.NewHashReference(plumbing.ReferenceName(name), plumbing.NewHash(hash))
+	assert.NoError(t, repo.Storer.SetReference(ref))
+}
+
+// runPullRequest runs a pull request deploy check in a fresh clone of origin
+// and returns the outputs it wrote.
+func runPullRequest(t *testing.T, origin pullRequestOrigin, opt deploycheck.DeployCheckerOption) (map[string]string, error) {
+	t.Helper()
+	opt.Path = filepath.Join(t.TempDir(), "clone")
+	opt.Url = origin.dir
+	opt.OutputFile = filepath.Join(t.TempDir(), "output")
+	if opt.DestinationBranch == "" {
+		opt.DestinationBranch = "master"
+	}
+	assert.NoError(t, os.WriteFile(opt.OutputFile, nil, 0644))
+	checker, err := deploycheck.NewDeployChecker(opt)
+	assert.NoError(t, err)
+	runErr := checker.Run()
+
+	data, err := os.ReadFile(opt.OutputFile)
+	assert.NoError(t, err)
+	outputs := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if key, value, ok := strings.Cut(line, "="); ok {
+			outputs[key] = value
+		}
+	}
+	return outputs, runErr
+}
+
+func TestDeployChecker_PullRequest(t *testing.T) {
+	// Arrange
+	origin := newPullRequestOrigin(t)
+
+	t.Run("Run does not announce checks of open pull requests", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "slack.json")
+
+		// Act
+		outputs, err := runPullRequest(t, origin, deploycheck.DeployCheckerOption{
+			PrNumber:        2,
+			Action:          "opened",
+			PrMerged:        "false",
+			SlackDryRunFile: file,
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "1.2.0", outputs["VERSION"])
+		assert.Equal(t, "true", outputs["DEPLOYMENT_NEEDED"])
+		assert.NoFileExists(t, file)
+	})
+
+	t.Run("Run announces merged pull requests, also after later commits on the branch", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "slack.json")
+
+		outputs, err := runPullRequest(t, origin, deploycheck.DeployCheckerOption{
+			PrNumber:        1,
+			Action:          "closed",
+			PrMerged:        "true",
+			MergeSHA:        origin.merge,
+			SlackDryRunFile: file,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "1.1.0", outputs["VERSION"])
+		assert.Equal(t, "true", outputs["DEPLOYMENT_NEEDED"])
+		data, err := os.ReadFile(file)
+		assert.NoError(t, err)
+		var msg struct {
+			Channel string `json:"channel"`
+			Text    string `json:"text"`
+		}
+		assert.NoError(t, json.Unmarshal(data, &msg))
+		assert.Equal(t, "#hello", msg.Channel)
+		assert.Equal(t, "hello 1.1.0 is deploying to qcs-stage-us-east-1", msg.Text)
+		assert.Contains(t, string(data), "1.0.0 → 1.1.0")
+		assert.Contains(t, string(data), "Deployment needed: *true*")
+	})
+
+	t.Run("Run compares the pull request named by a workflow_dispatch event", func(t *testing.T) {
+		// Arrange
+		e, err := ghevent.Load(ghevent.WorkflowDispatch, filepath.Join("..", "ghevent", "testdata", "workflow_dispatch.json"))
+		assert.NoError(t, err)
+		setRef(t, origin.repo, "refs/pull/42/head", origin.open)
+		setRef(t, origin.repo, "refs/heads/main", origin.tip)
+		file := filepath.Join(t.TempDir(), "slack.json")
+		opt := deploycheck.DeployCheckerOption{SlackDryRunFile: file}
+		opt.ApplyEvent(e)
+
+		// Act
+		outputs, err := runPullRequest(t, origin, opt)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "synchronize", outputs["PR_STATE"])
+		assert.Equal(t, "1.2.0", outputs["VERSION"])
+		assert.Equal(t, "true", outputs["DEPLOYMENT_NEEDED"])
+		assert.NoFileExists(t, file)
+	})
+}
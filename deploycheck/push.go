@@ -0,0 +1,88 @@
+package deploycheck
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gitpkg/ghevent"
+	"gitpkg/qgit"
+)
+
+// Modes of a deploy check.
+const (
+	// ModePullRequest checks the conf.yaml changed by a pull request.
+	ModePullRequest = "pull-request"
+	// ModePush checks the conf.yaml changed by a push, between the commits
+	// before and after it.
+	ModePush = "push"
+)
+
+// mode returns the configured mode, or the mode of the event the check runs for.
+func (opt DeployCheckerOption) mode() string {
+	if opt.Mode != "" {
+		return opt.Mode
+	}
+	if opt.EventName == ghevent.Push {
+		return ModePush
+	}
+	return ModePullRequest
+}
+
+// runPush checks the conf.yaml changed between the commits before and after a push.
+func (gr *DeployChecker) runPush() error {
+	before, after := gr.option.BaseSHA, gr.option.HeadSHA
+	if before == "" || after == "" {
+		return fmt.Errorf("the commits before and after the push are required in %s mode", ModePush)
+	}
+	if strings.Trim(before, "0") == "" {
+		return fmt.Errorf("the push creates branch %s, there is no previous commit to compare with", gr.destinationBranch())
+	}
+	gr.log().Info("push", "before", before, "after", after)
+
+	files, err := gr.gitClient.ChangedFiles(before, after)
+	if err != nil {
+		return fmt.Errorf("error getting changed files: %w", err)
+	}
+	file, err := confFile(files)
+	if err != nil {
+		return fmt.Errorf("error getting conf file %w", err)
+	}
+	gr.log().Info("conf file", "file", file)
+	if err := gr.setFile(file); err != nil {
+		return err
+	}
+
+	ref := gr.mergedDestinationRef()
+	if err := gr.checkInventory(ref); err != nil {
+		return fmt.Errorf("inventory check failed: %w", err)
+	}
+	if err := gr.checkBranchPolicy(ref); err != nil {
+		return fmt.Errorf("branch policy check failed: %w", err)
+	}
+	current, err := gr.getConfigDataFromCommit(file, after)
+	if err != nil {
+		return fmt.Errorf("error checking version and heoRevision: %w", err)
+	}
+	if current == nil {
+		return &Error{Kind: KindConfFiles, Err: fmt.Errorf("the push deletes %s", file)}
+	}
+	previous, err := gr.getConfigDataFromCommit(file, before)
+	if err != nil {
+		return fmt.Errorf("failed to get the previous conf file: %w", err)
+	}
+	return gr.compare(previous, current)
+}
+
+// getConfigDataFromCommit reads the conf.yaml at a commit. It returns nil
+// when the file does not exist there.
+func (gr *DeployChecker) getConfigDataFromCommit(file, commit string) (*ConfigFile, error) {
+	content, err := gr.gitClient.FileContentFromCommit(commit, file)
+	if errors.Is(err, qgit.ErrFileNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfig([]byte(content))
+}
@@ -0,0 +1,291 @@
+package deploycheck_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gitpkg/deploycheck"
+	"gitpkg/logging"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+const confFile = "components/hello/qcs-stage-us-east-1/conf.yaml"
+
+// commit writes the files into the work tree of repo and commits them.
+func commit(t *testing.T, repo *git.Repository, dir string, files map[string]string) string {
+	t.Helper()
+	wt, err := repo.Worktree()
+	assert.NoError(t, err)
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		assert.NoError(t, os.WriteFile(file, []byte(content), 0644))
+		_, err := wt.Add(name)
+		assert.NoError(t, err)
+	}
+	hash, err := wt.Commit("update", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	assert.NoError(t, err)
+	return hash.String()
+}
+
+// runPush runs a push mode deploy check on the fixture repository in dir and
+// returns the outputs it wrote.
+func runPush(t *testing.T, dir, before, after string) (map[string]string, error) {
+	t.Helper()
+	return runPushWith(t, deploycheck.DeployCheckerOption{Path: dir, BaseSHA: before, HeadSHA: after})
+}
+
+// runPushWith runs a push mode deploy check of master with opt and returns
+// the outputs it wrote.
+func runPushWith(t *testing.T, opt deploycheck.DeployCheckerOption) (map[string]string, error) {
+	t.Helper()
+	opt.Mode = deploycheck.ModePush
+	opt.DestinationBranch = "master"
+	opt.OutputFile = filepath.Join(t.TempDir(), "output")
+	assert.NoError(t, os.WriteFile(opt.OutputFile, nil, 0644))
+	checker, err := deploycheck.NewDeployChecker(opt)
+	assert.NoError(t, err)
+	runErr := checker.Run()
+
+	data, err := os.ReadFile(opt.OutputFile)
+	assert.NoError(t, err)
+	outputs := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if key, value, ok := strings.Cut(line, "="); ok {
+			outputs[key] = value
+		}
+	}
+	return outputs, runErr
+}
+
+func TestDeployChecker_Push(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	assert.NoError(t, err)
+	initial := commit(t, repo, dir, map[string]string{confFile: "version: 1.0.0\nnamespace: hello\nheoRevision: abc\n"})
+	bump := commit(t, repo, dir, map[string]string{confFile: "version: 1.1.0-rc.1\nnamespace: hello\nheoRevision: abc\n"})
+	slack := commit(t, repo, dir, map[string]string{confFile: "version: 1.1.0-rc.1\nnamespace: hello\nheoRevision: abc\nslackNotifyChannel: \"#hello\"\n"})
+	paused := commit(t, repo, dir, map[string]string{confFile: "version: 1.2.0\nnamespace: hello\nheoRevision: abc\nonboarded: \"false\"\n"})
+	twoFiles := commit(t, repo, dir, map[string]string{
+		confFile: "version: 1.3.0\nnamespace: hello\n",
+		"components/hello/qcs-prod-eu-west-1/conf.yaml": "version: 1.3.0\nnamespace: hello\n",
+	})
+	broken := commit(t, repo, dir, map[string]string{confFile: "version: [1.4.0\n"})
+
+	t.Run("Run in push mode reports a version change between the commits", func(t *testing.T) {
+		// Act
+		outputs, err := runPush(t, dir, initial, bump)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", outputs["COMPONENT"])
+		assert.Equal(t, "qcs-stage-us-east-1", outputs["ENVIRONMENT"])
+		assert.Equal(t, "1.1.0-rc.1", outputs["VERSION"])
+		assert.Equal(t, "false", outputs["IS_RELEASE"])
+		assert.Equal(t, "abc", outputs["HEO_REVISION"])
+		assert.Equal(t, "true", outputs["DEPLOYMENT_NEEDED"])
+	})
+
+	t.Run("Run in push mode reports no deployment for changes of other fields", func(t *testing.T) {
+		outputs, err := runPush(t, dir, bump, slack)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "false", outputs["DEPLOYMENT_NEEDED"])
+	})
+
+	t.Run("Run in push mode reports a conf file added by the push", func(t *testing.T) {
+		root := commitRoot(t, dir)
+
+		outputs, err := runPush(t, dir, root, initial)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "1.0.0", outputs["VERSION"])
+		assert.Equal(t, "true", outputs["DEPLOYMENT_NEEDED"])
+	})
+
+	t.Run("Run in push mode suppresses environments that are not onboarded", func(t *testing.T) {
+		outputs, err := runPush(t, dir, slack, paused)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "false", outputs["DEPLOYMENT_NEEDED"])
+		assert.Contains(t, outputs["DEPLOYMENT_SUPPRESSED_REASON"], "environment qcs-stage-us-east-1 is not onboarded")
+	})
+
+	t.Run("Run in push mode rejects pushes changing more than one file", func(t *testing.T) {
+		outputs, err := runPush(t, dir, paused, twoFiles)
+
+		assert.ErrorContains(t, err, "more than one file was changed")
+		assert.Equal(t, deploycheck.KindConfFiles, deploycheck.KindOf(err))
+		assert.Equal(t, "conf-files", outputs["ERROR_KIND"])
+	})
+
+	t.Run("Run in push mode reports an unparsable conf.yaml as invalid config", func(t *testing.T) {
+		outputs, err := runPush(t, dir, twoFiles, broken)
+
+		assert.ErrorContains(t, err, "failed to parse conf.yaml")
+		assert.Equal(t, "invalid-config", outputs["ERROR_KIND"])
+	})
+
+	t.Run("Run annotates an unparsable conf.yaml at its position", func(t *testing.T) {
+		// Arrange
+		var buf bytes.Buffer
+		checker, err := deploycheck.NewDeployChecker(deploycheck.DeployCheckerOption{
+			Mode:              deploycheck.ModePush,
+			Path:              dir,
+			DestinationBranch: "master",
+			BaseSHA:           twoFiles,
+			HeadSHA:           broken,
+			Annotator:         logging.NewGitHubAnnotator(&buf),
+		})
+		assert.NoError(t, err)
+
+		// Act
+		err = checker.Run()
+
+		// Assert
+		assert.Error(t, err)
+		assert.Equal(t, "::error title=deploy-check,file="+confFile+",line=1::hello in qcs-stage-us-east-1: error checking version and heoRevision: failed to parse conf.yaml: line 1: did not find expected ',' or ']'\n", buf.String())
+	})
+
+	t.Run("Run in push mode announces the decision on the channel", func(t *testing.T) {
+		// Arrange
+		var channels, payloads []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			channels = append(channels, r.URL.Path)
+			payloads = append(payloads, string(body))
+		}))
+		defer server.Close()
+		checker, err := deploycheck.NewDeployChecker(deploycheck.DeployCheckerOption{
+			Mode:              deploycheck.ModePush,
+			Path:              dir,
+			DestinationBranch: "master",
+			BaseSHA:           bump,
+			HeadSHA:           slack,
+			SlackWebhookURL:   server.URL + "/default",
+			SlackWebhooks:     map[string]string{"hello": server.URL + "/hello"},
+		})
+		assert.NoError(t, err)
+
+		// Act
+		err = checker.Run()
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"/hello"}, channels)
+		var msg struct {
+			Channel string `json:"channel"`
+			Text    string `json:"text"`
+		}
+		assert.NoError(t, json.Unmarshal([]byte(payloads[0]), &msg))
+		assert.Equal(t, "#hello", msg.Channel)
+		assert.Equal(t, "No deployment of hello to qcs-stage-us-east-1 is needed", msg.Text)
+		assert.Contains(t, payloads[0], "Deployment needed: *false*")
+	})
+
+	t.Run("Run in push mode does not fail when slack cannot be notified", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+		var buf bytes.Buffer
+		checker, err := deploycheck.NewDeployChecker(deploycheck.DeployCheckerOption{
+			Mode:              deploycheck.ModePush,
+			Path:              dir,
+			DestinationBranch: "master",
+			BaseSHA:           bump,
+			HeadSHA:           slack,
+			SlackWebhookURL:   server.URL,
+			Logger:            slog.New(slog.NewTextHandler(&buf, nil)),
+		})
+		assert.NoError(t, err)
+
+		err = checker.Run()
+
+		assert.NoError(t, err)
+		assert.Contains(t, buf.String(), `level=WARN msg="failed to notify slack" channel=#hello`)
+	})
+
+	t.Run("Run in push mode reports unknown commits as missing refs", func(t *testing.T) {
+		outputs, err := runPush(t, dir, strings.Repeat("1", 40), initial)
+
+		assert.Equal(t, deploycheck.KindRefNotFound, deploycheck.KindOf(err))
+		assert.Equal(t, "ref-not-found", outputs["ERROR_KIND"])
+	})
+
+	t.Run("Run in push mode rejects pushes creating the branch", func(t *testing.T) {
+		_, err := runPush(t, dir, strings.Repeat("0", 40), initial)
+
+		assert.EqualError(t, err, "the push creates branch master, there is no previous commit to compare with")
+	})
+}
+
+func TestDeployChecker_PushPolicies(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	assert.NoError(t, err)
+	base := commit(t, repo, dir, map[string]string{
+		"environments.yaml":    "pipeline-environments:\n  - qcs-stage-us-east-1\n",
+		"branch-policies.yaml": "branches:\n  - branch: master\n    tiers: [prod]\n",
+	})
+	stage := commit(t, repo, dir, map[string]string{confFile: "version: 1.0.0\nnamespace: hello\n"})
+	prod := commit(t, repo, dir, map[string]string{"components/hello/qcs-prod-eu-west-1/conf.yaml": "version: 1.0.0\nnamespace: hello\n"})
+
+	t.Run("Run in push mode reports environments missing from the inventory as invalid config", func(t *testing.T) {
+		// Act
+		outputs, err := runPushWith(t, deploycheck.DeployCheckerOption{Path: dir, BaseSHA: stage, HeadSHA: prod, InventoryFile: "environments.yaml"})
+
+		// Assert
+		assert.ErrorContains(t, err, `environment "qcs-prod-eu-west-1" is not declared in environments.yaml`)
+		assert.Equal(t, deploycheck.KindInvalidConfig, deploycheck.KindOf(err))
+		assert.Equal(t, "invalid-config", outputs["ERROR_KIND"])
+	})
+
+	t.Run("Run in push mode reports environments the branch policy rejects as invalid config", func(t *testing.T) {
+		outputs, err := runPushWith(t, deploycheck.DeployCheckerOption{Path: dir, BaseSHA: base, HeadSHA: stage, InventoryFile: "environments.yaml", BranchPolicyFile: "branch-policies.yaml"})
+
+		assert.ErrorContains(t, err, "environment qcs-stage-us-east-1 may not be deployed from branch master")
+		assert.Equal(t, deploycheck.KindInvalidConfig, deploycheck.KindOf(err))
+		assert.Equal(t, "invalid-config", outputs["ERROR_KIND"])
+	})
+}
+
+// commitRoot stores a commit with an empty tree in the repository at dir and
+// returns its hash.
+func commitRoot(t *testing.T, dir string) string {
+	t.Helper()
+	repo, err := git.PlainOpen(dir)
+	assert.NoError(t, err)
+	tree := &object.Tree{}
+	obj := repo.Storer.NewEncodedObject()
+	assert.NoError(t, tree.Encode(obj))
+	treeHash, err := repo.Storer.SetEncodedObject(obj)
+	assert.NoError(t, err)
+	empty := &object.Commit{
+		Author:    object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		Committer: object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		Message:   "empty",
+		TreeHash:  treeHash,
+	}
+	obj = repo.Storer.NewEncodedObject()
+	assert.NoError(t, empty.Encode(obj))
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	assert.NoError(t, err)
+	return hash.String()
+}
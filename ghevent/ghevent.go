@@ -0,0 +1,172 @@
+// Package ghevent reads the payload of the GitHub Actions event that triggered
+// a workflow run.
+package ghevent
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Supported event names.
+const (
+	PullRequest      = "pull_request"
+	Push             = "push"
+	WorkflowDispatch = "workflow_dispatch"
+)
+
+// Event holds the fields of an event payload the tools act on.
+type Event struct {
+	// Name is the event name, e.g. pull_request.
+	Name string
+	// Action is the activity type of pull_request events, e.g. opened.
+	Action   string
+	PRNumber int
+	Merged   bool
+	Labels   []string
+	// BaseRef and HeadRef are branch names. For push and workflow_dispatch
+	// events both are the branch the event ran on.
+	BaseRef string
+	HeadRef string
+	// BaseSHA and HeadSHA are the base and head commits of a pull request,
+	// or the commits before and after a push.
+	BaseSHA string
+	HeadSHA string
+	// MergeSHA is the merge commit of a merged pull request.
+	MergeSHA string
+	// RepoURL is the clone URL of the repository.
+	RepoURL string
+	// Inputs are the inputs of a workflow_dispatch event.
+	Inputs map[string]string
+}
+
+// payload is the subset of the webhook payloads decoded by Parse.
+type payload struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest *struct {
+		Number         int    `json:"number"`
+		Merged         bool   `json:"merged"`
+		MergeCommitSHA string `json:"merge_commit_sha"`
+		Labels         []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+		Base struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+		} `json:"base"`
+		Head struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+		} `json:"head"`
+	} `json:"pull_request"`
+	Ref        string                 `json:"ref"`
+	Before     string                 `json:"before"`
+	After      string                 `json:"after"`
+	Inputs     map[string]interface{} `json:"inputs"`
+	Repository struct {
+		CloneURL string `json:"clone_url"`
+	} `json:"repository"`
+}
+
+// Parse decodes the payload of the named event. The name is inferred from
+// the payload when empty; pull_request_target is handled as pull_request.
+func Parse(name string, data []byte) (*Event, error) {
+	var p payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse event payload: %w", err)
+	}
+	if name == "" {
+		name = infer(p)
+	}
+	if name == "pull_request_target" {
+		name = PullRequest
+	}
+	e := &Event{Name: name, Action: p.Action, RepoURL: p.Repository.CloneURL}
+	switch name {
+	case PullRequest:
+		if p.PullRequest == nil {
+			return nil, fmt.Errorf("pull_request event without a pull_request")
+		}
+		pr := p.PullRequest
+		e.PRNumber = pr.Number
+		if e.PRNumber == 0 {
+			e.PRNumber = p.Number
+		}
+		e.Merged = pr.Merged
+		if pr.Merged {
+			e.MergeSHA = pr.MergeCommitSHA
+		}
+		for _, label := range pr.Labels {
+			e.Labels = append(e.Labels, label.Name)
+		}
+		e.BaseRef, e.BaseSHA = pr.Base.Ref, pr.Base.SHA
+		e.HeadRef, e.HeadSHA = pr.Head.Ref, pr.Head.SHA
+	case Push:
+		e.BaseRef = branch(p.Ref)
+		e.HeadRef = e.BaseRef
+		e.BaseSHA, e.HeadSHA = p.Before, p.After
+	case WorkflowDispatch:
+		e.BaseRef = branch(p.Ref)
+		e.HeadRef = e.BaseRef
+		e.Inputs = map[string]string{}
+		for key, value := range p.Inputs {
+			e.Inputs[key] = fmt.Sprint(value)
+		}
+		// a dispatch may name the pull request to check
+		for _, key := range []string{"pr-number", "pr_number"} {
+			if n, err := strconv.Atoi(strings.TrimSpace(e.Inputs[key])); err == nil {
+				e.PRNumber = n
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported event %q: expected one of %s, %s or %s", name, PullRequest, Push, WorkflowDispatch)
+	}
+	return e, nil
+}
+
+// Load reads the payload of the named event from file.
+func Load(name, file string) (*Event, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event payload: %w", err)
+	}
+	return Parse(name, data)
+}
+
+// FromEnv loads the event of the running workflow from GITHUB_EVENT_NAME and
+// GITHUB_EVENT_PATH. It returns nil outside GitHub Actions. The merge commit
+// of a merged pull request missing from the payload is read from GITHUB_SHA.
+func FromEnv() (*Event, error) {
+	file := os.Getenv("GITHUB_EVENT_PATH")
+	if file == "" {
+		return nil, nil
+	}
+	e, err := Load(os.Getenv("GITHUB_EVENT_NAME"), file)
+	if err != nil {
+		return nil, err
+	}
+	if e.Merged && e.MergeSHA == "" {
+		e.MergeSHA = os.Getenv("GITHUB_SHA")
+	}
+	return e, nil
+}
+
+func infer(p payload) string {
+	switch {
+	case p.PullRequest != nil:
+		return PullRequest
+	case p.Inputs != nil:
+		return WorkflowDispatch
+	case p.Before != "" || p.After != "":
+		return Push
+	}
+	return ""
+}
+
+// branch returns the branch name of a full reference, e.g. main for refs/heads/main.
+func branch(ref string) string {
+	return strings.TrimPrefix(ref, "refs/heads/")
+}
@@ -0,0 +1,91 @@
+package ghevent_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitpkg/ghevent"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("Load reads a merged pull request", func(t *testing.T) {
+		// Act
+		e, err := ghevent.Load(ghevent.PullRequest, filepath.Join("testdata", "pull_request_closed.json"))
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, &ghevent.Event{
+			Name:     ghevent.PullRequest,
+			Action:   "closed",
+			PRNumber: 42,
+			Merged:   true,
+			Labels:   []string{"deploy", "stage"},
+			BaseRef:  "main",
+			HeadRef:  "hello-1.2.0",
+			BaseSHA:  "2222222222222222222222222222222222222222",
+			HeadSHA:  "1111111111111111111111111111111111111111",
+			MergeSHA: "9f3c1b2a4d5e6f708192a3b4c5d6e7f809a1b2c3",
+			RepoURL:  "https://github.com/qlik-trial/gitops-environments.git",
+		}, e)
+	})
+
+	t.Run("Load reads a push", func(t *testing.T) {
+		e, err := ghevent.Load(ghevent.Push, filepath.Join("testdata", "push.json"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "main", e.BaseRef)
+		assert.Equal(t, "main", e.HeadRef)
+		assert.Equal(t, "5555555555555555555555555555555555555555", e.BaseSHA)
+		assert.Equal(t, "6666666666666666666666666666666666666666", e.HeadSHA)
+		assert.Zero(t, e.PRNumber)
+	})
+
+	t.Run("Load reads the pull request number of a workflow dispatch", func(t *testing.T) {
+		e, err := ghevent.Load(ghevent.WorkflowDispatch, filepath.Join("testdata", "workflow_dispatch.json"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 42, e.PRNumber)
+		assert.Equal(t, "main", e.BaseRef)
+		assert.Equal(t, map[string]string{"pr-number": "42", "dry-run": "true"}, e.Inputs)
+	})
+
+	t.Run("Load infers the event name from the payload", func(t *testing.T) {
+		for file, name := range map[string]string{
+			"pull_request_opened.json": ghevent.PullRequest,
+			"push.json":                ghevent.Push,
+			"workflow_dispatch.json":   ghevent.WorkflowDispatch,
+		} {
+			e, err := ghevent.Load("", filepath.Join("testdata", file))
+
+			assert.NoError(t, err)
+			assert.Equal(t, name, e.Name, file)
+		}
+	})
+
+	t.Run("Load rejects unsupported events", func(t *testing.T) {
+		_, err := ghevent.Load("issue_comment", filepath.Join("testdata", "push.json"))
+
+		assert.ErrorContains(t, err, `unsupported event "issue_comment"`)
+	})
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Run("FromEnv reads the merge commit missing from the payload from GITHUB_SHA", func(t *testing.T) {
+		// Arrange
+		file := filepath.Join(t.TempDir(), "event.json")
+		assert.NoError(t, os.WriteFile(file, []byte(`{"action":"closed","pull_request":{"number":1,"merged":true}}`), 0644))
+		t.Setenv("GITHUB_EVENT_PATH", file)
+		t.Setenv("GITHUB_EVENT_NAME", ghevent.PullRequest)
+		t.Setenv("GITHUB_SHA", "9f3c1b2a4d5e6f708192a3b4c5d6e7f809a1b2c3")
+
+		// Act
+		e, err := ghevent.FromEnv()
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "9f3c1b2a4d5e6f708192a3b4c5d6e7f809a1b2c3", e.MergeSHA)
+	})
+}
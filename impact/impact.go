@@ -0,0 +1,250 @@
+package impact
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gitpkg/inventory"
+	"gitpkg/render"
+	"gitpkg/validate"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Options configures an impact analysis between two trees.
+type Options struct {
+	// Base and Head are the repository trees at the base and the PR ref, e.g. from qgit.Client.FS.
+	Base fs.FS
+	Head fs.FS
+	// Components to analyze. When empty, the components with changed files under qcs/ are used.
+	Components []string
+	Inventory  *inventory.Inventory
+	// EnvironmentsRoot is the gitops-environments checkout holding the conf.yaml files.
+	// When set, environments the component is not deployed to are left out.
+	EnvironmentsRoot string
+	// RegistryURL is rendered as CONTAINER_REGISTRY_URL.
+	RegistryURL string
+	// RenderOptions are applied to every values render, e.g. the vault datasources.
+	RenderOptions []render.Option
+	// Secrets tracks the values read from datasources, which are redacted from
+	// the reasons. A new set is used when nil.
+	Secrets *render.Secrets
+}
+
+// Entry is a component/environment pair of the analysis.
+type Entry struct {
+	Component   string `json:"component"`
+	Environment string `json:"environment"`
+	Tier        string `json:"tier"`
+	Region      string `json:"region"`
+	Affected    bool   `json:"-"`
+	// Reason explains why the pair is affected.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Result lists every analyzed pair.
+type Result struct {
+	Entries []Entry
+}
+
+// Affected returns the pairs whose rendered values differ.
+func (r *Result) Affected() []Entry {
+	var affected []Entry
+	for _, e := range r.Entries {
+		if e.Affected {
+			affected = append(affected, e)
+		}
+	}
+	return affected
+}
+
+// Matrix returns the affected pairs as a GitHub Actions matrix, {"include":[...]}.
+func (r *Result) Matrix() (string, error) {
+	include := r.Affected()
+	if include == nil {
+		include = []Entry{}
+	}
+	data, err := json.Marshal(map[string][]Entry{"include": include})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Analyze renders the values of every component at the base and the head for
+// every inventory environment and marks the pairs whose output differs.
+func Analyze(opts Options) (*Result, error) {
+	if opts.Inventory == nil {
+		return nil, fmt.Errorf("an inventory is required")
+	}
+	if opts.RegistryURL == "" {
+		opts.RegistryURL = "registry.com"
+	}
+	if opts.Secrets == nil {
+		opts.Secrets = render.NewSecrets()
+	}
+	opts.RenderOptions = append(append([]render.Option{}, opts.RenderOptions...), render.WithSecrets(opts.Secrets))
+	components := opts.Components
+	if len(components) == 0 {
+		var err error
+		if components, err = ChangedComponents(opts.Base, opts.Head); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &Result{}
+	for _, component := range components {
+		file := path.Join("qcs", component, "values.yaml")
+		head, err := readValues(opts.Head, file)
+		if err != nil {
+			return nil, err
+		}
+		if head == "" {
+			// Removed components have nothing left to validate or deploy.
+			continue
+		}
+		base, err := readValues(opts.Base, file)
+		if err != nil {
+			return nil, err
+		}
+		for _, env := range opts.Inventory.List() {
+			if !env.Known() {
+				continue
+			}
+			if opts.EnvironmentsRoot != "" {
+				conf := filepath.Join(opts.EnvironmentsRoot, inventory.ConfPath(component, env.Name))
+				if _, err := os.Stat(conf); os.IsNotExist(err) {
+					continue
+				}
+			}
+			entry := Entry{Component: component, Environment: env.Name, Tier: env.Tier, Region: env.Region}
+			entry.Affected, entry.Reason = opts.compare(component, base, head, env)
+			result.Entries = append(result.Entries, entry)
+		}
+	}
+	for i := range result.Entries {
+		result.Entries[i].Reason = opts.Secrets.Redact(result.Entries[i].Reason)
+	}
+	return result, nil
+}
+
+// compare reports whether the rendered values of a component differ between
+// the base and the head. Render failures count as affected so they get validated.
+func (opts Options) compare(component, base, head string, env inventory.Environment) (bool, string) {
+	if base == "" {
+		return true, "new component"
+	}
+	headValues, err := validate.RenderValues(opts.Head, component, head, env, opts.RegistryURL, opts.RenderOptions...)
+	if err != nil {
+		return true, fmt.Sprintf("head does not render: %v", err)
+	}
+	baseValues, err := validate.RenderValues(opts.Base, component, base, env, opts.RegistryURL, opts.RenderOptions...)
+	if err != nil {
+		return true, fmt.Sprintf("base does not render: %v", err)
+	}
+	if headValues == baseValues {
+		return false, ""
+	}
+	var b, h interface{}
+	if yaml.Unmarshal([]byte(baseValues), &b) == nil && yaml.Unmarshal([]byte(headValues), &h) == nil && reflect.DeepEqual(b, h) {
+		return false, ""
+	}
+	return true, "rendered values differ"
+}
+
+// ChangedComponents returns the components with a file under qcs/<component>/
+// that differs between the two trees, including added and removed files.
+func ChangedComponents(base, head fs.FS) ([]string, error) {
+	baseFiles, err := componentFiles(base)
+	if err != nil {
+		return nil, err
+	}
+	headFiles, err := componentFiles(head)
+	if err != nil {
+		return nil, err
+	}
+	changed := map[string]bool{}
+	for file, component := range headFiles {
+		if _, ok := baseFiles[file]; !ok {
+			changed[component] = true
+		}
+	}
+	for file, component := range baseFiles {
+		if _, ok := headFiles[file]; !ok {
+			changed[component] = true
+			continue
+		}
+		if changed[component] {
+			continue
+		}
+		same, err := sameContent(base, head, file)
+		if err != nil {
+			return nil, err
+		}
+		if !same {
+			changed[component] = true
+		}
+	}
+	components := make([]string, 0, len(changed))
+	for c := range changed {
+		components = append(components, c)
+	}
+	sort.Strings(components)
+	return components, nil
+}
+
+// componentFiles maps every file under qcs/<component>/ to its component.
+func componentFiles(root fs.FS) (map[string]string, error) {
+	files := map[string]string{}
+	err := fs.WalkDir(root, "qcs", func(name string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && name == "qcs" {
+			return fs.SkipAll
+		}
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if parts := strings.Split(name, "/"); len(parts) > 2 {
+			files[name] = parts[1]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list qcs: %w", err)
+	}
+	return files, nil
+}
+
+func sameContent(base, head fs.FS, file string) (bool, error) {
+	b, err := fs.ReadFile(base, file)
+	if err != nil {
+		return false, err
+	}
+	h, err := fs.ReadFile(head, file)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(b, h), nil
+}
+
+func readValues(root fs.FS, file string) (string, error) {
+	data, err := fs.ReadFile(root, file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return string(data), nil
+}
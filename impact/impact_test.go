@@ -0,0 +1,129 @@
+package impact_test
+
+import (
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"gitpkg/impact"
+	"gitpkg/inventory"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const values = `{{ $environment := getenv "ENVIRONMENT" -}}
+{{ $region := getenv "REGION" -}}
+replicas: 2
+{{- if eq $environment "qcs-int" }}
+debug: %s
+{{- end }}
+region: {{ $region }}
+`
+
+func testInventory(t *testing.T) *inventory.Inventory {
+	t.Helper()
+	inv, err := inventory.Parse([]byte(`pipeline-environments:
+  - qlik-cloud-services-int-env
+  - qcs-stage-us-east-1
+  - qcs-prod-eu-west-1
+`))
+	assert.NoError(t, err)
+	return inv
+}
+
+func affected(result *impact.Result) []string {
+	var names []string
+	for _, e := range result.Affected() {
+		names = append(names, e.Component+"/"+e.Environment)
+	}
+	return names
+}
+
+func TestAnalyze(t *testing.T) {
+	t.Run("Analyze only reports environments whose rendered values change", func(t *testing.T) {
+		// Arrange
+		base := fstest.MapFS{
+			"qcs/c/values.yaml":     {Data: []byte(fmt.Sprintf(values, "false"))},
+			"qcs/other/values.yaml": {Data: []byte("replicas: 1\n")},
+		}
+		head := fstest.MapFS{
+			"qcs/c/values.yaml":     {Data: []byte(fmt.Sprintf(values, "true"))},
+			"qcs/other/values.yaml": {Data: []byte("replicas: 1\n")},
+		}
+
+		// Act
+		result, err := impact.Analyze(impact.Options{Base: base, Head: head, Inventory: testInventory(t)})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Len(t, result.Entries, 3)
+		assert.Equal(t, []string{"c/qlik-cloud-services-int-env"}, affected(result))
+		matrix, err := result.Matrix()
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"include":[{"component":"c","environment":"qlik-cloud-services-int-env","tier":"qcs-int","region":"eu-central-1","reason":"rendered values differ"}]}`, matrix)
+	})
+
+	t.Run("Analyze ignores formatting only changes", func(t *testing.T) {
+		base := fstest.MapFS{"qcs/c/values.yaml": {Data: []byte("a: 1\nb: 2\n")}}
+		head := fstest.MapFS{"qcs/c/values.yaml": {Data: []byte("# comment\nb: 2\na:   1\n")}}
+
+		result, err := impact.Analyze(impact.Options{Base: base, Head: head, Inventory: testInventory(t)})
+
+		assert.NoError(t, err)
+		assert.Len(t, result.Entries, 3)
+		assert.Empty(t, result.Affected())
+		matrix, err := result.Matrix()
+		assert.NoError(t, err)
+		assert.Equal(t, `{"include":[]}`, matrix)
+	})
+
+	t.Run("Analyze marks sealed secrets of a region and new components", func(t *testing.T) {
+		secretsValues := `{{ $environment := getenv "ENVIRONMENT" -}}
+{{ $region := getenv "REGION" -}}
+{{ file.Read (printf "c-values/qcs/c/sealed-secrets/%s/%s/sealed-secrets.yaml" $environment $region) }}
+`
+		base := fstest.MapFS{
+			"qcs/c/values.yaml": {Data: []byte(secretsValues)},
+			"qcs/c/sealed-secrets/qcs-int/eu-central-1/sealed-secrets.yaml": {Data: []byte("a: old\n")},
+			"qcs/c/sealed-secrets/stage/us-east-1/sealed-secrets.yaml":      {Data: []byte("a: same\n")},
+			"qcs/c/sealed-secrets/prod/eu-west-1/sealed-secrets.yaml":       {Data: []byte("a: same\n")},
+		}
+		head := fstest.MapFS{
+			"qcs/c/values.yaml": {Data: []byte(secretsValues)},
+			"qcs/c/sealed-secrets/qcs-int/eu-central-1/sealed-secrets.yaml": {Data: []byte("a: new\n")},
+			"qcs/c/sealed-secrets/stage/us-east-1/sealed-secrets.yaml":      {Data: []byte("a: same\n")},
+			"qcs/c/sealed-secrets/prod/eu-west-1/sealed-secrets.yaml":       {Data: []byte("a: same\n")},
+			"qcs/new/values.yaml": {Data: []byte("replicas: 1\n")},
+		}
+
+		result, err := impact.Analyze(impact.Options{Base: base, Head: head, Inventory: testInventory(t)})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"c/qlik-cloud-services-int-env",
+			"new/qlik-cloud-services-int-env",
+			"new/qcs-stage-us-east-1",
+			"new/qcs-prod-eu-west-1",
+		}, affected(result))
+	})
+}
+
+func TestChangedComponents(t *testing.T) {
+	base := fstest.MapFS{
+		"qcs/same/values.yaml":    {Data: []byte("a: 1\n")},
+		"qcs/changed/values.yaml": {Data: []byte("a: 1\n")},
+		"qcs/removed/values.yaml": {Data: []byte("a: 1\n")},
+		"README.md":               {Data: []byte("old")},
+	}
+	head := fstest.MapFS{
+		"qcs/same/values.yaml":    {Data: []byte("a: 1\n")},
+		"qcs/changed/values.yaml": {Data: []byte("a: 2\n")},
+		"qcs/added/values.yaml":   {Data: []byte("a: 1\n")},
+		"README.md":               {Data: []byte("new")},
+	}
+
+	components, err := impact.ChangedComponents(base, head)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"added", "changed", "removed"}, components)
+}
@@ -0,0 +1,74 @@
+package impact
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"gitpkg/render"
+	"gitpkg/revisions"
+	"gitpkg/utilities"
+)
+
+// Setup registers the flags of the impact command; the command prints the analysis and
+// writes the MATRIX and AFFECTED outputs to $GITHUB_OUTPUT when set.
+func Setup(fs *flag.FlagSet) func(args []string) error {
+	var change revisions.Options
+	change.BindFlags(fs)
+	components := fs.String("components", "", "Comma separated components to analyze, the changed components when empty")
+	return func(args []string) error {
+		trees, err := change.Load()
+		if err != nil {
+			return err
+		}
+		opts := Options{
+			Base:             trees.Base,
+			Head:             trees.Head,
+			Inventory:        trees.Inventory,
+			EnvironmentsRoot: trees.EnvironmentsRoot,
+			RegistryURL:      trees.RegistryURL,
+			RenderOptions:    trees.RenderOptions,
+			Secrets:          render.NewSecrets(),
+		}
+		if *components != "" {
+			opts.Components = strings.Split(*components, ",")
+		}
+
+		result, err := Analyze(opts)
+		if err != nil {
+			return err
+		}
+		opts.Secrets.WriteMasks(os.Stdout)
+		result.WriteSummary(os.Stdout)
+		matrix, err := result.Matrix()
+		if err != nil {
+			return err
+		}
+		if output := os.Getenv("GITHUB_OUTPUT"); output != "" {
+			w := utilities.NewFileOutputWriter(output)
+			if err := w.WriteOutput("MATRIX", matrix); err != nil {
+				return err
+			}
+			if err := w.WriteOutput("AFFECTED", strconv.Itoa(len(result.Affected()))); err != nil {
+				return err
+			}
+		}
+		fmt.Printf("MATRIX=%s\n", matrix)
+		return nil
+	}
+}
+
+// WriteSummary prints every analyzed pair and whether it is affected.
+func (r *Result) WriteSummary(w io.Writer) {
+	for _, e := range r.Entries {
+		if e.Affected {
+			fmt.Fprintf(w, "affected:   %s in %s: %s\n", e.Component, e.Environment, e.Reason)
+		} else {
+			fmt.Fprintf(w, "unaffected: %s in %s\n", e.Component, e.Environment)
+		}
+	}
+	fmt.Fprintf(w, "%d of %d component/environment pairs affected\n", len(r.Affected()), len(r.Entries))
+}
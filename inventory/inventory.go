@@ -0,0 +1,250 @@
+package inventory
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultFile is the location of the inventory inside the gitops-environments repository.
+const DefaultFile = "environments.yaml"
+
+// Tiers used by the qcs values templates for the ENVIRONMENT variable.
+const (
+	TierQcsInt = "qcs-int"
+	TierStage  = "stage"
+	TierProd   = "prod"
+)
+
+// DefaultProvider is the PROVIDER used when an environment does not declare one.
+const DefaultProvider = "aws"
+
+// Environment is a single pipeline environment, e.g. "qcs-prod-us-east-1".
+type Environment struct {
+	// Name is the pipeline environment name, which is also the directory
+	// name under components/<component>/ holding the conf.yaml.
+	Name string
+	// Tier is the value rendered as ENVIRONMENT (qcs-int, stage, prod, ...).
+	Tier string
+	// Region is the value rendered as REGION.
+	Region string
+	// Provider is the value rendered as PROVIDER.
+	Provider string
+}
+
+// UnmarshalYAML accepts either a plain environment name or a mapping with
+// explicit tier, region and provider.
+//
+// Plain names are resolved the same way the validation workflows do it:
+// "qlik-cloud-services-int-env" is qcs-int in eu-central-1, "*-prod-<region>"
+// and "*-stage-<region>" take the tier and everything after it as region.
+func (e *Environment) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		*e = FromName(name)
+		return nil
+	}
+
+	var entry struct {
+		Name     string `yaml:"name"`
+		Tier     string `yaml:"environment"`
+		Region   string `yaml:"region"`
+		Provider string `yaml:"provider"`
+	}
+	if err := unmarshal(&entry); err != nil {
+		return err
+	}
+	if entry.Name == "" {
+		return fmt.Errorf("environment entry is missing a name")
+	}
+	*e = FromName(entry.Name)
+	if entry.Tier != "" {
+		e.Tier = entry.Tier
+	}
+	if entry.Region != "" {
+		e.Region = entry.Region
+	}
+	if entry.Provider != "" {
+		e.Provider = entry.Provider
+	}
+	return nil
+}
+
+// FromName derives tier and region from a pipeline environment name.
+// Names that do not follow a known pattern are returned with an empty tier and region.
+func FromName(name string) Environment {
+	env := Environment{Name: name, Provider: DefaultProvider}
+	if name == "qlik-cloud-services-int-env" {
+		env.Tier = TierQcsInt
+		env.Region = "eu-central-1"
+		return env
+	}
+	for _, tier := range []string{TierProd, TierStage} {
+		_, region, found := strings.Cut(name, "-"+tier+"-")
+		if !found || region == "" {
+			continue
+		}
+		env.Tier = tier
+		env.Region = region
+		return env
+	}
+	return env
+}
+
+// Known reports whether the tier and region of the environment could be resolved.
+func (e Environment) Known() bool {
+	return e.Tier != "" && e.Region != ""
+}
+
+// Vars returns the variables used when rendering a qcs values template for this environment.
+func (e Environment) Vars() map[string]string {
+	return map[string]string{
+		"ENVIRONMENT": e.Tier,
+		"REGION":      e.Region,
+		"PROVIDER":    e.Provider,
+	}
+}
+
+// Inventory is the parsed content of gitops-environments/environments.yaml.
+type Inventory struct {
+	Environments []Environment `yaml:"pipeline-environments"`
+}
+
+// FileReader reads a file at a given git reference. *qgit.Client satisfies it.
+type FileReader interface {
+	FileContentFromBranch(ref, file string) (string, error)
+}
+
+// Parse decodes an inventory document.
+func Parse(data []byte) (*Inventory, error) {
+	inv := &Inventory{}
+	if err := yaml.Unmarshal(data, inv); err != nil {
+		return nil, fmt.Errorf("failed to parse inventory: %w", err)
+	}
+	seen := map[string]bool{}
+	for _, env := range inv.Environments {
+		if seen[env.Name] {
+			return nil, fmt.Errorf("duplicate environment %q in inventory", env.Name)
+		}
+		seen[env.Name] = true
+	}
+	return inv, nil
+}
+
+// Load reads the inventory from a local checkout.
+func Load(file string) (*Inventory, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory %s: %w", file, err)
+	}
+	return Parse(data)
+}
+
+// LoadFromRef reads the inventory file at the given git reference.
+//
+// Parameters:
+//   - reader: The git client used to read the file, typically a *qgit.Client.
+//   - ref: The full reference name, e.g. "refs/remotes/origin/main".
+//   - file: The path of the inventory inside the repository, usually DefaultFile.
+func LoadFromRef(reader FileReader, ref, file string) (*Inventory, error) {
+	content, err := reader.FileContentFromBranch(ref, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory %s at %s: %w", file, ref, err)
+	}
+	return Parse([]byte(content))
+}
+
+// List returns all environments in inventory order.
+func (inv *Inventory) List() []Environment {
+	return append([]Environment(nil), inv.Environments...)
+}
+
+// Names returns the names of all environments in inventory order.
+func (inv *Inventory) Names() []string {
+	names := make([]string, 0, len(inv.Environments))
+	for _, env := range inv.Environments {
+		names = append(names, env.Name)
+	}
+	return names
+}
+
+// Get looks up an environment by name.
+func (inv *Inventory) Get(name string) (Environment, bool) {
+	for _, env := range inv.Environments {
+		if env.Name == name {
+			return env, true
+		}
+	}
+	return Environment{}, false
+}
+
+// Has reports whether the named environment is part of the inventory.
+func (inv *Inventory) Has(name string) bool {
+	_, ok := inv.Get(name)
+	return ok
+}
+
+// Filter returns the environments matching the given predicate.
+func (inv *Inventory) Filter(match func(Environment) bool) []Environment {
+	var envs []Environment
+	for _, env := range inv.Environments {
+		if match(env) {
+			envs = append(envs, env)
+		}
+	}
+	return envs
+}
+
+// ByTier returns the environments of the given tier, e.g. "prod".
+func (inv *Inventory) ByTier(tier string) []Environment {
+	return inv.Filter(func(env Environment) bool { return env.Tier == tier })
+}
+
+// ByRegion returns the environments deployed to the given region.
+func (inv *Inventory) ByRegion(region string) []Environment {
+	return inv.Filter(func(env Environment) bool { return env.Region == region })
+}
+
+// Regions returns the sorted set of regions used by environments of the given tier.
+// An empty tier returns the regions of all environments.
+func (inv *Inventory) Regions(tier string) []string {
+	set := map[string]bool{}
+	for _, env := range inv.Environments {
+		if env.Region != "" && (tier == "" || env.Tier == tier) {
+			set[env.Region] = true
+		}
+	}
+	regions := make([]string, 0, len(set))
+	for region := range set {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// ConfPath returns the repository path of the conf.yaml of a component in an environment.
+func ConfPath(component, environment string) string {
+	return path.Join("components", component, environment, "conf.yaml")
+}
+
+// ConfPath returns the conf.yaml path of the component in the named environment.
+// It fails if the environment is not part of the inventory.
+func (inv *Inventory) ConfPath(component, environment string) (string, error) {
+	if !inv.Has(environment) {
+		return "", fmt.Errorf("environment %q is not in the inventory", environment)
+	}
+	return ConfPath(component, environment), nil
+}
+
+// ParseConfPath splits a "components/<component>/<environment>/conf.yaml" path.
+func ParseConfPath(file string) (component, environment string, err error) {
+	parts := strings.Split(path.Clean(file), "/")
+	if len(parts) != 4 || parts[0] != "components" || parts[3] != "conf.yaml" {
+		return "", "", fmt.Errorf("%s is not a components/<component>/<environment>/conf.yaml path", file)
+	}
+	return parts[1], parts[2], nil
+}
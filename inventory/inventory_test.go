@@ -0,0 +1,145 @@
+package inventory_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitpkg/inventory"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const environmentsYAML = `pipeline-environments:
+  - qlik-cloud-services-int-env
+  - qcs-stage-us-east-1
+  - qcs-stage-eu-west-1
+  - qcs-prod-us-east-1
+  - qcs-prod-ap-southeast-2
+  - name: qcs-fed-stage-us-gov-west-1
+    environment: fed-stage
+    provider: fedramp
+`
+
+type fakeReader map[string]string
+
+func (f fakeReader) FileContentFromBranch(ref, file string) (string, error) {
+	content, ok := f[ref+":"+file]
+	if !ok {
+		return "", errors.New("file not found")
+	}
+	return content, nil
+}
+
+func TestParse(t *testing.T) {
+	t.Run("Parse resolves tier and region from environment names", func(t *testing.T) {
+		// Act
+		inv, err := inventory.Parse([]byte(environmentsYAML))
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Len(t, inv.List(), 6)
+
+		env, ok := inv.Get("qlik-cloud-services-int-env")
+		assert.True(t, ok)
+		assert.Equal(t, inventory.Environment{Name: "qlik-cloud-services-int-env", Tier: "qcs-int", Region: "eu-central-1", Provider: "aws"}, env)
+
+		env, ok = inv.Get("qcs-prod-ap-southeast-2")
+		assert.True(t, ok)
+		assert.Equal(t, "prod", env.Tier)
+		assert.Equal(t, "ap-southeast-2", env.Region)
+	})
+
+	t.Run("Parse honours explicit entries", func(t *testing.T) {
+		// Act
+		inv, err := inventory.Parse([]byte(environmentsYAML))
+
+		// Assert
+		assert.NoError(t, err)
+		env, ok := inv.Get("qcs-fed-stage-us-gov-west-1")
+		assert.True(t, ok)
+		assert.Equal(t, "fed-stage", env.Tier)
+		assert.Equal(t, "us-gov-west-1", env.Region)
+		assert.Equal(t, "fedramp", env.Provider)
+	})
+
+	t.Run("Parse rejects duplicate environments", func(t *testing.T) {
+		// Act
+		_, err := inventory.Parse([]byte("pipeline-environments:\n  - a-prod-us-east-1\n  - a-prod-us-east-1\n"))
+
+		// Assert
+		assert.Error(t, err)
+	})
+}
+
+func TestInventory_Queries(t *testing.T) {
+	inv, err := inventory.Parse([]byte(environmentsYAML))
+	assert.NoError(t, err)
+
+	t.Run("ByTier returns environments of a tier", func(t *testing.T) {
+		names := []string{}
+		for _, env := range inv.ByTier("stage") {
+			names = append(names, env.Name)
+		}
+		assert.Equal(t, []string{"qcs-stage-us-east-1", "qcs-stage-eu-west-1"}, names)
+	})
+
+	t.Run("ByRegion returns environments of a region", func(t *testing.T) {
+		assert.Len(t, inv.ByRegion("us-east-1"), 2)
+		assert.Empty(t, inv.ByRegion("eu-north-1"))
+	})
+
+	t.Run("Regions returns the sorted regions of a tier", func(t *testing.T) {
+		assert.Equal(t, []string{"ap-southeast-2", "us-east-1"}, inv.Regions("prod"))
+	})
+
+	t.Run("ConfPath only resolves known environments", func(t *testing.T) {
+		file, err := inv.ConfPath("dataprep-proxy", "qcs-prod-us-east-1")
+		assert.NoError(t, err)
+		assert.Equal(t, "components/dataprep-proxy/qcs-prod-us-east-1/conf.yaml", file)
+
+		_, err = inv.ConfPath("dataprep-proxy", "qcs-prod-mars-north-1")
+		assert.Error(t, err)
+	})
+}
+
+func TestParseConfPath(t *testing.T) {
+	component, environment, err := inventory.ParseConfPath("components/dataprep-proxy/qcs-prod-us-east-1/conf.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "dataprep-proxy", component)
+	assert.Equal(t, "qcs-prod-us-east-1", environment)
+
+	_, _, err = inventory.ParseConfPath("qcs/dataprep-proxy/values.yaml")
+	assert.Error(t, err)
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("Load reads a local checkout", func(t *testing.T) {
+		// Arrange
+		file := filepath.Join(t.TempDir(), inventory.DefaultFile)
+		assert.NoError(t, os.WriteFile(file, []byte(environmentsYAML), 0600))
+
+		// Act
+		inv, err := inventory.Load(file)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.True(t, inv.Has("qcs-prod-us-east-1"))
+	})
+
+	t.Run("LoadFromRef reads the file through the git client", func(t *testing.T) {
+		// Arrange
+		reader := fakeReader{"refs/heads/main:" + inventory.DefaultFile: environmentsYAML}
+
+		// Act
+		inv, err := inventory.LoadFromRef(reader, "refs/heads/main", inventory.DefaultFile)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, 6, len(inv.Names()))
+
+		_, err = inventory.LoadFromRef(reader, "refs/heads/other", inventory.DefaultFile)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,119 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigFile is the lint configuration at the root of the values repository.
+const DefaultConfigFile = ".qcs-lint.yaml"
+
+// Config enables rules and overrides their severity, globally or per component:
+//
+//	disabled: [chart-header]
+//	severity:
+//	  pull-secrets: warning
+//	components:
+//	  bonjour-world:
+//	    disabled: [prod-replicas]
+type Config struct {
+	Disabled   []string                   `yaml:"disabled"`
+	Severity   map[string]Severity        `yaml:"severity"`
+	Components map[string]ComponentConfig `yaml:"components"`
+}
+
+// ComponentConfig overrides the global configuration for one component.
+type ComponentConfig struct {
+	Disabled []string            `yaml:"disabled"`
+	Severity map[string]Severity `yaml:"severity"`
+}
+
+// ParseConfig parses a lint configuration.
+func ParseConfig(data []byte) (*Config, error) {
+	config := &Config{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse lint config: %w", err)
+	}
+	return config, nil
+}
+
+// LoadConfig reads a lint configuration. A missing file enables every rule.
+func LoadConfig(file string) (*Config, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return ParseConfig(data)
+}
+
+// Enabled reports whether a rule runs for a component.
+func (c *Config) Enabled(component, rule string) bool {
+	if contains(c.Disabled, rule) {
+		return false
+	}
+	return !contains(c.Components[component].Disabled, rule)
+}
+
+// SeverityOf returns the configured severity of a rule for a component, or def.
+func (c *Config) SeverityOf(component, rule string, def Severity) Severity {
+	if s, ok := c.Components[component].Severity[rule]; ok {
+		return s
+	}
+	if s, ok := c.Severity[rule]; ok {
+		return s
+	}
+	return def
+}
+
+// check rejects unknown rule names and severities, which are most likely typos.
+func (c *Config) check(rules map[string]bool) error {
+	var errs []error
+	checkRules := func(scope string, disabled []string, severity map[string]Severity) {
+		for _, name := range disabled {
+			if !rules[name] {
+				errs = append(errs, fmt.Errorf("%s: unknown rule %s", scope, name))
+			}
+		}
+		names := make([]string, 0, len(severity))
+		for name := range severity {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if !rules[name] {
+				errs = append(errs, fmt.Errorf("%s: unknown rule %s", scope, name))
+			}
+			if s := severity[name]; s != SeverityError && s != SeverityWarning {
+				errs = append(errs, fmt.Errorf("%s: invalid severity %q for %s", scope, s, name))
+			}
+		}
+	}
+	checkRules("config", c.Disabled, c.Severity)
+	components := make([]string, 0, len(c.Components))
+	for component := range c.Components {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+	for _, component := range components {
+		cc := c.Components[component]
+		checkRules("component "+component, cc.Disabled, cc.Severity)
+	}
+	return errors.Join(errs...)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
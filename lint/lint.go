@@ -0,0 +1,308 @@
+package lint
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"gitpkg/inventory"
+	"gitpkg/render"
+	"gitpkg/sealedsecrets"
+	"gitpkg/validate"
+	"gitpkg/vaultmock"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity of a diagnostic.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a rule violation at a line of a values template.
+type Diagnostic struct {
+	Rule     string
+	Severity Severity
+	File     string
+	// Line is 1-based, 0 when the violation cannot be located in the template.
+	Line    int
+	Message string
+	// Environments lists the environments whose rendered output violates the
+	// rule; it is empty for violations of the template source.
+	Environments []string
+}
+
+func (d Diagnostic) String() string {
+	s := fmt.Sprintf("%s:%d: %s: [%s] %s", d.File, d.Line, d.Severity, d.Rule, d.Message)
+	if len(d.Environments) > 0 {
+		s += fmt.Sprintf(" (%s)", strings.Join(d.Environments, ", "))
+	}
+	return s
+}
+
+// Rule is a named lint rule. A rule checks the template source, the rendered
+// output or both by implementing SourceRule and/or RenderedRule.
+type Rule interface {
+	Name() string
+	Description() string
+	// Severity is used unless the configuration overrides it.
+	Severity() Severity
+}
+
+// SourceRule checks the values template as written.
+type SourceRule interface {
+	Rule
+	CheckSource(src *Source) []Diagnostic
+}
+
+// RenderedRule checks the values rendered for one environment.
+type RenderedRule interface {
+	Rule
+	CheckRendered(r *Rendered) []Diagnostic
+}
+
+// Source is the values template of a component.
+type Source struct {
+	Component string
+	File      string
+	Text      string
+	Lines     []string
+}
+
+// Diagnostic returns a diagnostic at a 1-based line of the source.
+func (s *Source) Diagnostic(line int, format string, args ...interface{}) Diagnostic {
+	return Diagnostic{File: s.File, Line: line, Message: fmt.Sprintf(format, args...)}
+}
+
+// Rendered is the values template of a component rendered for an environment.
+type Rendered struct {
+	*Source
+	Environment inventory.Environment
+	// Values is the mapping node of the rendered document.
+	Values *yaml.Node
+	// UsesSealedSecrets is the `$useSealedSecrets` condition of the template.
+	UsesSealedSecrets bool
+	// RegistryURL is the value CONTAINER_REGISTRY_URL was rendered with.
+	RegistryURL string
+}
+
+// Diagnostic returns a diagnostic located at the template line of the value at path.
+func (r *Rendered) Diagnostic(path []string, format string, args ...interface{}) Diagnostic {
+	return r.Source.Diagnostic(SourceLine(r.Lines, path), format, args...)
+}
+
+// Options configures an Engine.
+type Options struct {
+	Rules  []Rule
+	Config *Config
+	// RegistryURL is rendered as CONTAINER_REGISTRY_URL.
+	RegistryURL string
+}
+
+type Option func(*Options) error
+
+// GetDefaultOptions returns the built-in rules with every rule enabled.
+func GetDefaultOptions() Options {
+	return Options{
+		Rules:       DefaultRules(),
+		Config:      &Config{},
+		RegistryURL: "registry.lint.local",
+	}
+}
+
+// WithRules is an Option to replace the rules of the engine.
+func WithRules(rules ...Rule) Option {
+	return func(opt *Options) error {
+		opt.Rules = rules
+		return nil
+	}
+}
+
+// WithConfig is an Option to set the per component rule configuration.
+func WithConfig(config *Config) Option {
+	return func(opt *Options) error {
+		if config == nil {
+			return fmt.Errorf("config must not be nil")
+		}
+		opt.Config = config
+		return nil
+	}
+}
+
+// WithRegistryURL is an Option to set the value rendered as CONTAINER_REGISTRY_URL.
+func WithRegistryURL(url string) Option {
+	return func(opt *Options) error {
+		opt.RegistryURL = url
+		return nil
+	}
+}
+
+func compileOptions(opts ...Option) (*Options, error) {
+	options := GetDefaultOptions()
+	for _, opt := range opts {
+		if err := opt(&options); err != nil {
+			return nil, err
+		}
+	}
+	return &options, nil
+}
+
+// Engine runs lint rules on qcs values templates.
+type Engine struct {
+	opts *Options
+}
+
+// NewEngine creates an engine with the built-in rules unless WithRules is given.
+func NewEngine(opts ...Option) (*Engine, error) {
+	options, err := compileOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]bool{renderRule.Name(): true}
+	for _, rule := range options.Rules {
+		if names[rule.Name()] {
+			return nil, fmt.Errorf("duplicate rule %s", rule.Name())
+		}
+		names[rule.Name()] = true
+	}
+	if err := options.Config.check(names); err != nil {
+		return nil, err
+	}
+	return &Engine{opts: options}, nil
+}
+
+// Rules returns the rules of the engine.
+func (e *Engine) Rules() []Rule {
+	return e.opts.Rules
+}
+
+// Lint runs the enabled rules on qcs/<component>/values.yaml in root, rendering it
+// for every environment. Vault datasources render as secret placeholders so rules
+// can spot them in the output. Diagnostics repeated across environments are merged.
+func (e *Engine) Lint(root fs.FS, component string, envs []inventory.Environment) ([]Diagnostic, error) {
+	file := path.Join("qcs", component, "values.yaml")
+	data, err := fs.ReadFile(root, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	src := &Source{Component: component, File: file, Text: string(data), Lines: strings.Split(string(data), "\n")}
+
+	var diags []Diagnostic
+	for _, rule := range e.opts.Rules {
+		if r, ok := rule.(SourceRule); ok && e.opts.Config.Enabled(component, rule.Name()) {
+			diags = append(diags, e.finish(component, rule, r.CheckSource(src))...)
+		}
+	}
+
+	var renderOpts []render.Option
+	for ds := range vaultmock.Keys(vaultmock.Scan(file, src.Text)) {
+		renderOpts = append(renderOpts, render.WithDatasource(ds, secretDatasource{}))
+	}
+	for _, env := range envs {
+		rendered, diag := e.render(root, src, env, renderOpts)
+		if diag != nil {
+			diags = append(diags, e.finish(component, renderRule, []Diagnostic{*diag})...)
+			continue
+		}
+		for _, rule := range e.opts.Rules {
+			if r, ok := rule.(RenderedRule); ok && e.opts.Config.Enabled(component, rule.Name()) {
+				found := e.finish(component, rule, r.CheckRendered(rendered))
+				for i := range found {
+					found[i].Environments = []string{env.Name}
+				}
+				diags = append(diags, found...)
+			}
+		}
+	}
+	return merge(diags), nil
+}
+
+// renderRule reports templates that do not render.
+var renderRule = ruleInfo{"render", "The values template renders to valid YAML", SeverityError}
+
+func (e *Engine) render(root fs.FS, src *Source, env inventory.Environment, opts []render.Option) (*Rendered, *Diagnostic) {
+	fail := func(err error) (*Rendered, *Diagnostic) {
+		d := src.Diagnostic(0, "%v", err)
+		d.Environments = []string{env.Name}
+		return nil, &d
+	}
+	uses, err := sealedsecrets.UsesSealedSecrets(src.Text, env)
+	if err != nil {
+		return fail(err)
+	}
+	out, err := validate.RenderValues(root, src.Component, src.Text, env, e.opts.RegistryURL, opts...)
+	if err != nil {
+		return fail(err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(out), &doc); err != nil {
+		return fail(err)
+	}
+	values := &yaml.Node{Kind: yaml.MappingNode}
+	if len(doc.Content) > 0 && doc.Content[0].Kind == yaml.MappingNode {
+		values = doc.Content[0]
+	}
+	return &Rendered{
+		Source:            src,
+		Environment:       env,
+		Values:            values,
+		UsesSealedSecrets: uses,
+		RegistryURL:       e.opts.RegistryURL,
+	}, nil
+}
+
+// finish sets the rule name and the configured severity of the diagnostics.
+func (e *Engine) finish(component string, rule Rule, diags []Diagnostic) []Diagnostic {
+	for i := range diags {
+		diags[i].Rule = rule.Name()
+		diags[i].Severity = e.opts.Config.SeverityOf(component, rule.Name(), rule.Severity())
+	}
+	return diags
+}
+
+// merge joins diagnostics that only differ by environment and sorts them by line.
+func merge(diags []Diagnostic) []Diagnostic {
+	var merged []Diagnostic
+	index := map[string]int{}
+	for _, d := range diags {
+		key := fmt.Sprintf("%s|%s|%d|%s", d.Rule, d.File, d.Line, d.Message)
+		if i, ok := index[key]; ok {
+			merged[i].Environments = append(merged[i].Environments, d.Environments...)
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, d)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		if merged[i].Line != merged[j].Line {
+			return merged[i].Line < merged[j].Line
+		}
+		return merged[i].Rule < merged[j].Rule
+	})
+	return merged
+}
+
+// SecretPlaceholder prefixes the value rendered for every vault datasource key.
+const SecretPlaceholder = "lint-secret-"
+
+// secretDatasource returns {"value": "lint-secret-<key>"} for every key.
+type secretDatasource struct{}
+
+func (secretDatasource) Read(key string) (interface{}, error) {
+	return map[string]interface{}{"value": SecretPlaceholder + key}, nil
+}
+
+type ruleInfo struct {
+	name        string
+	description string
+	severity    Severity
+}
+
+func (r ruleInfo) Name() string        { return r.name }
+func (r ruleInfo) Description() string { return r.description }
+func (r ruleInfo) Severity() Severity  { return r.severity }
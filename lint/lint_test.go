@@ -0,0 +1,235 @@
+package lint_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"gitpkg/inventory"
+	"gitpkg/lint"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const values = `## chart: https://github.com/org/c/tree/main/chart
+{{ $environment := getenv "ENVIRONMENT" -}}
+{{ $registry := getenv "CONTAINER_REGISTRY_URL" "" -}}
+{{- $provider := getenv "PROVIDER" "aws" }}
+
+global:
+  imageRegistry: {{ $registry }}
+
+image:
+  pullSecrets: ~
+
+replicaCount: {{ if eq $environment "prod" }}2{{ else }}1{{ end }}
+
+resources:
+  requests:
+    memory: 5Mi
+  limits:
+    memory: 10Mi
+
+networkPolicy:
+  ipBlock:
+    blockedCidrs:
+      {{- if (eq "eks" $provider) }}
+      defaultBlock: false
+      additionalBlockedCidrs:
+        - 100.64.0.0/10
+      {{- else }}
+      defaultBlock: true
+      {{- end }}
+`
+
+func testEnvironments(t *testing.T) []inventory.Environment {
+	t.Helper()
+	inv, err := inventory.Parse([]byte(`pipeline-environments:
+  - qcs-stage-us-east-1
+  - qcs-prod-eu-west-1
+  - name: qcs-prod-eks-us-east-1
+    environment: prod
+    region: us-east-1
+    provider: eks
+`))
+	assert.NoError(t, err)
+	return inv.List()
+}
+
+func lintValues(t *testing.T, text string, opts ...lint.Option) []lint.Diagnostic {
+	t.Helper()
+	engine, err := lint.NewEngine(opts...)
+	assert.NoError(t, err)
+	root := fstest.MapFS{"qcs/c/values.yaml": {Data: []byte(text)}}
+	diags, err := engine.Lint(root, "c", testEnvironments(t))
+	assert.NoError(t, err)
+	return diags
+}
+
+func strs(diags []lint.Diagnostic) []string {
+	var out []string
+	for _, d := range diags {
+		out = append(out, d.String())
+	}
+	return out
+}
+
+func TestLint(t *testing.T) {
+	t.Run("Lint passes a template following the conventions", func(t *testing.T) {
+		// Act
+		diags := lintValues(t, values)
+
+		// Assert
+		assert.Empty(t, strs(diags))
+	})
+
+	t.Run("Lint reports rendered violations at the template line, merged across environments", func(t *testing.T) {
+		// Arrange
+		text := strings.Replace(values, "2{{ else }}1", "1{{ else }}1", 1)
+		text = strings.Replace(text, "  limits:\n    memory: 10Mi\n", "", 1)
+		text = strings.Replace(text, "  pullSecrets: ~", "  pullSecrets: [secret]", 1)
+
+		// Act
+		diags := lintValues(t, text)
+
+		// Assert
+		assert.Equal(t, []string{
+			"qcs/c/values.yaml:10: warning: [pull-secrets] image.pullSecrets must be ~ (qcs-stage-us-east-1, qcs-prod-eu-west-1, qcs-prod-eks-us-east-1)",
+			"qcs/c/values.yaml:12: error: [prod-replicas] replicaCount is 1, prod requires at least 2 (qcs-prod-eu-west-1, qcs-prod-eks-us-east-1)",
+			"qcs/c/values.yaml:14: error: [memory-limits] resources has no memory limit (qcs-stage-us-east-1, qcs-prod-eu-west-1, qcs-prod-eks-us-east-1)",
+		}, strs(diags))
+	})
+
+	t.Run("Lint checks the header, registry and blockedCidrs provider branch", func(t *testing.T) {
+		text := strings.Replace(values, "## chart: https://github.com/org/c/tree/main/chart\n", "", 1)
+		text = strings.Replace(text, "imageRegistry: {{ $registry }}", "imageRegistry: docker.io", 1)
+		text = strings.Replace(text, `(eq "eks" $provider)`, `(eq "gke" $provider)`, 1)
+
+		diags := lintValues(t, text, lint.WithRegistryURL("registry.example.com"))
+
+		assert.Equal(t, []string{
+			"qcs/c/values.yaml:1: warning: [chart-header] missing ## comment header with the chart link",
+			`qcs/c/values.yaml:6: error: [image-registry] global.imageRegistry is "docker.io", not CONTAINER_REGISTRY_URL (qcs-stage-us-east-1, qcs-prod-eu-west-1, qcs-prod-eks-us-east-1)`,
+			"qcs/c/values.yaml:21: error: [blocked-cidrs] blockedCidrs is not branched on the eks provider",
+			"qcs/c/values.yaml:23: error: [blocked-cidrs] defaultBlock must be false for provider eks (qcs-prod-eks-us-east-1)",
+			"qcs/c/values.yaml:24: error: [blocked-cidrs] additionalBlockedCidrs must be set for provider eks (qcs-prod-eks-us-east-1)",
+		}, strs(diags))
+	})
+
+	t.Run("Lint reports vault values rendered when useSealedSecrets is true", func(t *testing.T) {
+		text := `{{ $environment := getenv "ENVIRONMENT" -}}
+{{ $useSealedSecrets := (eq $environment "prod") -}}
+global:
+  imageRegistry: {{ getenv "CONTAINER_REGISTRY_URL" }}
+image:
+  pullSecrets: ~
+{{- if not $useSealedSecrets }}
+secrets:
+  stringData:
+    password: {{ (ds "vault" "password").value }}
+{{- end }}
+redis:
+  password: {{ (ds "vault" "redisPassword").value }}
+`
+
+		diags := lintValues(t, text, lint.WithConfig(&lint.Config{Disabled: []string{"chart-header"}}))
+
+		assert.Equal(t, []string{
+			"qcs/c/values.yaml:13: error: [sealed-secrets-plaintext] redis.password renders a vault value although useSealedSecrets is true (qcs-prod-eu-west-1, qcs-prod-eks-us-east-1)",
+		}, strs(diags))
+	})
+
+	t.Run("Lint reports templates that do not render", func(t *testing.T) {
+		diags := lintValues(t, "## https://chart\na: {{ .Missing.Field }\n")
+
+		assert.Len(t, diags, 1)
+		assert.Equal(t, "render", diags[0].Rule)
+		assert.Equal(t, lint.SeverityError, diags[0].Severity)
+		assert.Len(t, diags[0].Environments, 3)
+	})
+}
+
+func TestConfig(t *testing.T) {
+	t.Run("Config disables rules and overrides severities per component", func(t *testing.T) {
+		// Arrange
+		config, err := lint.ParseConfig([]byte(`disabled: [chart-header]
+severity:
+  memory-limits: warning
+components:
+  c:
+    disabled: [prod-replicas]
+    severity:
+      memory-limits: error
+`))
+		assert.NoError(t, err)
+
+		// Act & Assert
+		assert.False(t, config.Enabled("c", "chart-header"))
+		assert.False(t, config.Enabled("c", "prod-replicas"))
+		assert.True(t, config.Enabled("other", "prod-replicas"))
+		assert.Equal(t, lint.SeverityError, config.SeverityOf("c", "memory-limits", lint.SeverityError))
+		assert.Equal(t, lint.SeverityWarning, config.SeverityOf("other", "memory-limits", lint.SeverityError))
+		assert.Equal(t, lint.SeverityWarning, config.SeverityOf("other", "pull-secrets", lint.SeverityWarning))
+		_, err = lint.NewEngine(lint.WithConfig(config))
+		assert.NoError(t, err)
+	})
+
+	t.Run("NewEngine rejects unknown rules and severities", func(t *testing.T) {
+		config, err := lint.ParseConfig([]byte(`components:
+  c:
+    disabled: [no-such-rule]
+    severity:
+      memory-limits: fatal
+`))
+		assert.NoError(t, err)
+
+		_, err = lint.NewEngine(lint.WithConfig(config))
+
+		assert.ErrorContains(t, err, "component c: unknown rule no-such-rule")
+		assert.ErrorContains(t, err, `component c: invalid severity "fatal" for memory-limits`)
+	})
+
+	t.Run("LoadConfig enables every rule when the file is missing", func(t *testing.T) {
+		config, err := lint.LoadConfig(t.TempDir() + "/" + lint.DefaultConfigFile)
+
+		assert.NoError(t, err)
+		assert.True(t, config.Enabled("c", "memory-limits"))
+	})
+}
+
+func TestSourceLine(t *testing.T) {
+	lines := strings.Split(`## header
+{{ $x := getenv "X" -}}
+a:
+  b: 1
+  # comment
+  c:
+    d: {{ $x }}
+list:
+- name: one
+  hpa:
+    min: 1
+## second item
+- name: two
+  hpa:
+    min: 1
+b: 2
+`, "\n")
+	tests := []struct {
+		path []string
+		line int
+	}{
+		{[]string{"a", "c", "d"}, 7},
+		{[]string{"b"}, 16},
+		{[]string{"list", "[0]", "hpa", "min"}, 11},
+		{[]string{"list", "[1]", "hpa", "min"}, 15},
+		{[]string{"list", "[1]", "name"}, 13},
+		{[]string{"a", "missing"}, 3},
+		{[]string{"missing"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(lint.PathString(tt.path), func(t *testing.T) {
+			assert.Equal(t, tt.line, lint.SourceLine(lines, tt.path))
+		})
+	}
+}
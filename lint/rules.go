@@ -0,0 +1,178 @@
+package lint
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gitpkg/inventory"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultRules returns the built-in rules for the qcs values conventions.
+func DefaultRules() []Rule {
+	return []Rule{
+		&ChartHeader{ruleInfo{"chart-header", "The template starts with a ## comment linking the chart", SeverityWarning}},
+		&ImageRegistry{ruleInfo{"image-registry", "global.imageRegistry or image.registry is CONTAINER_REGISTRY_URL", SeverityError}},
+		&PullSecrets{ruleInfo{"pull-secrets", "image.pullSecrets is set to ~", SeverityWarning}},
+		&BlockedCidrs{ruleInfo{"blocked-cidrs", "networkPolicy blockedCidrs branch on the eks provider", SeverityError}},
+		&MemoryLimits{ruleInfo{"memory-limits", "Every resources block sets limits.memory", SeverityError}},
+		&ProdReplicas{ruleInfo{"prod-replicas", "Replicas are at least 2 in prod", SeverityError}},
+		&SealedSecretsPlaintext{ruleInfo{"sealed-secrets-plaintext", "No plaintext secrets when useSealedSecrets is true", SeverityError}},
+	}
+}
+
+// ChartHeader checks the leading ## comment holds the chart link.
+type ChartHeader struct{ ruleInfo }
+
+func (r *ChartHeader) CheckSource(src *Source) []Diagnostic {
+	for _, line := range src.Lines {
+		text := strings.TrimSpace(line)
+		if !strings.HasPrefix(text, "##") {
+			break
+		}
+		if strings.Contains(text, "https://") {
+			return nil
+		}
+	}
+	return []Diagnostic{src.Diagnostic(1, "missing ## comment header with the chart link")}
+}
+
+// ImageRegistry checks images are pulled from CONTAINER_REGISTRY_URL.
+type ImageRegistry struct{ ruleInfo }
+
+func (r *ImageRegistry) CheckRendered(rendered *Rendered) []Diagnostic {
+	var diags []Diagnostic
+	set := false
+	for _, path := range [][]string{{"global", "imageRegistry"}, {"image", "registry"}} {
+		node := Lookup(rendered.Values, path...)
+		if node == nil {
+			continue
+		}
+		set = true
+		if node.Value != rendered.RegistryURL {
+			diags = append(diags, rendered.Diagnostic(path, "%s is %q, not CONTAINER_REGISTRY_URL", PathString(path), node.Value))
+		}
+	}
+	if !set {
+		diags = append(diags, rendered.Diagnostic([]string{"global"}, "neither global.imageRegistry nor image.registry is set"))
+	}
+	return diags
+}
+
+// PullSecrets checks image.pullSecrets is negated so the chart default is not used.
+type PullSecrets struct{ ruleInfo }
+
+func (r *PullSecrets) CheckRendered(rendered *Rendered) []Diagnostic {
+	path := []string{"image", "pullSecrets"}
+	node := Lookup(rendered.Values, path...)
+	if node == nil {
+		return []Diagnostic{rendered.Diagnostic(path, "image.pullSecrets is not set to ~")}
+	}
+	if !IsNull(node) {
+		return []Diagnostic{rendered.Diagnostic(path, "image.pullSecrets must be ~")}
+	}
+	return nil
+}
+
+// eksBranch matches the provider condition around the blockedCidrs settings.
+var eksBranch = regexp.MustCompile(`eq\s+(?:"eks"\s+\$provider|\$provider\s+"eks")`)
+
+// BlockedCidrs checks the networkPolicy blockedCidrs follow the provider branch:
+// eks blocks its own list of CIDRs, every other provider the default block.
+type BlockedCidrs struct{ ruleInfo }
+
+func (r *BlockedCidrs) CheckSource(src *Source) []Diagnostic {
+	line := SourceLine(src.Lines, blockedCidrsPath)
+	if line == 0 || eksBranch.MatchString(src.Text) {
+		return nil
+	}
+	return []Diagnostic{src.Diagnostic(line, "blockedCidrs is not branched on the eks provider")}
+}
+
+var blockedCidrsPath = []string{"networkPolicy", "ipBlock", "blockedCidrs"}
+
+func (r *BlockedCidrs) CheckRendered(rendered *Rendered) []Diagnostic {
+	blocked := Lookup(rendered.Values, blockedCidrsPath...)
+	if blocked == nil {
+		return nil
+	}
+	eks := rendered.Environment.Provider == "eks"
+	var diags []Diagnostic
+	path := append(append([]string{}, blockedCidrsPath...), "defaultBlock")
+	if node := Lookup(blocked, "defaultBlock"); node == nil || node.Value != strconv.FormatBool(!eks) {
+		diags = append(diags, rendered.Diagnostic(path, "defaultBlock must be %t for provider %s", !eks, rendered.Environment.Provider))
+	}
+	if eks {
+		path := append(append([]string{}, blockedCidrsPath...), "additionalBlockedCidrs")
+		if node := Lookup(blocked, "additionalBlockedCidrs"); node == nil || node.Kind != yaml.SequenceNode || len(node.Content) == 0 {
+			diags = append(diags, rendered.Diagnostic(path, "additionalBlockedCidrs must be set for provider eks"))
+		}
+	}
+	return diags
+}
+
+// MemoryLimits checks every container resources block sets a memory limit.
+type MemoryLimits struct{ ruleInfo }
+
+func (r *MemoryLimits) CheckRendered(rendered *Rendered) []Diagnostic {
+	var diags []Diagnostic
+	Walk(rendered.Values, func(path []string, node *yaml.Node) {
+		if path[len(path)-1] != "resources" || node.Kind != yaml.MappingNode {
+			return
+		}
+		if Lookup(node, "requests") == nil && Lookup(node, "limits") == nil {
+			return
+		}
+		if memory := Lookup(node, "limits", "memory"); IsNull(memory) || memory.Value == "" {
+			diags = append(diags, rendered.Diagnostic(path, "%s has no memory limit", PathString(path)))
+		}
+	})
+	return diags
+}
+
+// replicaKeys are the keys charts use for the number of pods.
+var replicaKeys = map[string]bool{"replicas": true, "replicaCount": true, "minReplicas": true}
+
+// ProdReplicas checks prod runs at least 2 pods of every workload.
+type ProdReplicas struct{ ruleInfo }
+
+func (r *ProdReplicas) CheckRendered(rendered *Rendered) []Diagnostic {
+	if rendered.Environment.Tier != inventory.TierProd {
+		return nil
+	}
+	var diags []Diagnostic
+	Walk(rendered.Values, func(path []string, node *yaml.Node) {
+		if !replicaKeys[path[len(path)-1]] || node.Kind != yaml.ScalarNode {
+			return
+		}
+		if n, err := strconv.Atoi(node.Value); err == nil && n < 2 {
+			diags = append(diags, rendered.Diagnostic(path, "%s is %d, prod requires at least 2", PathString(path), n))
+		}
+	})
+	return diags
+}
+
+// SealedSecretsPlaintext checks templates using sealed secrets render no secret
+// values: no secrets.data or secrets.stringData and no value read from vault.
+type SealedSecretsPlaintext struct{ ruleInfo }
+
+func (r *SealedSecretsPlaintext) CheckRendered(rendered *Rendered) []Diagnostic {
+	if !rendered.UsesSealedSecrets {
+		return nil
+	}
+	var diags []Diagnostic
+	for _, key := range []string{"data", "stringData"} {
+		path := []string{"secrets", key}
+		if !IsNull(Lookup(rendered.Values, path...)) {
+			diags = append(diags, rendered.Diagnostic(path, "secrets.%s is rendered although useSealedSecrets is true", key))
+		}
+	}
+	Walk(rendered.Values, func(path []string, node *yaml.Node) {
+		if node.Kind == yaml.ScalarNode && strings.Contains(node.Value, SecretPlaceholder) {
+			diags = append(diags, rendered.Diagnostic(path, "%s renders a vault value although useSealedSecrets is true", PathString(path)))
+		}
+	})
+	return diags
+}
@@ -0,0 +1,79 @@
+package lint
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gitpkg/inventory"
+	"gitpkg/sealedsecrets"
+)
+
+// Setup registers the flags of the lint command; the command prints every diagnostic and
+// fails when any has severity error.
+func Setup(fs *flag.FlagSet) func(args []string) error {
+	root := fs.String("root", ".", "Repository root containing the qcs directory")
+	inventoryFile := fs.String("inventory", "gitops-environments/"+inventory.DefaultFile, "Path of the environment inventory")
+	components := fs.String("components", "", "Comma separated components to lint, all when empty")
+	configFile := fs.String("config", "", "Lint configuration, <root>/"+DefaultConfigFile+" by default; ignored when missing")
+	registry := fs.String("registry-url", "registry.lint.local", "Value rendered as CONTAINER_REGISTRY_URL")
+	listRules := fs.Bool("list-rules", false, "Print the available rules and exit")
+	return func(args []string) error {
+		if *listRules {
+			writeRules(os.Stdout, DefaultRules())
+			return nil
+		}
+
+		if *configFile == "" {
+			*configFile = filepath.Join(*root, DefaultConfigFile)
+		}
+		config, err := LoadConfig(*configFile)
+		if err != nil {
+			return err
+		}
+		engine, err := NewEngine(WithConfig(config), WithRegistryURL(*registry))
+		if err != nil {
+			return err
+		}
+		inv, err := inventory.Load(*inventoryFile)
+		if err != nil {
+			return err
+		}
+		var names []string
+		if *components != "" {
+			names = strings.Split(*components, ",")
+		} else if names, err = sealedsecrets.Components(*root); err != nil {
+			return err
+		}
+
+		errors, warnings := 0, 0
+		for _, component := range names {
+			diags, err := engine.Lint(os.DirFS(*root), component, inv.List())
+			if err != nil {
+				return err
+			}
+			for _, d := range diags {
+				fmt.Println(d)
+				if d.Severity == SeverityError {
+					errors++
+				} else {
+					warnings++
+				}
+			}
+		}
+		fmt.Printf("%d components linted: %d errors, %d warnings\n", len(names), errors, warnings)
+		if errors > 0 {
+			return fmt.Errorf("%d lint errors found", errors)
+		}
+		return nil
+	}
+}
+
+func writeRules(w io.Writer, rules []Rule) {
+	for _, rule := range rules {
+		fmt.Fprintf(w, "%-26s %-8s %s\n", rule.Name(), rule.Severity(), rule.Description())
+	}
+}
@@ -0,0 +1,160 @@
+package lint
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Lookup returns the value at the given mapping keys, or nil.
+func Lookup(node *yaml.Node, keys ...string) *yaml.Node {
+	for _, key := range keys {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		node = next
+	}
+	return node
+}
+
+// Walk calls fn for every node below node with its path of mapping keys and
+// "[index]" sequence elements.
+func Walk(node *yaml.Node, fn func(path []string, node *yaml.Node)) {
+	walk(nil, node, fn)
+}
+
+func walk(path []string, node *yaml.Node, fn func(path []string, node *yaml.Node)) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			p := append(append([]string{}, path...), node.Content[i].Value)
+			fn(p, node.Content[i+1])
+			walk(p, node.Content[i+1], fn)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			p := append(append([]string{}, path...), fmt.Sprintf("[%d]", i))
+			fn(p, item)
+			walk(p, item, fn)
+		}
+	case yaml.AliasNode:
+		walk(path, node.Alias, fn)
+	}
+}
+
+// IsNull reports whether a node is missing or an explicit null.
+func IsNull(node *yaml.Node) bool {
+	return node == nil || (node.Kind == yaml.ScalarNode && node.Tag == "!!null")
+}
+
+// SourceLine returns the 1-based line of the template declaring the value at a
+// rendered path. The template is not valid YAML, so keys and list items are
+// matched by their indentation while template actions and comments are skipped.
+// When the path is only partly written in the template, e.g. it comes from an
+// included file, the line of the deepest element found is returned, and 0 when
+// none is.
+func SourceLine(lines []string, path []string) int {
+	line, parent := 0, -1
+	// start is the first line to search; item is the line opening the current
+	// list item, whose first key shares the line with the "- " marker.
+	start, item := 0, -1
+	for _, seg := range path {
+		index, isIndex := parseIndex(seg)
+		found, child, count := false, -1, 0
+		for i := start; i < len(lines); i++ {
+			indent, dash, text := splitLine(lines[i])
+			if text == "" {
+				continue
+			}
+			if i != item && dash >= 0 && dash <= parent && !(isIndex && dash == parent) {
+				break
+			}
+			if dash < 0 && indent <= parent {
+				break
+			}
+			if isIndex {
+				if dash < 0 {
+					continue
+				}
+				if child == -1 {
+					child = dash
+				}
+				if dash == child {
+					if count == index {
+						line, parent, start, item, found = i+1, dash, i, i, true
+						break
+					}
+					count++
+				}
+				continue
+			}
+			if child == -1 {
+				child = indent
+			}
+			if indent == child && strings.HasPrefix(text, seg+":") {
+				line, parent, start, item, found = i+1, indent, i+1, -1, true
+				break
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	return line
+}
+
+// parseIndex parses a "[index]" path element.
+func parseIndex(seg string) (int, bool) {
+	if !strings.HasPrefix(seg, "[") || !strings.HasSuffix(seg, "]") {
+		return 0, false
+	}
+	n, err := strconv.Atoi(seg[1 : len(seg)-1])
+	return n, err == nil
+}
+
+// splitLine returns the indentation and text of a template line, counting "- "
+// list markers as indentation, and the column of the marker, -1 when the line
+// does not open a list item. The text is empty for blank lines, comments and
+// lines starting with a template action.
+func splitLine(line string) (int, int, string) {
+	text := strings.TrimLeft(line, " ")
+	indent, dash := len(line)-len(text), -1
+	if text == "-" || strings.HasPrefix(text, "- ") {
+		dash = indent
+	}
+	for strings.HasPrefix(text, "- ") {
+		trimmed := strings.TrimLeft(text[2:], " ")
+		indent += len(text) - len(trimmed)
+		text = trimmed
+	}
+	text = strings.TrimSpace(text)
+	if dash >= 0 && (text == "-" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, "{{")) {
+		// a bare marker or an item rendered by an action still opens an item
+		return indent, dash, "-"
+	}
+	if strings.HasPrefix(text, "#") || strings.HasPrefix(text, "{{") {
+		return indent, dash, ""
+	}
+	return indent, dash, text
+}
+
+// PathString formats a path as written in diagnostics, e.g. "a.b[0].c".
+func PathString(path []string) string {
+	var b strings.Builder
+	for i, seg := range path {
+		if i > 0 && !strings.HasPrefix(seg, "[") {
+			b.WriteByte('.')
+		}
+		b.WriteString(seg)
+	}
+	return b.String()
+}
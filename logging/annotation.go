@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// Severities of annotations.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Annotation is a problem at a position of a repository file, shown inline on
+// the pull request diff by GitHub.
+type Annotation struct {
+	// Severity is SeverityError or SeverityWarning; SeverityError when empty.
+	Severity string
+	// File is relative to the repository root, e.g. components/x/y/conf.yaml.
+	File string
+	// Line and Col are 1-based, 0 when unknown.
+	Line int
+	Col  int
+	// Title is the optional heading of the annotation.
+	Title   string
+	Message string
+}
+
+// Annotator emits annotations.
+type Annotator interface {
+	// Enabled reports whether annotations are emitted, so callers can log
+	// the problem instead.
+	Enabled() bool
+	Annotate(a Annotation)
+}
+
+// NewAnnotator returns a GitHubAnnotator writing to w on GitHub Actions
+// runners and an annotator dropping every annotation everywhere else.
+func NewAnnotator(w io.Writer) Annotator {
+	if os.Getenv("GITHUB_ACTIONS") != "true" {
+		return NopAnnotator()
+	}
+	return NewGitHubAnnotator(w)
+}
+
+// NopAnnotator returns an annotator dropping every annotation.
+func NopAnnotator() Annotator {
+	return nopAnnotator{}
+}
+
+type nopAnnotator struct{}
+
+func (nopAnnotator) Enabled() bool       { return false }
+func (nopAnnotator) Annotate(Annotation) {}
+
+// GitHubAnnotator writes annotations as ::error and ::warning workflow commands.
+type GitHubAnnotator struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewGitHubAnnotator returns an annotator writing workflow commands to w.
+func NewGitHubAnnotator(w io.Writer) *GitHubAnnotator {
+	return &GitHubAnnotator{w: w}
+}
+
+func (a *GitHubAnnotator) Enabled() bool { return true }
+
+func (a *GitHubAnnotator) Annotate(an Annotation) {
+	props := map[string]string{}
+	if an.Title != "" {
+		props["title"] = an.Title
+	}
+	if an.File != "" {
+		props["file"] = an.File
+	}
+	if an.Line > 0 {
+		props["line"] = strconv.Itoa(an.Line)
+	}
+	if an.Col > 0 {
+		props["col"] = strconv.Itoa(an.Col)
+	}
+	severity := an.Severity
+	if severity == "" {
+		severity = SeverityError
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	io.WriteString(a.w, formatCommand(severity, props, an.Message))
+}
@@ -0,0 +1,166 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// annotationKeys are the attributes that become the properties of the
+// ::error and ::warning commands instead of part of the message, in the
+// order GitHub documents them.
+var annotationKeys = []string{"title", "file", "line", "col", "endLine", "endColumn"}
+
+// GitHubHandler writes records as GitHub Actions workflow commands: errors
+// and warnings become ::error and ::warning annotations placed by their
+// file, line and col attributes, debug records become ::debug lines shown
+// when step debugging is enabled, and info records are plain lines.
+type GitHubHandler struct {
+	opts   slog.HandlerOptions
+	mu     *sync.Mutex
+	w      io.Writer
+	attrs  []slog.Attr
+	prefix string
+}
+
+// NewGitHubHandler returns a handler writing workflow commands to w.
+func NewGitHubHandler(w io.Writer, opts *slog.HandlerOptions) *GitHubHandler {
+	h := &GitHubHandler{mu: &sync.Mutex{}, w: w}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+func (h *GitHubHandler) Enabled(_ context.Context, level slog.Level) bool {
+	min := slog.LevelInfo
+	if h.opts.Level != nil {
+		min = h.opts.Level.Level()
+	}
+	return level >= min
+}
+
+func (h *GitHubHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.attrs = append(append([]slog.Attr{}, h.attrs...), h.prefixed(attrs)...)
+	return &c
+}
+
+func (h *GitHubHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.prefix = h.prefix + name + "."
+	return &c
+}
+
+func (h *GitHubHandler) prefixed(attrs []slog.Attr) []slog.Attr {
+	out := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		a.Key = h.prefix + a.Key
+		out = append(out, a)
+	}
+	return out
+}
+
+func (h *GitHubHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := append([]slog.Attr{}, h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, h.prefixed([]slog.Attr{a})...)
+		return true
+	})
+
+	props := map[string]string{}
+	var fields []string
+	for _, a := range attrs {
+		h.appendAttr(nil, a, props, &fields)
+	}
+	msg := r.Message
+	if len(fields) > 0 {
+		msg += " " + strings.Join(fields, " ")
+	}
+
+	switch {
+	case r.Level >= slog.LevelError:
+		h.command("error", props, msg)
+	case r.Level >= slog.LevelWarn:
+		h.command("warning", props, msg)
+	case r.Level < slog.LevelInfo:
+		h.command("debug", nil, msg)
+	default:
+		h.write(msg + "\n")
+	}
+	return nil
+}
+
+// appendAttr adds the annotation properties to props and the other
+// attributes as key=value to fields.
+func (h *GitHubHandler) appendAttr(groups []string, a slog.Attr, props map[string]string, fields *[]string) {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		for _, ga := range a.Value.Group() {
+			ga.Key = a.Key + "." + ga.Key
+			h.appendAttr(append(groups, a.Key), ga, props, fields)
+		}
+		return
+	}
+	if h.opts.ReplaceAttr != nil {
+		a = h.opts.ReplaceAttr(groups, a)
+	}
+	if a.Key == "" {
+		return
+	}
+	for _, key := range annotationKeys {
+		if a.Key == key {
+			props[key] = a.Value.String()
+			return
+		}
+	}
+	value := a.Value.String()
+	if value == "" || strings.ContainsAny(value, " \t\"=") {
+		value = strconv.Quote(value)
+	}
+	*fields = append(*fields, a.Key+"="+value)
+}
+
+// command writes the workflow command ::name props::msg.
+func (h *GitHubHandler) command(name string, props map[string]string, msg string) {
+	h.write(formatCommand(name, props, msg))
+}
+
+func (h *GitHubHandler) write(s string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	io.WriteString(h.w, s)
+}
+
+// formatCommand formats the workflow command ::name props::msg, with the
+// annotation properties in annotationKeys order.
+func formatCommand(name string, props map[string]string, msg string) string {
+	var ps []string
+	for _, key := range annotationKeys {
+		if v, ok := props[key]; ok {
+			ps = append(ps, key+"="+escapeProperty(v))
+		}
+	}
+	cmd := "::" + name
+	if len(ps) > 0 {
+		cmd += " " + strings.Join(ps, ",")
+	}
+	return fmt.Sprintf("%s::%s\n", cmd, escapeData(msg))
+}
+
+// escapeData escapes the message of a workflow command.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
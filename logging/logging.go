@@ -0,0 +1,140 @@
+// Package logging builds the slog loggers of the tools: text for terminals,
+// JSON for log collection, and GitHub workflow commands on Actions runners,
+// with secrets redacted in every format.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"gitpkg/render"
+)
+
+// Formats of the handlers.
+const (
+	FormatText   = "text"
+	FormatJSON   = "json"
+	FormatGitHub = "github"
+)
+
+// Redacted replaces the values of secret attributes. It is the marker the
+// rendered values are redacted with, so both read the same in the output.
+const Redacted = render.Redacted
+
+// Options configure a logger.
+type Options struct {
+	// Level is the minimum level logged.
+	Level slog.Level
+	// Format is FormatText, FormatJSON or FormatGitHub. DefaultFormat is
+	// used when empty.
+	Format string
+}
+
+// DefaultFormat returns FormatGitHub on GitHub Actions runners and
+// FormatText everywhere else.
+func DefaultFormat() string {
+	if os.Getenv("GITHUB_ACTIONS") == "true" {
+		return FormatGitHub
+	}
+	return FormatText
+}
+
+// ParseLevel parses debug, info, warn or error.
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q: expected debug, info, warn or error", s)
+	}
+	return level, nil
+}
+
+// New returns a logger writing to w.
+func New(w io.Writer, opts Options) (*slog.Logger, error) {
+	handlerOpts := &slog.HandlerOptions{Level: opts.Level, ReplaceAttr: RedactAttr}
+	format := opts.Format
+	if format == "" {
+		format = DefaultFormat()
+	}
+	switch format {
+	case FormatText:
+		return slog.New(slog.NewTextHandler(w, handlerOpts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, handlerOpts)), nil
+	case FormatGitHub:
+		return slog.New(NewGitHubHandler(w, handlerOpts)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q: expected %s, %s or %s", format, FormatText, FormatJSON, FormatGitHub)
+	}
+}
+
+// OrDefault returns logger, or slog.Default() when it is nil, so packages
+// can log through an optional logger of their options.
+func OrDefault(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.Default()
+	}
+	return logger
+}
+
+// Discard returns a logger dropping every record.
+func Discard() *slog.Logger {
+	return slog.New(discardHandler{})
+}
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// secretKeys are the parts of attribute keys holding secrets.
+var secretKeys = []string{"token", "secret", "password", "webhook"}
+
+// IsSecret reports whether an attribute key names a secret, e.g. token or
+// SlackWebhookURL.
+func IsSecret(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range secretKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactAttr is a slog.HandlerOptions.ReplaceAttr function replacing the
+// values of secret attributes, also inside groups, with Redacted.
+func RedactAttr(groups []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() == slog.KindGroup || a.Value.String() == "" {
+		return a
+	}
+	if IsSecret(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+	return a
+}
+
+// Mask returns Redacted for a set secret and an empty string otherwise, so
+// LogValue methods can show whether a secret is configured.
+func Mask(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return Redacted
+}
+
+// Group starts a collapsible group of log lines titled title and returns the
+// function ending it. Outside the GitHub format the title is logged instead.
+func Group(logger *slog.Logger, title string) func() {
+	if h, ok := logger.Handler().(*GitHubHandler); ok {
+		h.command("group", nil, title)
+		return func() { h.command("endgroup", nil, "") }
+	}
+	logger.Info(title)
+	return func() {}
+}
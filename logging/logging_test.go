@@ -0,0 +1,136 @@
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"gitpkg/logging"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type options struct {
+	URL   string
+	Token string
+}
+
+func (o options) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("url", o.URL), slog.String("token", o.Token))
+}
+
+func TestNew(t *testing.T) {
+	t.Run("New redacts secret attributes in JSON logs", func(t *testing.T) {
+		// Arrange
+		var buf bytes.Buffer
+		logger, err := logging.New(&buf, logging.Options{Format: logging.FormatJSON})
+		assert.NoError(t, err)
+
+		// Act
+		logger.Info("clone", "token", "ghp_secret", "SlackWebhookURL", "https://hooks.slack.com/x", "options", options{URL: "https://github.com/org/repo", Token: "ghp_secret"})
+
+		// Assert
+		assert.NotContains(t, buf.String(), "ghp_secret")
+		assert.NotContains(t, buf.String(), "hooks.slack.com")
+		var record map[string]interface{}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, logging.Redacted, record["token"])
+		assert.Equal(t, map[string]interface{}{"url": "https://github.com/org/repo", "token": logging.Redacted}, record["options"])
+	})
+
+	t.Run("New drops records below the level", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := logging.New(&buf, logging.Options{Level: slog.LevelWarn, Format: logging.FormatText})
+		assert.NoError(t, err)
+
+		logger.Info("hidden")
+		logger.Warn("shown")
+
+		assert.NotContains(t, buf.String(), "hidden")
+		assert.Contains(t, buf.String(), "msg=shown")
+	})
+
+	t.Run("New rejects unknown formats", func(t *testing.T) {
+		_, err := logging.New(&bytes.Buffer{}, logging.Options{Format: "xml"})
+
+		assert.EqualError(t, err, `invalid log format "xml": expected text, json or github`)
+	})
+}
+
+func TestGitHubHandler(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.Options{Level: slog.LevelDebug, Format: logging.FormatGitHub})
+	assert.NoError(t, err)
+
+	t.Run("GitHubHandler writes errors as annotations placed by their file and line", func(t *testing.T) {
+		buf.Reset()
+
+		// Act
+		logger.Error("invalid version: 1,2", "file", "components/x/y/conf.yaml", "line", 3, "kind", "invalid-config")
+
+		// Assert
+		assert.Equal(t, "::error file=components/x/y/conf.yaml,line=3::invalid version: 1,2 kind=invalid-config\n", buf.String())
+	})
+
+	t.Run("GitHubHandler writes warnings, debug and info lines", func(t *testing.T) {
+		buf.Reset()
+
+		logger.Warn("multi\nline", "token", "ghp_secret")
+		logger.Debug("opening repository", "path", "/tmp/repo")
+		logger.With("component", "hello").Info("checking out branch", "ref", "main")
+
+		assert.Equal(t, "::warning::multi%0Aline token="+logging.Redacted+"\n"+
+			"::debug::opening repository path=/tmp/repo\n"+
+			"checking out branch component=hello ref=main\n", buf.String())
+	})
+
+	t.Run("Group wraps the lines in a collapsible group", func(t *testing.T) {
+		buf.Reset()
+
+		end := logging.Group(logger, "Deploy check")
+		logger.Info("inside")
+		end()
+
+		assert.Equal(t, "::group::Deploy check\ninside\n::endgroup::\n", buf.String())
+	})
+}
+
+func TestAnnotator(t *testing.T) {
+	t.Run("GitHubAnnotator writes annotations placed by their file, line and column", func(t *testing.T) {
+		// Arrange
+		var buf bytes.Buffer
+		annotator := logging.NewGitHubAnnotator(&buf)
+
+		// Act
+		annotator.Annotate(logging.Annotation{File: "components/x/y/conf.yaml", Line: 3, Col: 5, Title: "deploy-check", Message: "version: cannot unmarshal !!seq into string"})
+		annotator.Annotate(logging.Annotation{Severity: logging.SeverityWarning, File: "qcs/x/values.yaml", Message: "50%, really"})
+
+		// Assert
+		assert.Equal(t, "::error title=deploy-check,file=components/x/y/conf.yaml,line=3,col=5::version: cannot unmarshal !!seq into string\n"+
+			"::warning file=qcs/x/values.yaml::50%25, really\n", buf.String())
+	})
+
+	t.Run("NewAnnotator is disabled outside GitHub Actions", func(t *testing.T) {
+		t.Setenv("GITHUB_ACTIONS", "")
+		var buf bytes.Buffer
+
+		annotator := logging.NewAnnotator(&buf)
+		annotator.Annotate(logging.Annotation{File: "components/x/y/conf.yaml", Message: "invalid"})
+
+		assert.False(t, annotator.Enabled())
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("NewAnnotator writes workflow commands on GitHub Actions", func(t *testing.T) {
+		t.Setenv("GITHUB_ACTIONS", "true")
+		var buf bytes.Buffer
+
+		annotator := logging.NewAnnotator(&buf)
+		annotator.Annotate(logging.Annotation{File: "components/x/y/conf.yaml", Line: 1, Message: "invalid"})
+
+		assert.True(t, annotator.Enabled())
+		assert.Equal(t, "::error file=components/x/y/conf.yaml,line=1::invalid\n", buf.String())
+	})
+}
@@ -0,0 +1,173 @@
+package manifestdiff
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"gitpkg/inventory"
+	"gitpkg/render"
+	"gitpkg/validate"
+)
+
+// Options configures a comparison of a component between two trees.
+type Options struct {
+	Component string
+	// Base and Head are the repository trees at the base and the PR ref, e.g. from qgit.Client.FS.
+	Base fs.FS
+	Head fs.FS
+	// BaseRef and HeadRef are only used to describe the trees in the output.
+	BaseRef string
+	HeadRef string
+	// Inventory lists the environments to compare.
+	Inventory *inventory.Inventory
+	// EnvironmentsRoot is the gitops-environments checkout holding the conf.yaml files.
+	// When set, only environments the component is deployed to are compared.
+	EnvironmentsRoot string
+	Charts           validate.ChartRenderer
+	// RegistryURL is rendered as CONTAINER_REGISTRY_URL.
+	RegistryURL string
+	// RenderOptions are applied to every values render, e.g. the vault datasources.
+	RenderOptions []render.Option
+	// Secrets tracks the values read from datasources, which are redacted from
+	// the changes and errors. A new set is used when nil.
+	Secrets *render.Secrets
+}
+
+// EnvironmentDiff is the diff of one environment. Err is set when either side
+// could not be rendered.
+type EnvironmentDiff struct {
+	Environment string
+	Version     string
+	Changes     []Change
+	Err         error
+}
+
+// Result is the diff of a component in every compared environment.
+type Result struct {
+	Component    string
+	BaseRef      string
+	HeadRef      string
+	Environments []EnvironmentDiff
+}
+
+// Changed returns the environments whose manifests change or could not be compared.
+func (r *Result) Changed() []EnvironmentDiff {
+	var changed []EnvironmentDiff
+	for _, env := range r.Environments {
+		if env.Err != nil || len(env.Changes) > 0 {
+			changed = append(changed, env)
+		}
+	}
+	return changed
+}
+
+// Compare renders the component at the base and the head for every environment
+// and diffs the resulting objects.
+func Compare(opts Options) (*Result, error) {
+	if opts.Inventory == nil {
+		return nil, fmt.Errorf("an inventory is required")
+	}
+	if opts.Charts == nil {
+		return nil, fmt.Errorf("a chart renderer is required")
+	}
+	if opts.RegistryURL == "" {
+		opts.RegistryURL = "registry.com"
+	}
+	if opts.Secrets == nil {
+		opts.Secrets = render.NewSecrets()
+	}
+	opts.RenderOptions = append(append([]render.Option{}, opts.RenderOptions...), render.WithSecrets(opts.Secrets))
+	file := path.Join("qcs", opts.Component, "values.yaml")
+	baseValues, err := readValues(opts.Base, file)
+	if err != nil {
+		return nil, err
+	}
+	headValues, err := readValues(opts.Head, file)
+	if err != nil {
+		return nil, err
+	}
+	if baseValues == "" && headValues == "" {
+		return nil, fmt.Errorf("%s exists neither at the base nor at the head", file)
+	}
+
+	result := &Result{Component: opts.Component, BaseRef: opts.BaseRef, HeadRef: opts.HeadRef}
+	for _, env := range opts.Inventory.List() {
+		if !env.Known() {
+			continue
+		}
+		diff := EnvironmentDiff{Environment: env.Name}
+		if opts.EnvironmentsRoot != "" {
+			conf, err := validate.ReadConf(filepath.Join(opts.EnvironmentsRoot, inventory.ConfPath(opts.Component, env.Name)))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			diff.Version = conf.Version
+		}
+		base, err := opts.objects(opts.Base, baseValues, env, diff.Version)
+		if err != nil {
+			diff.Err = fmt.Errorf("base: %w", err)
+		}
+		head, err := opts.objects(opts.Head, headValues, env, diff.Version)
+		if err != nil {
+			diff.Err = errors.Join(diff.Err, fmt.Errorf("head: %w", err))
+		}
+		if diff.Err == nil {
+			diff.Changes = Diff(base, head)
+		}
+		result.Environments = append(result.Environments, diff)
+	}
+	result.redact(opts.Secrets)
+	return result, nil
+}
+
+// redact replaces the values read from datasources in every change and error,
+// so values rendered outside of Secret objects do not leak into the comment.
+func (r *Result) redact(secrets *render.Secrets) {
+	for i := range r.Environments {
+		env := &r.Environments[i]
+		if env.Err != nil && secrets.Contains(env.Err.Error()) {
+			env.Err = errors.New(secrets.Redact(env.Err.Error()))
+		}
+		for j := range env.Changes {
+			for k := range env.Changes[j].Fields {
+				field := &env.Changes[j].Fields[k]
+				field.Old, field.New = secrets.Redact(field.Old), secrets.Redact(field.New)
+			}
+		}
+	}
+}
+
+// objects renders the values and the chart of one side. A component without
+// values has no objects.
+func (opts Options) objects(root fs.FS, values string, env inventory.Environment, version string) (map[string]Object, error) {
+	if values == "" {
+		return map[string]Object{}, nil
+	}
+	rendered, err := validate.RenderValues(root, opts.Component, values, env, opts.RegistryURL, opts.RenderOptions...)
+	if err != nil {
+		return nil, err
+	}
+	manifests, err := opts.Charts.Render(opts.Component, version, []byte(rendered))
+	if err != nil {
+		return nil, err
+	}
+	return ParseManifests(manifests)
+}
+
+func readValues(root fs.FS, file string) (string, error) {
+	data, err := fs.ReadFile(root, file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return string(data), nil
+}
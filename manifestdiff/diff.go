@@ -0,0 +1,90 @@
+package manifestdiff
+
+import (
+	"sort"
+)
+
+// ChangeType is the kind of change of an object.
+type ChangeType string
+
+const (
+	Added    ChangeType = "added"
+	Removed  ChangeType = "removed"
+	Modified ChangeType = "modified"
+)
+
+// FieldChange is an added, removed or modified leaf value.
+type FieldChange struct {
+	Type ChangeType
+	Path string
+	Old  string
+	New  string
+}
+
+// Change is an added, removed or modified object.
+type Change struct {
+	Type   ChangeType
+	Kind   string
+	ID     string
+	Fields []FieldChange
+}
+
+// Diff compares the objects rendered at the base and the head and returns the
+// changes sorted by kind and id. Secret values are replaced by Redacted, only
+// whether they changed is reported.
+func Diff(base, head map[string]Object) []Change {
+	var changes []Change
+	for key, h := range head {
+		b, ok := base[key]
+		if !ok {
+			changes = append(changes, Change{Type: Added, Kind: h.Kind, ID: h.ID()})
+			continue
+		}
+		if fields := diffFields(b, h); len(fields) > 0 {
+			changes = append(changes, Change{Type: Modified, Kind: h.Kind, ID: h.ID(), Fields: fields})
+		}
+	}
+	for key, b := range base {
+		if _, ok := head[key]; !ok {
+			changes = append(changes, Change{Type: Removed, Kind: b.Kind, ID: b.ID()})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind < changes[j].Kind
+		}
+		return changes[i].ID < changes[j].ID
+	})
+	return changes
+}
+
+func diffFields(base, head Object) []FieldChange {
+	var fields []FieldChange
+	for path, newValue := range head.Fields {
+		oldValue, ok := base.Fields[path]
+		switch {
+		case !ok:
+			fields = append(fields, FieldChange{Type: Added, Path: path, New: newValue})
+		case oldValue != newValue:
+			fields = append(fields, FieldChange{Type: Modified, Path: path, Old: oldValue, New: newValue})
+		}
+	}
+	for path, oldValue := range base.Fields {
+		if _, ok := head.Fields[path]; !ok {
+			fields = append(fields, FieldChange{Type: Removed, Path: path, Old: oldValue})
+		}
+	}
+	for i := range fields {
+		if !head.secret(fields[i].Path) && !base.secret(fields[i].Path) {
+			continue
+		}
+		if fields[i].Type != Added {
+			fields[i].Old = Redacted
+		}
+		if fields[i].Type != Removed {
+			fields[i].New = Redacted
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Path < fields[j].Path })
+	return fields
+}
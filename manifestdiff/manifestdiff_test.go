@@ -0,0 +1,192 @@
+package manifestdiff_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"gitpkg/inventory"
+	"gitpkg/manifestdiff"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestParseManifests(t *testing.T) {
+	objects, err := manifestdiff.ParseManifests(`---
+# Source: c/templates/cm.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: c
+  namespace: default
+data:
+  config.yaml: |
+    a: 1
+---
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: c
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+        - name: c
+          args: []
+`)
+
+	assert.NoError(t, err)
+	assert.Len(t, objects, 2)
+	cm := objects["ConfigMap default/c"]
+	assert.Equal(t, "default/c", cm.ID())
+	assert.Equal(t, "a: 1\n", cm.Fields["data.config.yaml"])
+	deployment := objects["Deployment c"]
+	assert.Equal(t, "2", deployment.Fields["spec.replicas"])
+	assert.Equal(t, "c", deployment.Fields["spec.template.spec.containers[0].name"])
+	assert.Equal(t, "[]", deployment.Fields["spec.template.spec.containers[0].args"])
+}
+
+func TestDiff(t *testing.T) {
+	base, err := manifestdiff.ParseManifests(`apiVersion: v1
+kind: Secret
+metadata: {name: s, namespace: ns}
+stringData:
+  password: old-password
+  user: admin
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata: {name: d}
+spec: {replicas: 2, paused: false}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata: {name: removed}
+`)
+	assert.NoError(t, err)
+	head, err := manifestdiff.ParseManifests(`apiVersion: v1
+kind: Secret
+metadata: {name: s, namespace: ns}
+stringData:
+  password: new-password
+  user: admin
+  token: new-token
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata: {name: d}
+spec: {replicas: 3}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata: {name: added}
+`)
+	assert.NoError(t, err)
+
+	changes := manifestdiff.Diff(base, head)
+
+	assert.Equal(t, []manifestdiff.Change{
+		{Type: manifestdiff.Added, Kind: "ConfigMap", ID: "added"},
+		{Type: manifestdiff.Removed, Kind: "ConfigMap", ID: "removed"},
+		{Type: manifestdiff.Modified, Kind: "Deployment", ID: "d", Fields: []manifestdiff.FieldChange{
+			{Type: manifestdiff.Removed, Path: "spec.paused", Old: "false"},
+			{Type: manifestdiff.Modified, Path: "spec.replicas", Old: "2", New: "3"},
+		}},
+		{Type: manifestdiff.Modified, Kind: "Secret", ID: "ns/s", Fields: []manifestdiff.FieldChange{
+			{Type: manifestdiff.Modified, Path: "stringData.password", Old: manifestdiff.Redacted, New: manifestdiff.Redacted},
+			{Type: manifestdiff.Added, Path: "stringData.token", New: manifestdiff.Redacted},
+		}},
+	}, changes)
+}
+
+// fakeCharts renders a Deployment with the replicas and a Secret with the
+// password of the values.
+type fakeCharts struct{}
+
+func (fakeCharts) Render(component, version string, values []byte) (string, error) {
+	var v struct {
+		Replicas int    `yaml:"replicas"`
+		Password string `yaml:"password"`
+	}
+	if err := yaml.Unmarshal(values, &v); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`apiVersion: apps/v1
+kind: Deployment
+metadata: {name: %[1]s}
+spec: {replicas: %[2]d}
+---
+apiVersion: v1
+kind: Secret
+metadata: {name: %[1]s}
+stringData: {password: %[3]s}
+`, component, v.Replicas, v.Password), nil
+}
+
+func TestCompare(t *testing.T) {
+	// Arrange
+	values := `{{ $replicas := "2" -}}
+{{- if eq (getenv "ENVIRONMENT") "prod" }}{{ $replicas = "%s" }}{{ end -}}
+replicas: {{ $replicas }}
+password: %s
+`
+	base := fstest.MapFS{"qcs/c/values.yaml": {Data: []byte(fmt.Sprintf(values, "2", "old-password"))}}
+	head := fstest.MapFS{"qcs/c/values.yaml": {Data: []byte(fmt.Sprintf(values, "3", "new-password"))}}
+	inv, err := inventory.Parse([]byte("pipeline-environments:\n  - qcs-stage-us-east-1\n  - qcs-prod-eu-west-1\n  - unknown\n"))
+	assert.NoError(t, err)
+
+	// Act
+	result, err := manifestdiff.Compare(manifestdiff.Options{
+		Component: "c",
+		Base:      base,
+		Head:      head,
+		BaseRef:   "refs/remotes/origin/main",
+		HeadRef:   "refs/pull/7/head",
+		Inventory: inv,
+		Charts:    fakeCharts{},
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, result.Environments, 2)
+	changed := result.Changed()
+	assert.Len(t, changed, 2)
+	assert.Len(t, changed[0].Changes, 1)
+	assert.Equal(t, "Secret", changed[0].Changes[0].Kind)
+	assert.Len(t, changed[1].Changes, 2)
+
+	var buf bytes.Buffer
+	assert.NoError(t, result.WriteMarkdown(&buf))
+	md := buf.String()
+	assert.True(t, strings.HasPrefix(md, manifestdiff.Marker("c")+"\n### Rendered manifest diff for `c`"))
+	assert.Contains(t, md, "2 of 2 environments change.")
+	assert.Contains(t, md, "<b>qcs-prod-eu-west-1</b>: 0 added, 0 removed, 2 modified")
+	assert.Contains(t, md, "! Deployment c\n-   spec.replicas: 2\n+   spec.replicas: 3\n")
+	assert.Contains(t, md, "-   stringData.password: "+manifestdiff.Redacted+"\n+   stringData.password: "+manifestdiff.Redacted+"\n")
+	assert.NotContains(t, md, "new-password")
+	assert.NotContains(t, md, "old-password")
+}
+
+func TestCompare_AddedComponent(t *testing.T) {
+	inv, err := inventory.Parse([]byte("pipeline-environments:\n  - qcs-stage-us-east-1\n"))
+	assert.NoError(t, err)
+
+	result, err := manifestdiff.Compare(manifestdiff.Options{
+		Component: "c",
+		Base:      fstest.MapFS{},
+		Head:      fstest.MapFS{"qcs/c/values.yaml": {Data: []byte("replicas: 1\npassword: p\n")}},
+		Inventory: inv,
+		Charts:    fakeCharts{},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []manifestdiff.Change{
+		{Type: manifestdiff.Added, Kind: "Deployment", ID: "c"},
+		{Type: manifestdiff.Added, Kind: "Secret", ID: "c"},
+	}, result.Environments[0].Changes)
+}
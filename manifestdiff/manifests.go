@@ -0,0 +1,121 @@
+package manifestdiff
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gitpkg/render"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Redacted replaces secret values in the diff.
+const Redacted = render.Redacted
+
+// Object is a rendered Kubernetes object.
+type Object struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+	// Fields maps the path of every leaf value, e.g. "spec.replicas", to its value.
+	Fields map[string]string
+}
+
+// Key identifies the object by kind, namespace and name.
+func (o Object) Key() string {
+	return o.Kind + " " + o.ID()
+}
+
+// ID is "namespace/name", or the name for objects without a namespace.
+func (o Object) ID() string {
+	if o.Namespace == "" {
+		return o.Name
+	}
+	return o.Namespace + "/" + o.Name
+}
+
+// secret reports whether the value at path must never be shown.
+func (o Object) secret(path string) bool {
+	switch o.Kind {
+	case "Secret":
+		return strings.HasPrefix(path, "data.") || strings.HasPrefix(path, "stringData.")
+	case "SealedSecret":
+		return strings.HasPrefix(path, "spec.encryptedData.")
+	}
+	return false
+}
+
+// ParseManifests parses a multi-document manifest stream into objects keyed by Key.
+// Empty documents are skipped; List objects are not expanded.
+func ParseManifests(stream string) (map[string]Object, error) {
+	objects := map[string]Object{}
+	dec := yaml.NewDecoder(strings.NewReader(stream))
+	for {
+		var doc map[string]interface{}
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse manifests: %w", err)
+		}
+		if len(doc) == 0 {
+			continue
+		}
+		obj := Object{Fields: map[string]string{}}
+		obj.APIVersion, _ = doc["apiVersion"].(string)
+		obj.Kind, _ = doc["kind"].(string)
+		if metadata, ok := doc["metadata"].(map[string]interface{}); ok {
+			obj.Name, _ = metadata["name"].(string)
+			obj.Namespace, _ = metadata["namespace"].(string)
+		}
+		if obj.Kind == "" || obj.Name == "" {
+			return nil, fmt.Errorf("manifest without kind or metadata.name: %v", doc)
+		}
+		flatten("", doc, obj.Fields)
+		if _, dup := objects[obj.Key()]; dup {
+			return nil, fmt.Errorf("duplicate object %s", obj.Key())
+		}
+		objects[obj.Key()] = obj
+	}
+}
+
+func flatten(prefix string, value interface{}, out map[string]string) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + "." + key
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 && prefix != "" {
+			out[prefix] = "{}"
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			flatten(join(k), v[k], out)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			out[prefix] = "[]"
+		}
+		for i, item := range v {
+			flatten(fmt.Sprintf("%s[%d]", prefix, i), item, out)
+		}
+	case nil:
+		out[prefix] = "null"
+	case string:
+		out[prefix] = v
+	default:
+		out[prefix] = fmt.Sprint(v)
+	}
+}
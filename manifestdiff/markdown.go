@@ -0,0 +1,110 @@
+package manifestdiff
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MaxCommentSize keeps the markdown below the GitHub comment size limit.
+// Longer diffs are written without the changed fields.
+const MaxCommentSize = 60000
+
+// Marker returns the hidden marker of the comment of a component, so a
+// workflow can find and update its previous comment.
+func Marker(component string) string {
+	return fmt.Sprintf("<!-- manifest-diff:%s -->", component)
+}
+
+// WriteMarkdown writes the diff as a PR comment: one collapsible section per
+// changed environment and a list of the unchanged ones.
+func (r *Result) WriteMarkdown(w io.Writer) error {
+	var buf bytes.Buffer
+	r.writeMarkdown(&buf, true)
+	if buf.Len() > MaxCommentSize {
+		buf.Reset()
+		r.writeMarkdown(&buf, false)
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (r *Result) writeMarkdown(w *bytes.Buffer, fields bool) {
+	fmt.Fprintln(w, Marker(r.Component))
+	fmt.Fprintf(w, "### Rendered manifest diff for `%s`\n\n", r.Component)
+	if r.BaseRef != "" && r.HeadRef != "" {
+		fmt.Fprintf(w, "Comparing `%s` with `%s`.\n\n", r.BaseRef, r.HeadRef)
+	}
+	changed := r.Changed()
+	if len(changed) == 0 {
+		fmt.Fprintf(w, "No rendered manifest changes in %d environments.\n", len(r.Environments))
+		return
+	}
+	fmt.Fprintf(w, "%d of %d environments change.\n\n", len(changed), len(r.Environments))
+	if !fields {
+		fmt.Fprintln(w, "_The diff is too large for a comment, only the changed objects are listed._")
+		fmt.Fprintln(w)
+	}
+
+	for _, env := range changed {
+		title := fmt.Sprintf("<b>%s</b>", env.Environment)
+		if env.Version != "" {
+			title += fmt.Sprintf(" (chart %s)", env.Version)
+		}
+		if env.Err != nil {
+			fmt.Fprintf(w, "<details open><summary>:x: %s: could not be compared</summary>\n\n```\n%s\n```\n\n</details>\n\n", title, env.Err)
+			continue
+		}
+		fmt.Fprintf(w, "<details><summary>%s: %s</summary>\n\n```diff\n", title, summary(env.Changes))
+		for _, c := range env.Changes {
+			writeChange(w, c, fields)
+		}
+		fmt.Fprint(w, "```\n\n</details>\n\n")
+	}
+
+	var unchanged []string
+	for _, env := range r.Environments {
+		if env.Err == nil && len(env.Changes) == 0 {
+			unchanged = append(unchanged, env.Environment)
+		}
+	}
+	if len(unchanged) > 0 {
+		fmt.Fprintf(w, "Unchanged: %s\n", strings.Join(unchanged, ", "))
+	}
+}
+
+func summary(changes []Change) string {
+	counts := map[ChangeType]int{}
+	for _, c := range changes {
+		counts[c.Type]++
+	}
+	return fmt.Sprintf("%d added, %d removed, %d modified", counts[Added], counts[Removed], counts[Modified])
+}
+
+func writeChange(w io.Writer, c Change, fields bool) {
+	switch c.Type {
+	case Added:
+		fmt.Fprintf(w, "+ %s %s\n", c.Kind, c.ID)
+	case Removed:
+		fmt.Fprintf(w, "- %s %s\n", c.Kind, c.ID)
+	default:
+		fmt.Fprintf(w, "! %s %s\n", c.Kind, c.ID)
+		if !fields {
+			return
+		}
+		for _, f := range c.Fields {
+			if f.Type != Added {
+				fmt.Fprintf(w, "-   %s: %s\n", f.Path, oneLine(f.Old))
+			}
+			if f.Type != Removed {
+				fmt.Fprintf(w, "+   %s: %s\n", f.Path, oneLine(f.New))
+			}
+		}
+	}
+}
+
+// oneLine keeps multi-line values, e.g. embedded config files, on a single diff line.
+func oneLine(s string) string {
+	return strings.ReplaceAll(strings.TrimRight(s, "\n"), "\n", `\n`)
+}
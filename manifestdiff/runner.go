@@ -0,0 +1,81 @@
+package manifestdiff
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gitpkg/render"
+	"gitpkg/revisions"
+	"gitpkg/validate"
+)
+
+// Setup registers the flags of the manifest-diff command; the command writes the markdown
+// diff of every component to --output, or stdout.
+func Setup(fs *flag.FlagSet) func(args []string) error {
+	var change revisions.Options
+	change.BindFlags(fs)
+	components := fs.String("components", "", "Comma separated components to diff")
+	chartDir := fs.String("chart-dir", "", "Directory of unpacked charts as <dir>/<component>")
+	chartCache := fs.String("chart-cache", "", "Directory of pulled charts as <dir>/<component>-<version>.tgz")
+	kubeVersion := fs.String("kube-version", "1.25.0", "Kubernetes version used for templating")
+	output := fs.String("output", "", "Write the markdown to this file instead of stdout")
+	return func(args []string) error {
+		if *components == "" {
+			return fmt.Errorf("--components is required")
+		}
+		trees, err := change.Load()
+		if err != nil {
+			return err
+		}
+		opts := Options{
+			Base:             trees.Base,
+			Head:             trees.Head,
+			BaseRef:          trees.BaseRef,
+			HeadRef:          trees.HeadRef,
+			Inventory:        trees.Inventory,
+			EnvironmentsRoot: trees.EnvironmentsRoot,
+			RegistryURL:      trees.RegistryURL,
+			RenderOptions:    trees.RenderOptions,
+			Secrets:          render.NewSecrets(),
+			Charts: &validate.HelmRenderer{
+				ChartDir:    *chartDir,
+				ChartCache:  *chartCache,
+				KubeVersion: *kubeVersion,
+				APIVersions: validate.DefaultAPIVersions,
+			},
+		}
+
+		var results []*Result
+		for _, component := range strings.Split(*components, ",") {
+			opts.Component = component
+			result, err := Compare(opts)
+			if err != nil {
+				return fmt.Errorf("failed to diff %s: %w", component, err)
+			}
+			results = append(results, result)
+		}
+
+		// the masks must not end up in the markdown when it is written to stdout
+		var w, masks io.Writer = os.Stdout, os.Stdout
+		if *output != "" {
+			f, err := os.Create(*output)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", *output, err)
+			}
+			defer f.Close()
+			w = f
+		} else {
+			masks = os.Stderr
+		}
+		opts.Secrets.WriteMasks(masks)
+		for _, result := range results {
+			if err := result.WriteMarkdown(w); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
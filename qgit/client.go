@@ -2,11 +2,14 @@ package qgit
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 
+	"gitpkg/logging"
+
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -30,26 +33,31 @@ func NewClient(opts ...Option) (*Client, error) {
 	return &Client{opts: options}, nil
 }
 
+// log returns the logger of the client.
+func (c *Client) log() *slog.Logger {
+	return logging.OrDefault(c.opts.Logger)
+}
+
 // InitRepo clones the repo from remote if it does not exist locally
 func (c *Client) InitRepo() (err error) {
 	gitDir := filepath.Join(c.opts.RepoPath, ".git")
 	if _, err := os.Stat(gitDir); os.IsNotExist(err) {
-		fmt.Println("Repository does not exist locally. Cloning...")
+		c.log().Info("repository does not exist locally, cloning", "path", c.opts.RepoPath, "url", c.opts.RepoUrl)
 		err = c.Clone()
 		if err != nil {
 			return fmt.Errorf("error cloning repository: %w", err)
 		}
-		fmt.Println("Repository cloned successfully.")
+		c.log().Info("repository cloned", "path", c.opts.RepoPath)
 	}
 	if err != nil {
 		return fmt.Errorf("error checking repository: %w", err)
 	}
 
-	fmt.Println("Repository exists locally. Opening...")
+	c.log().Debug("opening repository", "path", c.opts.RepoPath)
 	if err = c.Open(); err != nil {
 		return fmt.Errorf("error opening repository: %w", err)
 	}
-	fmt.Println("Repository opened successfully.")
+	c.log().Debug("repository opened", "path", c.opts.RepoPath)
 	return nil
 }
 
@@ -80,8 +88,7 @@ func (c *Client) Open() (err error) {
 func (c *Client) Checkout(ref string) error {
 	isBranch, isTag, isCommitHash, err := c.CheckLocalRef(ref)
 	if err != nil {
-		fmt.Printf("%v", fmt.Errorf("failed to resolve ref locally: %w", err))
-		fmt.Printf("Checking remote refs")
+		c.log().Debug("ref not found locally, checking remote refs", "ref", ref, "error", err)
 		isBranch, isTag, isCommitHash, err = c.CheckRemoteRef(ref)
 		if err != nil {
 			return fmt.Errorf("failed to resolved ref on remote: %w", err)
@@ -92,12 +99,12 @@ func (c *Client) Checkout(ref string) error {
 	checkoutOpts := git.CheckoutOptions{}
 	switch {
 	case isBranch:
-		fmt.Println("Checking out branch:", ref)
+		c.log().Info("checking out branch", "ref", ref)
 		checkoutOpts = git.CheckoutOptions{
 			Branch: plumbing.NewBranchReferenceName(ref),
 		}
 	case isTag:
-		fmt.Println("Checking out tag:", ref)
+		c.log().Info("checking out tag", "ref", ref)
 		ref, err := c.repo.Tag(ref)
 		if err != nil {
 			return fmt.Errorf("failed to get tag reference: %w", err)
@@ -106,12 +113,12 @@ func (c *Client) Checkout(ref string) error {
 			Hash: ref.Hash(),
 		}
 	case isCommitHash:
-		fmt.Println("Checking out commit hash:", ref)
+		c.log().Info("checking out commit", "ref", ref)
 		checkoutOpts = git.CheckoutOptions{
 			Hash: plumbing.NewHash(ref),
 		}
 	default:
-		return fmt.Errorf("reference not found: %s", ref)
+		return fmt.Errorf("%w: %s", plumbing.ErrReferenceNotFound, ref)
 	}
 	return c.checkout(&checkoutOpts)
 }
@@ -169,6 +176,16 @@ func (c *Client) Fetch(ref string) error {
 	return nil
 }
 
+// FetchPullRequest fetches the head of a pull request into refs/pull/<prNumber>/head
+// and returns that reference name.
+func (c *Client) FetchPullRequest(prNumber int) (string, error) {
+	prRef := fmt.Sprintf("refs/pull/%d/head", prNumber)
+	if err := c.Fetch(fmt.Sprintf("+%s:%s", prRef, prRef)); err != nil {
+		return "", fmt.Errorf("failed to fetch remote branch %s: %w", prRef, err)
+	}
+	return prRef, nil
+}
+
 func (c *Client) GetChangedFilesByPRNumber(prNumber int) (changedFiles []string, err error) {
 
 	if err != nil {
@@ -317,19 +334,13 @@ func (c *Client) FileContentFromCommit(commitHash, file string) (content string,
 // FileContentFromBranch retrieves the content of a specified file from the latest commit on a given branch.
 //
 // Parameters:
-//   - branch: The branch name from which to retrieve the file (e.g., "main").
+//   - branch: The full reference name of the branch (e.g., "refs/heads/main") or a commit hash.
 //   - file: The path of the file whose content needs to be retrieved.
 
 func (gr *Client) FileContentFromBranch(branch, file string) (content string, err error) {
 
-	// Get the reference to the specified branch
-	ref, err := gr.repo.Reference(plumbing.ReferenceName(branch), true)
-	if err != nil {
-		return "", err
-	}
-
 	// Get the commit object for the latest commit on the branch
-	commit, err := gr.repo.CommitObject(ref.Hash())
+	commit, err := gr.commit(branch)
 	if err != nil {
 		return "", err
 	}
@@ -356,6 +367,33 @@ func (gr *Client) FileContentFromBranch(branch, file string) (content string, er
 	return content, nil
 }
 
+// HasRef reports whether a full reference name, e.g. "refs/heads/main",
+// exists in the local repository.
+func (c *Client) HasRef(name string) bool {
+	_, err := c.repo.Reference(plumbing.ReferenceName(name), true)
+	return err == nil
+}
+
+// ErrFileNotFound is returned when reading a file missing from the tree of a
+// commit or branch.
+var ErrFileNotFound = object.ErrFileNotFound
+
+// ParentCommit returns the hash of the first parent of the commit a reference
+// points to, or an empty hash for a root commit.
+//
+// Parameters:
+//   - ref: A full reference name (e.g., "refs/heads/main") or a commit hash.
+func (c *Client) ParentCommit(ref string) (string, error) {
+	commit, err := c.commit(ref)
+	if err != nil {
+		return "", err
+	}
+	if commit.NumParents() == 0 {
+		return "", nil
+	}
+	return commit.ParentHashes[0].String(), nil
+}
+
 func (c *Client) changedFiles(base, current string) (*object.Changes, error) {
 	baseHashStr, _, _, _, err := c.resolveRef(base)
 	if err != nil {
@@ -367,11 +405,11 @@ func (c *Client) changedFiles(base, current string) (*object.Changes, error) {
 	}
 	baseCommit, err := c.repo.CommitObject(plumbing.NewHash(baseHashStr))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get commit for base ref: %s err:%v", base, err)
+		return nil, fmt.Errorf("failed to get commit for base ref %s: %w", base, err)
 	}
 	currentCommit, err := c.repo.CommitObject(plumbing.NewHash(currentHashStr))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get commit for current ref: %s err:%v", current, err)
+		return nil, fmt.Errorf("failed to get commit for current ref %s: %w", current, err)
 	}
 
 	// Get the trees for the commits
@@ -408,6 +446,71 @@ func (c *Client) ChangedFiles(base, current string) (changedFiles []string, err
 	return
 }
 
+// ChangedFilesFromMergeBase returns the files changed on head since it
+// diverged from base, which are the files of a pull request from head into base.
+//
+// Parameters:
+//   - base: A full reference name (e.g., "refs/remotes/origin/main") or a commit hash.
+//   - head: A full reference name (e.g., "refs/pull/12/head") or a commit hash.
+func (c *Client) ChangedFilesFromMergeBase(base, head string) ([]string, error) {
+	mergeBase, headCommit, err := c.mergeBase(base, head)
+	if err != nil {
+		return nil, err
+	}
+	mergeBaseTree, err := mergeBase.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merge base tree: %w", err)
+	}
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get head commit tree: %w", err)
+	}
+	changes, err := object.DiffTree(mergeBaseTree, headTree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff commits: %w", err)
+	}
+	var changedFiles []string
+	for _, change := range changes {
+		name := change.To.Name
+		if name == "" {
+			name = change.From.Name
+		}
+		changedFiles = append(changedFiles, name)
+	}
+	return changedFiles, nil
+}
+
+// MergeBase returns the hash of the best common ancestor of base and head,
+// where head diverged from base. base and head are full reference names or
+// commit hashes.
+func (c *Client) MergeBase(base, head string) (string, error) {
+	mergeBase, _, err := c.mergeBase(base, head)
+	if err != nil {
+		return "", err
+	}
+	return mergeBase.Hash.String(), nil
+}
+
+// mergeBase returns the merge base of base and head, and the head commit.
+func (c *Client) mergeBase(base, head string) (*object.Commit, *object.Commit, error) {
+	baseCommit, err := c.commit(base)
+	if err != nil {
+		return nil, nil, err
+	}
+	headCommit, err := c.commit(head)
+	if err != nil {
+		return nil, nil, err
+	}
+	bases, err := baseCommit.MergeBase(headCommit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find the merge base of %s and %s: %w", base, head, err)
+	}
+	if len(bases) == 0 {
+		return nil, nil, fmt.Errorf("%s and %s have no common ancestor", base, head)
+	}
+	return bases[0], headCommit, nil
+}
+
 // ChangedFilesByFilter returns the changed filepaths between the base ref and the current ref, matching the given filter.
 func (c *Client) ChangedFilesByFilter(base, current string, filter func(string) bool) ([]string, error) {
 	changedFiles, err := c.ChangedFiles(base, current)
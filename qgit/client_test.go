@@ -0,0 +1,59 @@
+package qgit_test
+
+import (
+	"testing"
+
+	"gitpkg/qgit"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_ChangedFilesFromMergeBase(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	assert.NoError(t, err)
+	base := commitFiles(t, repo, dir, map[string]string{"a.yaml": "a: 1\n", "b.yaml": "b: 1\n"})
+	pr := commitFiles(t, repo, dir, map[string]string{"components/c/env/conf.yaml": "version: 1.0.0\n"})
+	assert.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference("refs/pull/1/head", plumbing.NewHash(pr))))
+	wt, err := repo.Worktree()
+	assert.NoError(t, err)
+	assert.NoError(t, wt.Reset(&git.ResetOptions{Commit: plumbing.NewHash(base), Mode: git.HardReset}))
+	main := commitFiles(t, repo, dir, map[string]string{"b.yaml": "b: 2\n"})
+
+	client, err := qgit.NewClient(qgit.WithRepoPath(dir))
+	assert.NoError(t, err)
+	assert.NoError(t, client.Open())
+
+	t.Run("ChangedFilesFromMergeBase ignores changes of the base made after the head diverged", func(t *testing.T) {
+		// Act
+		files, err := client.ChangedFilesFromMergeBase(main, "refs/pull/1/head")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"components/c/env/conf.yaml"}, files)
+	})
+
+	t.Run("MergeBase returns the commit the head diverged from", func(t *testing.T) {
+		mergeBase, err := client.MergeBase(main, "refs/pull/1/head")
+
+		assert.NoError(t, err)
+		assert.Equal(t, base, mergeBase)
+	})
+
+	t.Run("ParentCommit returns the first parent of a reference", func(t *testing.T) {
+		parent, err := client.ParentCommit("refs/pull/1/head")
+
+		assert.NoError(t, err)
+		assert.Equal(t, base, parent)
+	})
+
+	t.Run("ParentCommit returns the first parent of a commit hash", func(t *testing.T) {
+		parent, err := client.ParentCommit(main)
+
+		assert.NoError(t, err)
+		assert.Equal(t, base, parent)
+	})
+}
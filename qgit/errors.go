@@ -0,0 +1,64 @@
+package qgit
+
+import (
+	"errors"
+	"net"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// Kind classifies the failures of git operations, so callers can tell a
+// missing token from a missing branch or an unreachable remote.
+type Kind string
+
+const (
+	// KindAuth is a remote rejecting the credentials, or requiring some.
+	KindAuth Kind = "auth"
+	// KindRefNotFound is a branch, tag, commit or pull request that does
+	// not exist locally or on the remote.
+	KindRefNotFound Kind = "ref-not-found"
+	// KindNetwork is a remote that could not be reached.
+	KindNetwork Kind = "network"
+)
+
+// KindOf returns the kind of a failure returned by the client, or an empty
+// kind when the failure is not one of the known kinds.
+func KindOf(err error) Kind {
+	if err == nil {
+		return ""
+	}
+	// go-git wraps transport failures without unwrapping them
+	var unexpected *plumbing.UnexpectedError
+	if errors.As(err, &unexpected) {
+		if kind := KindOf(unexpected.Err); kind != "" {
+			return kind
+		}
+	}
+	var permanent *plumbing.PermanentError
+	if errors.As(err, &permanent) {
+		if kind := KindOf(permanent.Err); kind != "" {
+			return kind
+		}
+	}
+
+	switch {
+	// GitHub answers requests for private repositories without a valid
+	// token as if the repository did not exist.
+	case errors.Is(err, transport.ErrAuthenticationRequired),
+		errors.Is(err, transport.ErrAuthorizationFailed),
+		errors.Is(err, transport.ErrInvalidAuthMethod),
+		errors.Is(err, transport.ErrRepositoryNotFound):
+		return KindAuth
+	case errors.Is(err, plumbing.ErrReferenceNotFound),
+		errors.Is(err, plumbing.ErrObjectNotFound),
+		errors.Is(err, git.NoMatchingRefSpecError{}):
+		return KindRefNotFound
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return KindNetwork
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+package qgit_test
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+
+	"gitpkg/qgit"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKindOf(t *testing.T) {
+	dial := &url.Error{Op: "Get", URL: "https://github.com/org/repo", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}
+	tests := []struct {
+		name string
+		err  error
+		want qgit.Kind
+	}{
+		{"KindOf classifies rejected credentials", fmt.Errorf("fetch origin failed: %w", transport.ErrAuthenticationRequired), qgit.KindAuth},
+		{"KindOf classifies hidden private repositories", fmt.Errorf("clone: %w", transport.ErrRepositoryNotFound), qgit.KindAuth},
+		{"KindOf classifies missing refs", fmt.Errorf("failed to resolve ref main: %w", plumbing.ErrReferenceNotFound), qgit.KindRefNotFound},
+		{"KindOf classifies missing commits", fmt.Errorf("failed to get commit object: %w", plumbing.ErrObjectNotFound), qgit.KindRefNotFound},
+		{"KindOf classifies unreachable remotes", fmt.Errorf("fetch origin failed: %w", plumbing.NewUnexpectedError(dial)), qgit.KindNetwork},
+		{"KindOf leaves other failures unclassified", errors.New("failed to get repo work tree"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, qgit.KindOf(tt.err))
+		})
+	}
+}
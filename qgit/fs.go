@@ -0,0 +1,143 @@
+package qgit
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// FS returns a read-only file system of the tree at the given reference, so
+// files can be read at a ref without checking it out.
+//
+// Parameters:
+//   - ref: A full reference name (e.g., "refs/pull/12/head", "refs/remotes/origin/main") or a commit hash.
+func (c *Client) FS(ref string) (fs.FS, error) {
+	commit, err := c.commit(ref)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit tree: %w", err)
+	}
+	return &treeFS{tree: tree, modTime: commit.Committer.When}, nil
+}
+
+// commit returns the commit of a full reference name or a commit hash.
+func (c *Client) commit(ref string) (*object.Commit, error) {
+	hash := plumbing.NewHash(ref)
+	if r, err := c.repo.Reference(plumbing.ReferenceName(ref), true); err == nil {
+		hash = r.Hash()
+	} else if len(ref) != 40 {
+		return nil, fmt.Errorf("failed to resolve ref %s: %w", ref, err)
+	}
+	commit, err := c.repo.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit for ref %s: %w", ref, err)
+	}
+	return commit, nil
+}
+
+// treeFS implements fs.FS on top of a git tree.
+type treeFS struct {
+	tree    *object.Tree
+	modTime time.Time
+}
+
+func (t *treeFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return &treeDir{fs: t, name: ".", tree: t.tree}, nil
+	}
+	entry, err := t.tree.FindEntry(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if entry.Mode == filemode.Dir {
+		sub, err := t.tree.Tree(name)
+		if err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		return &treeDir{fs: t, name: name, tree: sub}, nil
+	}
+	file, err := t.tree.File(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &treeFile{ReadCloser: reader, info: fileInfo{name: path.Base(name), size: file.Size, mode: 0444, modTime: t.modTime}}, nil
+}
+
+type treeFile struct {
+	io.ReadCloser
+	info fileInfo
+}
+
+func (f *treeFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+type treeDir struct {
+	fs     *treeFS
+	name   string
+	tree   *object.Tree
+	offset int
+}
+
+func (d *treeDir) Stat() (fs.FileInfo, error) {
+	return fileInfo{name: path.Base(d.name), mode: fs.ModeDir | 0555, modTime: d.fs.modTime}, nil
+}
+
+func (d *treeDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: fs.ErrInvalid}
+}
+
+func (d *treeDir) Close() error { return nil }
+
+// ReadDir returns the entries of the directory in tree order, which is sorted by name.
+func (d *treeDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	entries := d.tree.Entries[d.offset:]
+	if n > 0 && len(entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > 0 && len(entries) > n {
+		entries = entries[:n]
+	}
+	d.offset += len(entries)
+	out := make([]fs.DirEntry, 0, len(entries))
+	for _, e := range entries {
+		info := fileInfo{name: e.Name, mode: 0444, modTime: d.fs.modTime}
+		if e.Mode == filemode.Dir {
+			info.mode = fs.ModeDir | 0555
+		} else if size, err := d.tree.Size(e.Name); err == nil {
+			info.size = size
+		}
+		out = append(out, fs.FileInfoToDirEntry(info))
+	}
+	return out, nil
+}
+
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i fileInfo) Name() string       { return i.name }
+func (i fileInfo) Size() int64        { return i.size }
+func (i fileInfo) Mode() fs.FileMode  { return i.mode }
+func (i fileInfo) ModTime() time.Time { return i.modTime }
+func (i fileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i fileInfo) Sys() interface{}   { return nil }
+
+var _ fs.ReadDirFile = (*treeDir)(nil)
@@ -0,0 +1,105 @@
+package qgit_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"gitpkg/qgit"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// commitFiles writes the files into the work tree of repo and commits them.
+func commitFiles(t *testing.T, repo *git.Repository, dir string, files map[string]string) string {
+	t.Helper()
+	wt, err := repo.Worktree()
+	assert.NoError(t, err)
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		assert.NoError(t, os.WriteFile(file, []byte(content), 0644))
+		_, err := wt.Add(name)
+		assert.NoError(t, err)
+	}
+	hash, err := wt.Commit("update", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	assert.NoError(t, err)
+	return hash.String()
+}
+
+func TestClient_FS(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	assert.NoError(t, err)
+	first := commitFiles(t, repo, dir, map[string]string{
+		"qcs/c/values.yaml": "replicas: 1\n",
+		"qcs/c/sealed-secrets/stage/us-east-1/sealed-secrets.yaml": "sealedSecrets: {}\n",
+	})
+	commitFiles(t, repo, dir, map[string]string{"qcs/c/values.yaml": "replicas: 2\n"})
+	head, err := repo.Head()
+	assert.NoError(t, err)
+
+	client, err := qgit.NewClient(qgit.WithRepoPath(dir))
+	assert.NoError(t, err)
+	assert.NoError(t, client.Open())
+
+	t.Run("FS reads files at a reference", func(t *testing.T) {
+		tree, err := client.FS(head.Name().String())
+		assert.NoError(t, err)
+
+		data, err := fs.ReadFile(tree, "qcs/c/values.yaml")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "replicas: 2\n", string(data))
+	})
+
+	t.Run("FS reads files at a commit", func(t *testing.T) {
+		tree, err := client.FS(first)
+		assert.NoError(t, err)
+
+		data, err := fs.ReadFile(tree, "qcs/c/values.yaml")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "replicas: 1\n", string(data))
+	})
+
+	t.Run("FS lists directories and reports missing files", func(t *testing.T) {
+		tree, err := client.FS(first)
+		assert.NoError(t, err)
+
+		entries, err := fs.ReadDir(tree, "qcs/c")
+		assert.NoError(t, err)
+		assert.Len(t, entries, 2)
+		assert.Equal(t, "sealed-secrets", entries[0].Name())
+		assert.True(t, entries[0].IsDir())
+		assert.Equal(t, "values.yaml", entries[1].Name())
+
+		matches, err := fs.Glob(tree, "qcs/*/sealed-secrets/*/*/sealed-secrets.yaml")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"qcs/c/sealed-secrets/stage/us-east-1/sealed-secrets.yaml"}, matches)
+
+		_, err = fs.ReadFile(tree, "qcs/missing/values.yaml")
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("FS satisfies the fs.FS contract", func(t *testing.T) {
+		tree, err := client.FS(first)
+		assert.NoError(t, err)
+
+		assert.NoError(t, fstest.TestFS(tree, "qcs/c/values.yaml", "qcs/c/sealed-secrets/stage/us-east-1/sealed-secrets.yaml"))
+	})
+
+	t.Run("FS fails for unknown references", func(t *testing.T) {
+		_, err := client.FS("refs/pull/1/head")
+
+		assert.Error(t, err)
+	})
+}
@@ -1,12 +1,29 @@
 package qgit
 
+import (
+	"log/slog"
+
+	"gitpkg/logging"
+)
+
 // Options required for setting up the git client.
 type Options struct {
 	RepoPath string
 	RepoUrl  string
 	Username string
 	Token    string
-	//TODO Logger   *logrus.Logger
+	// Logger receives the progress of the client, slog.Default() when nil.
+	Logger *slog.Logger
+}
+
+// LogValue logs the options with the token redacted.
+func (o Options) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("repoPath", o.RepoPath),
+		slog.String("repoUrl", o.RepoUrl),
+		slog.String("username", o.Username),
+		slog.String("token", logging.Mask(o.Token)),
+	)
 }
 
 type Option func(*Options) error
@@ -18,13 +35,13 @@ func GetDefaultOptions() Options {
 	}
 }
 
-// TODO WithLogger is an Option to set a logger to be used by the Client.
-// func WithLogger(logger *Logger.Log) Option {
-// 	return func(opt *Options) error {
-// 		opt.Logger = logger
-// 		return nil
-// 	}
-// }
+// WithLogger is an Option to set a logger to be used by the Client.
+func WithLogger(logger *slog.Logger) Option {
+	return func(opt *Options) error {
+		opt.Logger = logger
+		return nil
+	}
+}
 
 // WithRepoPath is an Option to set the local repo path
 func WithRepoPath(path string) Option {
@@ -58,7 +75,6 @@ func WithToken(token string) Option {
 	}
 }
 
-
 func compileOptions(opts ...Option) (*Options, error) {
 	options := GetDefaultOptions()
 	for _, opt := range opts {
@@ -1,7 +1,7 @@
 package qgit_2
 
 import (
-	"fmt"
+	"log/slog"
 	"os"
 )
 
@@ -9,10 +9,12 @@ import (
 // It clones or opens a Git repository, checks out a reference, and performs some basic operations.
 func Runner(url, directory, ref, token string) {
 	// Step 1: Set up repository options
+	logger := slog.Default()
 	options := QRepoOptions{
-		Url:   url,       // Git repository URL
-		Path:  directory, // Local directory path to clone or open the repository
-		Token: token,     // Authentication token (e.g., GitHub personal access token)
+		Url:    url,       // Git repository URL
+		Path:   directory, // Local directory path to clone or open the repository
+		Token:  token,     // Authentication token (e.g., GitHub personal access token)
+		Logger: logger,    // Logger receiving the progress, the token is redacted
 	}
 
 	// Step 2: Initialize the repository using qgit
@@ -21,27 +23,27 @@ func Runner(url, directory, ref, token string) {
 
 	// Step 3: Perform the checkout operation to switch to the given reference (branch, tag, or commit)
 	if err := qGit.Checkout(ref); err != nil {
-		fmt.Println("Error checking out reference:", err)
+		logger.Error("error checking out reference", "ref", ref, "error", err)
 		os.Exit(1) // Exit if there's an error during checkout
 	}
-	fmt.Printf("Checked out reference: %s\n", ref)
+	logger.Info("checked out reference", "ref", ref)
 
 	// Step 4: Retrieve the current HEAD reference and print its commit hash
 	refInfo, err := qGit.Head()
 	if err != nil {
-		fmt.Println("Error getting HEAD reference:", err)
+		logger.Error("error getting HEAD reference", "error", err)
 		os.Exit(1) // Exit if there's an error retrieving the HEAD reference
 	}
-	fmt.Printf("Current HEAD Commit: %s\n", refInfo.Hash)
+	logger.Info("current HEAD commit", "hash", refInfo.Hash)
 
 	// Step 5: Retrieve the list of files ending with "conf.yaml" from a pull request (PR) number
 	prNumber := 37 // Example PR number
 	files, err := qGit.GetConfFileChangedByPRNumber(prNumber)
 	if err != nil {
-		fmt.Printf("Error retrieving changed files: %v\n", err)
+		logger.Error("error retrieving changed files", "error", err)
 		os.Exit(1) // Exit if there's an error retrieving the files
 	}
-	fmt.Printf("Changed files in PR #%d ending with 'conf.yaml': %v\n", prNumber, files)
+	logger.Info("changed conf.yaml files", "pr", prNumber, "files", files)
 }
 
 // func main() {
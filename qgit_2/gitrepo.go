@@ -2,9 +2,12 @@ package qgit_2
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 
+	"gitpkg/logging"
+
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -44,21 +47,21 @@ func getRepo(options QRepoOptions) (git *git.Repository, err error) {
 
 	// Call the Stat method from the interface to check if the repository exists
 	if _, err := Stat(gitDir); os.IsNotExist(err) {
-		fmt.Println("Repository does not exist locally. Cloning...")
+		options.log().Info("repository does not exist locally, cloning", "path", options.Path, "url", options.Url)
 		git, err = plainClone(options)
 		if err != nil {
 			return nil, fmt.Errorf("error cloning repository: %w", err)
 		}
-		fmt.Println("Repository cloned successfully.")
+		options.log().Info("repository cloned", "path", options.Path)
 	} else if err != nil {
 		return nil, fmt.Errorf("error checking repository: %w", err)
 	} else {
-		fmt.Println("Repository exists locally. Opening...")
+		options.log().Debug("opening repository", "path", options.Path)
 		git, err = plainOpen(options)
 		if err != nil {
 			return nil, fmt.Errorf("error opening repository: %w", err)
 		}
-		fmt.Println("Repository opened successfully.")
+		options.log().Debug("repository opened", "path", options.Path)
 	}
 	return git, nil
 }
@@ -77,6 +80,25 @@ type QRepoOptions struct {
 	Path  string
 	Url   string
 	Token string
+	// Logger receives the progress of the repository, slog.Default() when nil.
+	Logger *slog.Logger
+}
+
+// LogValue logs the options with the token redacted.
+func (o QRepoOptions) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("path", o.Path),
+		slog.String("url", o.Url),
+		slog.String("token", logging.Mask(o.Token)),
+	)
+}
+
+// log returns the logger of the options; nil options log to slog.Default().
+func (o *QRepoOptions) log() *slog.Logger {
+	if o == nil {
+		return slog.Default()
+	}
+	return logging.OrDefault(o.Logger)
 }
 
 // QRepoCheckoutOptions provides options for checking out a Git reference, including branches, tags, or commit hashes.
@@ -124,13 +146,13 @@ func (gr *QGitRepo) Checkout(ref string) error {
 	}
 	switch {
 	case isBranch:
-		fmt.Println("Checking out branch:", ref)
+		gr.Option().log().Info("checking out branch", "ref", ref)
 		return gr.CheckoutBranch(ref)
 	case isTag:
-		fmt.Println("Checking out tag:", ref)
+		gr.Option().log().Info("checking out tag", "ref", ref)
 		return gr.CheckoutTag(ref)
 	case isCommitHash:
-		fmt.Println("Checking out commit hash:", ref)
+		gr.Option().log().Info("checking out commit", "ref", ref)
 		return gr.CheckoutHash(ref)
 	default:
 		return fmt.Errorf("reference not found: %s", ref)
@@ -389,7 +411,6 @@ func (gr *QGitRepo) convertToQReferences(refs []*plumbing.Reference) []*QReferen
 func (gr *QGitRepo) classifyRef(ref string, refs []*QReference) (isBranch, isTag, isCommitHash bool, err error) {
 	repo, err := getRepo(*gr.Option())
 	if err != nil {
-		//fmt.Printf("Error conneting to repo: %w\n", err)
 		return
 	}
 
@@ -408,7 +429,7 @@ func (gr *QGitRepo) classifyRef(ref string, refs []*QReference) (isBranch, isTag
 		if err == nil {
 			isCommitHash = true
 		} else {
-			fmt.Printf("error fetching commit object: %v\n", err)
+			gr.Option().log().Debug("ref is not a commit hash", "ref", ref, "error", err)
 		}
 	}
 	return isBranch, isTag, isCommitHash, err
@@ -96,13 +96,13 @@ func (gr *Qgit) Checkout(ref string) error {
 	// Perform the appropriate checkout operation based on the reference type
 	switch {
 	case isBranch:
-		fmt.Println("Checking out branch:", ref)
+		gr.Option().log().Info("checking out branch", "ref", ref)
 		return gr.Repo().CheckoutBranch(ref)
 	case isTag:
-		fmt.Println("Checking out tag:", ref)
+		gr.Option().log().Info("checking out tag", "ref", ref)
 		return gr.Repo().CheckoutTag(ref)
 	case isCommitHash:
-		fmt.Println("Checking out commit hash:", ref)
+		gr.Option().log().Info("checking out commit", "ref", ref)
 		return gr.Repo().CheckoutHash(ref)
 	default:
 		return fmt.Errorf("reference not found: %s", ref)
@@ -0,0 +1,109 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Datasource provides the data returned by the gomplate `ds` and `datasource` functions.
+type Datasource interface {
+	// Read returns the parsed document for the given key. The key is empty when
+	// the template calls `ds "name"` without a sub path.
+	Read(key string) (interface{}, error)
+}
+
+// DirDatasource mirrors gomplate's `file:///dir/?type=application/json` datasources:
+// every key is a file inside Dir holding a JSON (or YAML) document.
+type DirDatasource struct {
+	Dir string
+	// MimeType selects the parser, "application/json" when empty.
+	MimeType string
+}
+
+// Read parses the file named after key in the datasource directory.
+func (d *DirDatasource) Read(key string) (interface{}, error) {
+	file := d.Dir
+	if key != "" {
+		file = filepath.Join(d.Dir, filepath.FromSlash(key))
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read datasource file: %w", err)
+	}
+	return parseDocument(data, d.MimeType)
+}
+
+// MapDatasource is an in-memory datasource keyed by the datasource sub path.
+type MapDatasource map[string]interface{}
+
+// Read returns the value stored under key.
+func (m MapDatasource) Read(key string) (interface{}, error) {
+	value, ok := m[key]
+	if !ok {
+		return nil, fmt.Errorf("key %q not found", key)
+	}
+	return value, nil
+}
+
+// ParseDatasource parses a gomplate `-d` argument, e.g.
+// "vault=file:///home/runner/workspace/vaultMock?type=application/json".
+func ParseDatasource(spec string) (name string, ds Datasource, err error) {
+	name, raw, found := strings.Cut(spec, "=")
+	if !found || name == "" || raw == "" {
+		return "", nil, fmt.Errorf("invalid datasource %q, expected name=url", spec)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid datasource url %q: %w", raw, err)
+	}
+	switch u.Scheme {
+	case "file", "":
+		return name, &DirDatasource{Dir: u.Path, MimeType: u.Query().Get("type")}, nil
+	default:
+		return "", nil, fmt.Errorf("unsupported datasource scheme %q in %q", u.Scheme, spec)
+	}
+}
+
+func parseDocument(data []byte, mimeType string) (interface{}, error) {
+	var doc interface{}
+	switch mimeType {
+	case "", "application/json":
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON datasource: %w", err)
+		}
+	case "application/yaml", "application/x-yaml":
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML datasource: %w", err)
+		}
+		doc = normalize(doc)
+	case "text/plain":
+		doc = string(data)
+	default:
+		return nil, fmt.Errorf("unsupported datasource type %q", mimeType)
+	}
+	return doc, nil
+}
+
+// normalize converts the map[interface{}]interface{} produced by yaml.v2 into
+// map[string]interface{} so templates can use `.field` access.
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			m[fmt.Sprint(k)] = normalize(val)
+		}
+		return m
+	case []interface{}:
+		for i, val := range t {
+			t[i] = normalize(val)
+		}
+	}
+	return v
+}
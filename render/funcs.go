@@ -0,0 +1,160 @@
+package render
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// funcMap returns the gomplate compatible functions used by the qcs values files.
+func (r *Renderer) funcMap() template.FuncMap {
+	return template.FuncMap{
+		"getenv":     r.getenv,
+		"ds":         r.datasource,
+		"datasource": r.datasource,
+		"file":       func() *fileFuncs { return &fileFuncs{fsys: r.opts.Files} },
+		"path":       func() pathFuncs { return pathFuncs{} },
+		"base64":     func() base64Funcs { return base64Funcs{} },
+		"indent":     indent,
+	}
+}
+
+// getenv returns the value of the variable or the first default when it is unset or empty.
+func (r *Renderer) getenv(name string, defaults ...string) string {
+	if value := r.opts.Env[name]; value != "" {
+		return value
+	}
+	if len(defaults) > 0 {
+		return defaults[0]
+	}
+	return ""
+}
+
+// datasource implements `ds "alias" "key"` and `datasource "alias" "key"`.
+func (r *Renderer) datasource(alias string, args ...string) (interface{}, error) {
+	ds, ok := r.opts.Datasources[alias]
+	if !ok {
+		return nil, fmt.Errorf("undefined datasource %q", alias)
+	}
+	key := strings.Join(args, "/")
+	value, err := ds.Read(key)
+	if err != nil {
+		return nil, fmt.Errorf("datasource %q key %q: %w", alias, key, err)
+	}
+	r.opts.Secrets.Add(value)
+	return value, nil
+}
+
+type fileFuncs struct {
+	fsys fs.FS
+}
+
+// Read implements gomplate's file.Read.
+func (f *fileFuncs) Read(name string) (string, error) {
+	data, err := fs.ReadFile(f.fsys, path.Clean(strings.TrimPrefix(name, "./")))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+type pathFuncs struct{}
+
+// Join implements gomplate's path.Join.
+func (pathFuncs) Join(elem ...interface{}) string {
+	parts := make([]string, 0, len(elem))
+	for _, e := range elem {
+		parts = append(parts, toString(e))
+	}
+	return path.Join(parts...)
+}
+
+type base64Funcs struct{}
+
+// Encode implements gomplate's base64.Encode.
+func (base64Funcs) Encode(in interface{}) string {
+	return base64.StdEncoding.EncodeToString([]byte(toString(in)))
+}
+
+// Decode implements gomplate's base64.Decode.
+func (base64Funcs) Decode(in interface{}) (string, error) {
+	out, err := base64.StdEncoding.DecodeString(toString(in))
+	return string(out), err
+}
+
+// indent implements gomplate's `indent [width] [indent] input`.
+func indent(args ...interface{}) (string, error) {
+	if len(args) == 0 || len(args) > 3 {
+		return "", fmt.Errorf("indent expects between 1 and 3 arguments, got %d", len(args))
+	}
+	input := toString(args[len(args)-1])
+	width, pad := 1, " "
+	switch len(args) {
+	case 2:
+		if s, ok := args[0].(string); ok {
+			if _, err := strconv.Atoi(s); err != nil {
+				pad = s
+				break
+			}
+		}
+		w, err := toInt(args[0])
+		if err != nil {
+			return "", err
+		}
+		width = w
+	case 3:
+		w, err := toInt(args[0])
+		if err != nil {
+			return "", err
+		}
+		width, pad = w, toString(args[1])
+	}
+	if width <= 0 {
+		return input, nil
+	}
+	pad = strings.Repeat(pad, width)
+
+	var sb strings.Builder
+	bol := true
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		if bol && c != '\n' {
+			sb.WriteString(pad)
+		}
+		sb.WriteByte(c)
+		bol = c == '\n'
+	}
+	return sb.String(), nil
+}
+
+func toString(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case []byte:
+		return string(t)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+func toInt(v interface{}) (int, error) {
+	switch t := v.(type) {
+	case int:
+		return t, nil
+	case int64:
+		return int(t), nil
+	case float64:
+		return int(t), nil
+	case string:
+		return strconv.Atoi(t)
+	default:
+		return 0, fmt.Errorf("cannot convert %v to int", v)
+	}
+}
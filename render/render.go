@@ -0,0 +1,171 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+)
+
+// Options required for rendering a qcs values template.
+type Options struct {
+	// Env holds the variables returned by getenv, e.g. ENVIRONMENT, REGION and PROVIDER.
+	Env map[string]string
+	// Datasources maps a datasource alias ("vault") to its implementation.
+	Datasources map[string]Datasource
+	// Files is the file system file.Read resolves paths against.
+	Files fs.FS
+	// Secrets records the values read from datasources, nil to not track them.
+	Secrets *Secrets
+}
+
+type Option func(*Options) error
+
+// GetDefaultOptions returns default configuration options for a Renderer.
+func GetDefaultOptions() Options {
+	return Options{
+		Env:         map[string]string{},
+		Datasources: map[string]Datasource{},
+		Files:       os.DirFS("."),
+	}
+}
+
+// WithEnv is an Option to add variables visible to getenv.
+func WithEnv(env map[string]string) Option {
+	return func(opt *Options) error {
+		for k, v := range env {
+			opt.Env[k] = v
+		}
+		return nil
+	}
+}
+
+// WithDatasource is an Option to register a datasource under an alias.
+func WithDatasource(alias string, ds Datasource) Option {
+	return func(opt *Options) error {
+		if alias == "" {
+			return fmt.Errorf("datasource alias must not be empty")
+		}
+		opt.Datasources[alias] = ds
+		return nil
+	}
+}
+
+// WithDatasourceSpec is an Option to register a datasource from a gomplate `-d` argument.
+func WithDatasourceSpec(spec string) Option {
+	return func(opt *Options) error {
+		alias, ds, err := ParseDatasource(spec)
+		if err != nil {
+			return err
+		}
+		opt.Datasources[alias] = ds
+		return nil
+	}
+}
+
+// WithSecrets is an Option to record the values read from datasources into secrets.
+func WithSecrets(secrets *Secrets) Option {
+	return func(opt *Options) error {
+		opt.Secrets = secrets
+		return nil
+	}
+}
+
+// WithFiles is an Option to set the file system used by file.Read.
+func WithFiles(fsys fs.FS) Option {
+	return func(opt *Options) error {
+		opt.Files = fsys
+		return nil
+	}
+}
+
+func compileOptions(opts ...Option) (*Options, error) {
+	options := GetDefaultOptions()
+	for _, opt := range opts {
+		if err := opt(&options); err != nil {
+			return nil, err
+		}
+	}
+	return &options, nil
+}
+
+// Renderer renders gomplate flavoured templates with text/template.
+type Renderer struct {
+	opts *Options
+}
+
+// NewRenderer creates a new Renderer with the provided options.
+func NewRenderer(opts ...Option) (*Renderer, error) {
+	options, err := compileOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Renderer{opts: options}, nil
+}
+
+// With returns a copy of the renderer with additional options applied,
+// e.g. to render the same values file for another environment.
+func (r *Renderer) With(opts ...Option) (*Renderer, error) {
+	options := Options{
+		Env:         map[string]string{},
+		Datasources: map[string]Datasource{},
+		Files:       r.opts.Files,
+		Secrets:     r.opts.Secrets,
+	}
+	for k, v := range r.opts.Env {
+		options.Env[k] = v
+	}
+	for k, v := range r.opts.Datasources {
+		options.Datasources[k] = v
+	}
+	for _, opt := range opts {
+		if err := opt(&options); err != nil {
+			return nil, err
+		}
+	}
+	return &Renderer{opts: &options}, nil
+}
+
+// Render executes the template text. The name is used in error messages.
+func (r *Renderer) Render(name, text string) (string, error) {
+	tmpl, err := template.New(name).Funcs(r.funcMap()).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, nil); err != nil {
+		return "", fmt.Errorf("failed to render template %s: %w", name, err)
+	}
+	return out.String(), nil
+}
+
+// RenderFile reads and renders a template from the local file system.
+func (r *Renderer) RenderFile(file string) (string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read template %s: %w", file, err)
+	}
+	return r.Render(file, string(data))
+}
+
+// ComponentFS maps the "<component>-values/" prefix used by the values files in
+// their file.Read calls onto the repository root, the way the workflows copy
+// qcs/<component> into <component>-values/qcs/<component> before running gomplate.
+func ComponentFS(root fs.FS, component string) fs.FS {
+	return &prefixFS{root: root, prefix: component + "-values"}
+}
+
+type prefixFS struct {
+	root   fs.FS
+	prefix string
+}
+
+func (p *prefixFS) Open(name string) (fs.File, error) {
+	if rest, found := strings.CutPrefix(name, p.prefix+"/"); found {
+		name = path.Clean(rest)
+	}
+	return p.root.Open(name)
+}
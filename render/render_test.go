@@ -0,0 +1,189 @@
+package render_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"gitpkg/render"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func newRenderer(t *testing.T, opts ...render.Option) *render.Renderer {
+	t.Helper()
+	r, err := render.NewRenderer(opts...)
+	assert.NoError(t, err)
+	return r
+}
+
+func TestRenderer_Getenv(t *testing.T) {
+	r := newRenderer(t, render.WithEnv(map[string]string{"ENVIRONMENT": "prod", "EMPTY": ""}))
+
+	tests := map[string]string{
+		`{{ getenv "ENVIRONMENT" "local" }}`:  "prod",
+		`{{ getenv "REGION" "localregion" }}`: "localregion",
+		`{{ getenv "EMPTY" "fallback" }}`:     "fallback",
+		`{{ getenv "UNSET" }}`:                "",
+	}
+	for text, expected := range tests {
+		t.Run(text, func(t *testing.T) {
+			out, err := r.Render("test", text)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, out)
+		})
+	}
+}
+
+func TestRenderer_Datasource(t *testing.T) {
+	t.Run("ds and datasource read keys from a directory datasource", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "redisPassword"), []byte(`{"value":"s3cr3t"}`), 0600))
+		r := newRenderer(t, render.WithDatasourceSpec("vault=file://"+dir+"?type=application/json"))
+
+		// Act
+		out, err := r.Render("test", `{{ (ds "vault" "redisPassword").value | printf "%q" }} {{ (datasource "vault" "redisPassword").value }}`)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, `"s3cr3t" s3cr3t`, out)
+	})
+
+	t.Run("ds fails for an undefined datasource", func(t *testing.T) {
+		r := newRenderer(t)
+
+		_, err := r.Render("test", `{{ (ds "vault" "redisPassword").value }}`)
+
+		assert.ErrorContains(t, err, `undefined datasource "vault"`)
+	})
+
+	t.Run("ds fails for a missing key", func(t *testing.T) {
+		r := newRenderer(t, render.WithDatasource("vault", render.MapDatasource{}))
+
+		_, err := r.Render("test", `{{ (ds "vault" "mongoURI").value }}`)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestRenderer_Namespaces(t *testing.T) {
+	files := fstest.MapFS{
+		"qcs/c/sealed-secrets/prod/us-east-1/sealed-secrets.yaml": {Data: []byte("sealedSecrets: {}\n")},
+	}
+	r := newRenderer(t,
+		render.WithFiles(render.ComponentFS(files, "c")),
+		render.WithDatasource("vault", render.MapDatasource{"settings": map[string]interface{}{"value": "a\nb"}}),
+	)
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"path.Join", `{{ path.Join "a/" "b" "c.yaml" }}`, "a/b/c.yaml"},
+		{"file.Read", `{{ file.Read (path.Join "c-values/qcs/c/sealed-secrets/" "prod" "us-east-1" "sealed-secrets.yaml") }}`, "sealedSecrets: {}\n"},
+		{"base64.Encode", `{{ "hello" | base64.Encode }}`, "aGVsbG8="},
+		{"indent width", `{{ (ds "vault" "settings").value | indent 4 }}`, "    a\n    b"},
+		{"indent default", `{{ indent "x" }}`, " x"},
+		{"indent custom", `{{ indent 2 "-" "x\n\ny" }}`, "--x\n\n--y"},
+		{"base64 then indent", `{{ (ds "vault" "settings").value | base64.Encode | indent 6 }}`, "      YQpi"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := r.Render("test", tc.text)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, out)
+		})
+	}
+
+	t.Run("file.Read fails for a missing file", func(t *testing.T) {
+		_, err := r.Render("test", `{{ file.Read "missing.yaml" }}`)
+		assert.Error(t, err)
+	})
+}
+
+func TestRenderer_QcsValuesFiles(t *testing.T) {
+	vault := render.MapDatasource{}
+	for _, key := range []string{"qcs_secrets_v2", "redisPassword", "tokenEncryptionkey", "enc_key_for_connector_settings_v1", "mongoURI"} {
+		vault[key] = map[string]interface{}{"value": "mock-" + key}
+	}
+	base := newRenderer(t,
+		render.WithDatasource("vault", vault),
+		render.WithEnv(map[string]string{"CONTAINER_REGISTRY_URL": "registry.com"}),
+	)
+
+	tests := []struct {
+		component string
+		env       map[string]string
+	}{
+		{"data-connector-odbc", map[string]string{"ENVIRONMENT": "stage", "REGION": "eu-west-1"}},
+		{"data-connector-odbc", map[string]string{"ENVIRONMENT": "prod", "REGION": "us-east-1", "PROVIDER": "fedramp"}},
+		{"dataprep-proxy", map[string]string{"ENVIRONMENT": "qcs-int", "REGION": "eu-central-1"}},
+		{"dataprep-proxy", map[string]string{"ENVIRONMENT": "prod", "REGION": "ap-south-1"}},
+		{"bonjour-world", map[string]string{"ENVIRONMENT": "stage", "REGION": "us-east-1", "PROVIDER": "eks"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.component+"/"+tc.env["ENVIRONMENT"]+"/"+tc.env["REGION"], func(t *testing.T) {
+			// Arrange
+			r, err := base.With(
+				render.WithEnv(tc.env),
+				render.WithFiles(render.ComponentFS(os.DirFS("../"), tc.component)),
+			)
+			assert.NoError(t, err)
+
+			// Act
+			out, err := r.RenderFile(filepath.Join("..", "qcs", tc.component, "values.yaml"))
+
+			// Assert
+			assert.NoError(t, err)
+			values := map[string]interface{}{}
+			assert.NoError(t, yaml.Unmarshal([]byte(out), &values))
+			assert.NotEmpty(t, values)
+		})
+	}
+}
+
+func TestSecrets(t *testing.T) {
+	t.Run("Secrets tracks datasource values and redacts them with their base64 encoding", func(t *testing.T) {
+		// Arrange
+		secrets := render.NewSecrets()
+		r := newRenderer(t,
+			render.WithDatasource("vault", render.MapDatasource{"key": map[string]interface{}{"value": "line-one\nline-two", "n": 1}}),
+			render.WithSecrets(secrets))
+
+		// Act
+		out, err := r.Render("test", `{{ (ds "vault" "key").value | base64.Encode }} {{ (ds "vault" "key").value | indent 2 }}`)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, render.Redacted+"   "+render.Redacted+"\n  "+render.Redacted, secrets.Redact(out))
+		assert.True(t, secrets.Contains("x line-two x"))
+		assert.False(t, secrets.Contains("1"))
+	})
+
+	t.Run("WriteMasks only writes add-mask commands in GitHub Actions", func(t *testing.T) {
+		secrets := render.NewSecrets()
+		secrets.Add("s3cr3t")
+		var buf bytes.Buffer
+
+		t.Setenv("GITHUB_ACTIONS", "")
+		secrets.WriteMasks(&buf)
+		assert.Empty(t, buf.String())
+
+		t.Setenv("GITHUB_ACTIONS", "true")
+		secrets.WriteMasks(&buf)
+		assert.Equal(t, "::add-mask::czNjcjN0\n::add-mask::s3cr3t\n", buf.String())
+	})
+
+	t.Run("A nil Secrets tracks and redacts nothing", func(t *testing.T) {
+		var secrets *render.Secrets
+
+		secrets.Add("s3cr3t")
+
+		assert.Equal(t, "s3cr3t", secrets.Redact("s3cr3t"))
+	})
+}
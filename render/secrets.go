@@ -0,0 +1,140 @@
+package render
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces secret values in logs, diffs and reports.
+const Redacted = "<redacted>"
+
+// MinSecretLength is the length below which datasource values are not tracked,
+// so that short values such as "1" or "true" do not redact unrelated text.
+const MinSecretLength = 4
+
+// Secrets collects the values templates read from datasources, which hold vault
+// secrets, so they can be redacted from any text the tools print. A nil *Secrets
+// tracks nothing and redacts nothing.
+type Secrets struct {
+	mu     sync.Mutex
+	values map[string]bool
+}
+
+// NewSecrets creates an empty set of secret values.
+func NewSecrets() *Secrets {
+	return &Secrets{values: map[string]bool{}}
+}
+
+// Add records every scalar of a datasource document along with its base64
+// encoding, which templates use for Secret data.
+func (s *Secrets) Add(value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.add(value)
+}
+
+func (s *Secrets) add(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, item := range v {
+			s.add(item)
+		}
+	case []interface{}:
+		for _, item := range v {
+			s.add(item)
+		}
+	case nil, bool:
+	default:
+		str := strings.TrimSpace(fmt.Sprint(v))
+		if len(str) < MinSecretLength {
+			return
+		}
+		s.values[str] = true
+		s.values[base64.StdEncoding.EncodeToString([]byte(str))] = true
+		// multi-line values are also rendered line by line through indent
+		for _, line := range strings.Split(str, "\n") {
+			if line = strings.TrimSpace(line); len(line) >= MinSecretLength {
+				s.values[line] = true
+			}
+		}
+	}
+}
+
+// Values returns the tracked values, longest first so that a value containing
+// another is replaced as a whole.
+func (s *Secrets) Values() []string {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	values := make([]string, 0, len(s.values))
+	for v := range s.values {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+		return values[i] < values[j]
+	})
+	return values
+}
+
+// Redact replaces every tracked value in text with Redacted.
+func (s *Secrets) Redact(text string) string {
+	for _, v := range s.Values() {
+		text = strings.ReplaceAll(text, v, Redacted)
+	}
+	return text
+}
+
+// Contains reports whether text holds a tracked value.
+func (s *Secrets) Contains(text string) bool {
+	for _, v := range s.Values() {
+		if strings.Contains(text, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteMasks writes a GitHub Actions add-mask command for every tracked value,
+// so the runner also hides them from the output of later steps, e.g. when a
+// workflow prints the rendered values file. Nothing is written outside Actions.
+func (s *Secrets) WriteMasks(w io.Writer) {
+	if os.Getenv("GITHUB_ACTIONS") != "true" {
+		return
+	}
+	for _, v := range s.Values() {
+		if !strings.Contains(v, "\n") {
+			fmt.Fprintf(w, "::add-mask::%s\n", v)
+		}
+	}
+}
+
+// Writer returns a writer redacting the tracked values from every write.
+// Values split across writes are not detected, so callers write whole lines.
+func (s *Secrets) Writer(w io.Writer) io.Writer {
+	return &redactWriter{secrets: s, w: w}
+}
+
+type redactWriter struct {
+	secrets *Secrets
+	w       io.Writer
+}
+
+func (r *redactWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, r.secrets.Redact(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
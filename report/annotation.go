@@ -0,0 +1,25 @@
+package report
+
+import "gitpkg/logging"
+
+// Annotation returns the annotation of a result, placed at its file, line and
+// column.
+func (res Result) Annotation() logging.Annotation {
+	return logging.Annotation{
+		Severity: logging.SeverityError,
+		File:     res.File,
+		Line:     res.Line,
+		Col:      res.Column,
+		Title:    string(res.Stage),
+		Message:  res.Component + " in " + res.Environment + ": " + res.Message,
+	}
+}
+
+// Annotate emits an annotation for every failed result of a repository file.
+func (r *Report) Annotate(a logging.Annotator) {
+	for _, res := range r.Results {
+		if res.Status == StatusFail && res.File != "" {
+			a.Annotate(res.Annotation())
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr,omitempty"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// WriteJUnit writes the report as JUnit XML with one test suite per component
+// and one test case per environment.
+func (r *Report) WriteJUnit(w io.Writer) error {
+	doc := junitSuites{Name: r.Name}
+	index := map[string]int{}
+	var durations []time.Duration
+	for _, res := range r.Results {
+		i, ok := index[res.Component]
+		if !ok {
+			i = len(doc.Suites)
+			index[res.Component] = i
+			doc.Suites = append(doc.Suites, junitSuite{Name: res.Component})
+			durations = append(durations, 0)
+		}
+		suite := &doc.Suites[i]
+		c := junitCase{
+			Name:      fmt.Sprintf("%s/%s", res.Environment, res.Stage),
+			ClassName: res.Component,
+			File:      res.File,
+			Time:      seconds(res.Duration),
+		}
+		switch res.Status {
+		case StatusFail:
+			c.Failure = &junitMessage{Message: firstLine(res.Message), Type: string(res.Stage), Text: res.Message}
+			suite.Failures++
+		case StatusSkip:
+			c.Skipped = &junitMessage{Message: res.Message}
+			suite.Skipped++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, c)
+		durations[i] += res.Duration
+	}
+	var total time.Duration
+	for i := range doc.Suites {
+		doc.Suites[i].Time = seconds(durations[i])
+		doc.Tests += doc.Suites[i].Tests
+		doc.Failures += doc.Suites[i].Failures
+		doc.Skipped += doc.Suites[i].Skipped
+		total += durations[i]
+	}
+	doc.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,57 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var statusIcons = map[Status]string{
+	StatusPass: ":white_check_mark: pass",
+	StatusFail: ":x: fail",
+	StatusSkip: ":warning: skip",
+}
+
+// WriteMarkdown writes the report as a GitHub step summary: a heading with the
+// totals and a table with failures first, then skipped and passed results.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	name := r.Name
+	if name == "" {
+		name = "Results"
+	}
+	fmt.Fprintf(w, "### %s\n\n", name)
+	fmt.Fprintf(w, "%d passed, %d failed, %d skipped\n\n", r.Count(StatusPass), r.Count(StatusFail), r.Count(StatusSkip))
+	if len(r.Results) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w, "| Status | Component | Environment | Region | Stage | Duration | Message |")
+	fmt.Fprintln(w, "| --- | --- | --- | --- | --- | --- | --- |")
+	for _, status := range []Status{StatusFail, StatusSkip, StatusPass} {
+		for _, res := range r.Results {
+			if res.Status != status {
+				continue
+			}
+			fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s | %s |\n",
+				statusIcons[res.Status],
+				cell(res.Component),
+				cell(res.Environment),
+				cell(res.Region),
+				cell(string(res.Stage)),
+				res.Duration.Round(time.Millisecond),
+				cell(res.Message))
+		}
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+func cell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(strings.TrimSpace(s), "\n", "<br>")
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,120 @@
+package report
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Status of a checked component/environment pair.
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusFail Status = "fail"
+	StatusSkip Status = "skip"
+)
+
+// Stage of the pipeline a result belongs to.
+type Stage string
+
+const (
+	StageRender      Stage = "render"
+	StageTemplate    Stage = "template"
+	StageSchema      Stage = "schema"
+	StageDeployCheck Stage = "deploy-check"
+)
+
+// Result is the outcome of checking one component in one environment.
+type Result struct {
+	Component   string
+	Environment string
+	Region      string
+	Version     string
+	Stage       Stage
+	Status      Status
+	Message     string
+	Duration    time.Duration
+	// File is the repository file the result is about, e.g. qcs/<component>/values.yaml.
+	File string
+	// Line is the 1-based line in File the message points at, 0 when unknown.
+	Line int
+	// Column is the 1-based column of Line, 0 when unknown.
+	Column int
+}
+
+// Report is a named list of results, e.g. the results of a validation run.
+type Report struct {
+	Name    string
+	Results []Result
+}
+
+// New creates an empty report.
+func New(name string) *Report {
+	return &Report{Name: name}
+}
+
+// Add appends results to the report.
+func (r *Report) Add(results ...Result) {
+	r.Results = append(r.Results, results...)
+}
+
+// Count returns the number of results with the given status.
+func (r *Report) Count(status Status) int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed reports whether any result failed.
+func (r *Report) Failed() bool {
+	return r.Count(StatusFail) > 0
+}
+
+// Outputs are the files a report is written to. Empty paths are skipped.
+type Outputs struct {
+	JUnit string
+	SARIF string
+	// Markdown is appended to, like the GitHub step summary file.
+	Markdown string
+}
+
+// BindFlags registers the --junit, --sarif and --summary flags. The summary
+// defaults to $GITHUB_STEP_SUMMARY so reports show up on the workflow run.
+func (o *Outputs) BindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.JUnit, "junit", "", "Write the results as JUnit XML to this file")
+	fs.StringVar(&o.SARIF, "sarif", "", "Write the failed results as SARIF to this file")
+	fs.StringVar(&o.Markdown, "summary", os.Getenv("GITHUB_STEP_SUMMARY"), "Append the results as a markdown table to this file")
+}
+
+// Write renders the report to every configured output.
+func (o Outputs) Write(r *Report) error {
+	if err := writeFile(o.JUnit, os.O_TRUNC, r.WriteJUnit); err != nil {
+		return err
+	}
+	if err := writeFile(o.SARIF, os.O_TRUNC, r.WriteSARIF); err != nil {
+		return err
+	}
+	return writeFile(o.Markdown, os.O_APPEND, r.WriteMarkdown)
+}
+
+func writeFile(file string, mode int, write func(io.Writer) error) error {
+	if file == "" {
+		return nil
+	}
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|mode, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", file, err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return f.Close()
+}
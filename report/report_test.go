@@ -0,0 +1,151 @@
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gitpkg/logging"
+	"gitpkg/report"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testReport() *report.Report {
+	r := report.New("Helm chart validation")
+	r.Add(
+		report.Result{Component: "a", Environment: "qcs-stage-us-east-1", Region: "us-east-1", Stage: report.StageSchema, Status: report.StatusPass, Duration: 1500 * time.Millisecond, File: "qcs/a/values.yaml"},
+		report.Result{Component: "a", Environment: "qcs-prod-eu-west-1", Region: "eu-west-1", Stage: report.StageRender, Status: report.StatusFail, Message: "template: qcs/a/values.yaml:3: bad | pipe\nsecond line", File: "qcs/a/values.yaml", Line: 3},
+		report.Result{Component: "b", Environment: "qcs-stage-us-east-1", Region: "us-east-1", Stage: report.StageTemplate, Status: report.StatusSkip, Message: "helm chart not found"},
+	)
+	return r
+}
+
+func TestReport_Count(t *testing.T) {
+	r := testReport()
+
+	assert.Equal(t, 1, r.Count(report.StatusPass))
+	assert.Equal(t, 1, r.Count(report.StatusFail))
+	assert.Equal(t, 1, r.Count(report.StatusSkip))
+	assert.True(t, r.Failed())
+}
+
+func TestReport_WriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := testReport().WriteJUnit(&buf)
+
+	assert.NoError(t, err)
+	var doc struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Skipped  int `xml:"skipped,attr"`
+		Suites   []struct {
+			Name  string `xml:"name,attr"`
+			Time  string `xml:"time,attr"`
+			Cases []struct {
+				Name    string `xml:"name,attr"`
+				Failure *struct {
+					Message string `xml:"message,attr"`
+				} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, 3, doc.Tests)
+	assert.Equal(t, 1, doc.Failures)
+	assert.Equal(t, 1, doc.Skipped)
+	assert.Len(t, doc.Suites, 2)
+	assert.Equal(t, "a", doc.Suites[0].Name)
+	assert.Equal(t, "1.500", doc.Suites[0].Time)
+	assert.Equal(t, "qcs-prod-eu-west-1/render", doc.Suites[0].Cases[1].Name)
+	assert.Equal(t, "template: qcs/a/values.yaml:3: bad | pipe", doc.Suites[0].Cases[1].Failure.Message)
+}
+
+func TestReport_WriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := testReport().WriteSARIF(&buf)
+
+	assert.NoError(t, err)
+	var doc struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, "2.1.0", doc.Version)
+	run := doc.Runs[0]
+	assert.Len(t, run.Results, 1)
+	assert.Equal(t, "render", run.Results[0].RuleID)
+	assert.Equal(t, "render", run.Tool.Driver.Rules[0].ID)
+	assert.Equal(t, "qcs/a/values.yaml", run.Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 3, run.Results[0].Locations[0].PhysicalLocation.Region.StartLine)
+}
+
+func TestReport_Annotate(t *testing.T) {
+	var buf bytes.Buffer
+
+	testReport().Annotate(logging.NewGitHubAnnotator(&buf))
+
+	assert.Equal(t, "::error title=render,file=qcs/a/values.yaml,line=3::a in qcs-prod-eu-west-1: template: qcs/a/values.yaml:3: bad | pipe%0Asecond line\n", buf.String())
+}
+
+func TestReport_WriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := testReport().WriteMarkdown(&buf)
+
+	assert.NoError(t, err)
+	lines := strings.Split(buf.String(), "\n")
+	assert.Equal(t, "### Helm chart validation", lines[0])
+	assert.Equal(t, "1 passed, 1 failed, 1 skipped", lines[2])
+	assert.Equal(t, "| :x: fail | a | qcs-prod-eu-west-1 | eu-west-1 | render | 0s | template: qcs/a/values.yaml:3: bad \\| pipe<br>second line |", lines[6])
+	assert.Contains(t, lines[7], ":warning: skip | b")
+	assert.Contains(t, lines[8], ":white_check_mark: pass | a | qcs-stage-us-east-1 | us-east-1 | schema | 1.5s")
+}
+
+func TestOutputs_Write(t *testing.T) {
+	dir := t.TempDir()
+	summary := filepath.Join(dir, "summary.md")
+	assert.NoError(t, os.WriteFile(summary, []byte("existing\n"), 0644))
+	outputs := report.Outputs{
+		JUnit:    filepath.Join(dir, "junit.xml"),
+		SARIF:    filepath.Join(dir, "results.sarif"),
+		Markdown: summary,
+	}
+
+	err := outputs.Write(testReport())
+
+	assert.NoError(t, err)
+	assert.FileExists(t, outputs.JUnit)
+	assert.FileExists(t, outputs.SARIF)
+	data, err := os.ReadFile(summary)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "existing\n### Helm chart validation"))
+}
@@ -0,0 +1,109 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// SARIFToolName is the driver name reported in SARIF output.
+const SARIFToolName = "go-tools"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           *sarifRegion  `json:"region,omitempty"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// WriteSARIF writes the failed results as SARIF 2.1.0, one rule per stage,
+// located at the file of the result so code scanning annotates it.
+func (r *Report) WriteSARIF(w io.Writer) error {
+	run := sarifRun{Tool: sarifTool{Driver: sarifDriver{Name: SARIFToolName}}, Results: []sarifResult{}}
+	rules := map[Stage]bool{}
+	for _, res := range r.Results {
+		if res.Status != StatusFail {
+			continue
+		}
+		rules[res.Stage] = true
+		result := sarifResult{
+			RuleID:  string(res.Stage),
+			Level:   "error",
+			Message: sarifMessage{Text: res.Component + " in " + res.Environment + ": " + res.Message},
+		}
+		if res.File != "" {
+			line := res.Line
+			if line < 1 {
+				line = 1
+			}
+			result.Locations = []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifact{URI: res.File},
+				Region:           &sarifRegion{StartLine: line, StartColumn: res.Column},
+			}}}
+		}
+		run.Results = append(run.Results, result)
+	}
+	run.Tool.Driver.Rules = []sarifRule{}
+	for stage := range rules {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:               string(stage),
+			ShortDescription: sarifMessage{Text: "The " + string(stage) + " stage failed"},
+		})
+	}
+	sort.Slice(run.Tool.Driver.Rules, func(i, j int) bool { return run.Tool.Driver.Rules[i].ID < run.Tool.Driver.Rules[j].ID })
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
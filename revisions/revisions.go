@@ -0,0 +1,137 @@
+// Package revisions loads the base and head trees of a values repository
+// change, with the inventory and datasources needed to render them. It is
+// shared by the commands comparing a pull request with its base.
+package revisions
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"gitpkg/inventory"
+	"gitpkg/qgit"
+	"gitpkg/render"
+	"gitpkg/utilities"
+	"gitpkg/vaultmock"
+)
+
+// Options selects the change and how its trees are rendered.
+type Options struct {
+	// Repo is the checkout of the values repository.
+	Repo string
+	// Base is compared through its merge base with Head, so later changes of
+	// the base are ignored.
+	Base string
+	// Head is refs/pull/<PrNumber>/head when empty.
+	Head string
+	// PrNumber is the pull request whose head ref is fetched, if not zero.
+	PrNumber int
+	// Inventory is the path of the environment inventory.
+	Inventory string
+	// EnvironmentsRoot is the gitops-environments checkout holding the
+	// conf.yaml files. It is ignored when it does not exist.
+	EnvironmentsRoot string
+	// VaultMock is a mock directory generated by vault-mock, used for every
+	// datasource the values templates of Repo reference.
+	VaultMock string
+	// RegistryURL is rendered as CONTAINER_REGISTRY_URL.
+	RegistryURL string
+	// Datasources are name=url specs, see render.WithDatasourceSpec.
+	Datasources utilities.MultiFlag
+}
+
+// BindFlags registers the flags selecting the change and rendering its trees.
+func (o *Options) BindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.Repo, "repo", ".", "Checkout of the values repository")
+	fs.StringVar(&o.Base, "base", "refs/remotes/origin/main", "Base reference or commit; the head is compared with its merge base with the base, so later changes of the base are ignored")
+	fs.StringVar(&o.Head, "head", "", "Head reference or commit, refs/pull/<pr-number>/head by default")
+	fs.IntVar(&o.PrNumber, "pr-number", 0, "Pull request number; its head ref is fetched")
+	fs.StringVar(&o.Inventory, "inventory", "gitops-environments/"+inventory.DefaultFile, "Path of the environment inventory")
+	fs.StringVar(&o.EnvironmentsRoot, "environments-root", "gitops-environments", "gitops-environments checkout holding the conf.yaml files")
+	fs.StringVar(&o.VaultMock, "vault-mock", "", "Mock directory generated by vault-mock, used for every referenced datasource")
+	fs.StringVar(&o.RegistryURL, "registry-url", "registry.com", "Value rendered as CONTAINER_REGISTRY_URL")
+	fs.Var(&o.Datasources, "datasource", "Datasource as name=file:///dir?type=application/json, repeatable")
+}
+
+// Trees are the two sides of a change and what rendering them needs.
+type Trees struct {
+	// Base is the tree at the merge base and Head the tree at HeadRef.
+	Base fs.FS
+	Head fs.FS
+	// BaseRef and HeadRef are the references the trees were loaded for.
+	BaseRef string
+	HeadRef string
+	// Inventory lists the environments to render.
+	Inventory *inventory.Inventory
+	// EnvironmentsRoot is empty when the checkout does not exist.
+	EnvironmentsRoot string
+	RegistryURL      string
+	// RenderOptions read the datasources and the vault mock.
+	RenderOptions []render.Option
+}
+
+// Load fetches the pull request, resolves the merge base of the head with the
+// base and returns the trees of both, with the inventory and render options.
+func (o Options) Load() (*Trees, error) {
+	head := o.Head
+	if head == "" {
+		if o.PrNumber == 0 {
+			return nil, fmt.Errorf("either --head or --pr-number is required")
+		}
+		head = fmt.Sprintf("refs/pull/%d/head", o.PrNumber)
+	}
+
+	client, err := qgit.NewClient(qgit.WithRepoPath(o.Repo), qgit.WithToken(os.Getenv("GITHUB_TOKEN")))
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Open(); err != nil {
+		return nil, err
+	}
+	if o.PrNumber != 0 {
+		if _, err := client.FetchPullRequest(o.PrNumber); err != nil {
+			return nil, err
+		}
+	}
+	mergeBase, err := client.MergeBase(o.Base, head)
+	if err != nil {
+		return nil, err
+	}
+	baseFS, err := client.FS(mergeBase)
+	if err != nil {
+		return nil, err
+	}
+	headFS, err := client.FS(head)
+	if err != nil {
+		return nil, err
+	}
+
+	inv, err := inventory.Load(o.Inventory)
+	if err != nil {
+		return nil, err
+	}
+	trees := &Trees{
+		Base:             baseFS,
+		Head:             headFS,
+		BaseRef:          o.Base,
+		HeadRef:          head,
+		Inventory:        inv,
+		EnvironmentsRoot: o.EnvironmentsRoot,
+		RegistryURL:      o.RegistryURL,
+	}
+	if _, err := os.Stat(o.EnvironmentsRoot); err != nil {
+		trees.EnvironmentsRoot = ""
+	}
+	for _, spec := range o.Datasources {
+		trees.RenderOptions = append(trees.RenderOptions, render.WithDatasourceSpec(spec))
+	}
+	if o.VaultMock != "" {
+		refs, err := vaultmock.ScanFiles(o.Repo, vaultmock.DefaultGlob)
+		if err != nil {
+			return nil, err
+		}
+		trees.RenderOptions = append(trees.RenderOptions, vaultmock.Datasources(o.VaultMock, refs)...)
+	}
+	return trees, nil
+}
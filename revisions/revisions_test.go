@@ -0,0 +1,81 @@
+package revisions_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gitpkg/revisions"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// commit writes the file into the work tree of repo and commits it.
+func commit(t *testing.T, repo *git.Repository, dir, name, content string) plumbing.Hash {
+	t.Helper()
+	wt, err := repo.Worktree()
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	_, err = wt.Add(name)
+	assert.NoError(t, err)
+	hash, err := wt.Commit("update", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	assert.NoError(t, err)
+	return hash
+}
+
+func TestOptions_Load(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	assert.NoError(t, err)
+	base := commit(t, repo, dir, "values.yaml", "replicas: 1\n")
+	head := commit(t, repo, dir, "values.yaml", "replicas: 2\n")
+	assert.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference("refs/pull/7/head", head)))
+	assert.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/main", base)))
+	commit(t, repo, dir, "values.yaml", "replicas: 3\n")
+	inv := filepath.Join(t.TempDir(), "environments.yaml")
+	assert.NoError(t, os.WriteFile(inv, []byte("pipeline-environments:\n  - qcs-stage-us-east-1\n"), 0644))
+
+	t.Run("Load returns the trees at the merge base and the head", func(t *testing.T) {
+		opt := revisions.Options{
+			Repo:             dir,
+			Base:             "refs/heads/main",
+			Head:             "refs/pull/7/head",
+			Inventory:        inv,
+			EnvironmentsRoot: filepath.Join(dir, "missing"),
+			RegistryURL:      "registry.example.com",
+			Datasources:      []string{"vault=file:///tmp"},
+		}
+
+		// Act
+		trees, err := opt.Load()
+
+		// Assert
+		assert.NoError(t, err)
+		baseValues, err := fs.ReadFile(trees.Base, "values.yaml")
+		assert.NoError(t, err)
+		assert.Equal(t, "replicas: 1\n", string(baseValues))
+		headValues, err := fs.ReadFile(trees.Head, "values.yaml")
+		assert.NoError(t, err)
+		assert.Equal(t, "replicas: 2\n", string(headValues))
+		assert.Equal(t, "refs/heads/main", trees.BaseRef)
+		assert.Equal(t, "refs/pull/7/head", trees.HeadRef)
+		assert.Equal(t, []string{"qcs-stage-us-east-1"}, trees.Inventory.Names())
+		assert.Empty(t, trees.EnvironmentsRoot)
+		assert.Equal(t, "registry.example.com", trees.RegistryURL)
+		assert.Len(t, trees.RenderOptions, 1)
+	})
+
+	t.Run("Load requires the head or the pull request number", func(t *testing.T) {
+		_, err := revisions.Options{Repo: dir, Base: "refs/heads/main", Inventory: inv}.Load()
+
+		assert.EqualError(t, err, "either --head or --pr-number is required")
+	})
+}
@@ -0,0 +1,81 @@
+package scaffold
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"gitpkg/inventory"
+)
+
+// Setup registers the flags of the init-component command; the command fails when the
+// generated values do not render for an environment.
+func Setup(fs *flag.FlagSet) func(args []string) error {
+	root := fs.String("root", ".", "Repository root containing the qcs directory")
+	inventoryFile := fs.String("inventory", "gitops-environments/"+inventory.DefaultFile, "Path of the environment inventory")
+	environmentsRoot := fs.String("environments-root", "gitops-environments", "gitops-environments checkout the conf.yaml files are written to")
+	component := fs.String("component", "", "Name of the new component")
+	chartURL := fs.String("chart-url", "", "Link to the chart, written to the header of the values file")
+	version := fs.String("version", "0.0.1", "Chart version written to every conf.yaml")
+	namespace := fs.String("namespace", "", "Namespace written to every conf.yaml, the component name by default")
+	slackChannel := fs.String("slack-channel", "", "Slack channel notified of deployments")
+	sealed := fs.Bool("sealed-secrets", true, "Include sealed secrets in the values and create their region files")
+	templateDir := fs.String("templates", "", "Directory with values.yaml.tmpl, sealed-secrets.yaml.tmpl and conf.yaml.tmpl replacing the default templates")
+	force := fs.Bool("force", false, "Overwrite the values file of an existing component")
+	registry := fs.String("registry-url", "registry.com", "Value rendered as CONTAINER_REGISTRY_URL")
+	return func(args []string) error {
+		if *component == "" {
+			return fmt.Errorf("--component is required")
+		}
+		if *chartURL == "" {
+			return fmt.Errorf("--chart-url is required")
+		}
+
+		inv, err := inventory.Load(*inventoryFile)
+		if err != nil {
+			return err
+		}
+		opts := Options{
+			Root:             *root,
+			EnvironmentsRoot: *environmentsRoot,
+			Inventory:        inv,
+			Component:        *component,
+			ChartURL:         *chartURL,
+			Version:          *version,
+			Namespace:        *namespace,
+			SlackChannel:     *slackChannel,
+			SealedSecrets:    *sealed,
+			Force:            *force,
+			RegistryURL:      *registry,
+		}
+		if _, err := os.Stat(*environmentsRoot); err != nil {
+			fmt.Printf("%s does not exist, no conf.yaml is written\n", *environmentsRoot)
+			opts.EnvironmentsRoot = ""
+		}
+		if *templateDir != "" {
+			opts.Templates = os.DirFS(*templateDir)
+		}
+
+		result, err := Generate(opts)
+		if err != nil {
+			return err
+		}
+		for _, file := range result.Files {
+			fmt.Printf("created %s\n", file)
+		}
+		for _, file := range result.ConfFiles {
+			fmt.Printf("created %s in %s\n", file, opts.EnvironmentsRoot)
+		}
+		for _, file := range result.ExistingConfFiles {
+			fmt.Printf("kept existing %s in %s\n", file, opts.EnvironmentsRoot)
+		}
+		for _, e := range result.RenderErrors {
+			fmt.Printf("render failed for %s: %v\n", e.Environment, e.Err)
+		}
+		if len(result.RenderErrors) > 0 {
+			return fmt.Errorf("the values of %s do not render for %d environments", *component, len(result.RenderErrors))
+		}
+		fmt.Printf("%s renders for all %d environments\n", *component, len(result.Rendered))
+		return nil
+	}
+}
@@ -0,0 +1,240 @@
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"text/template"
+
+	"gitpkg/inventory"
+	"gitpkg/sealedsecrets"
+	"gitpkg/validate"
+)
+
+// templates is the default template set. The templates use [[ ]] delimiters so
+// the gomplate actions of the values file are written as is.
+//
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+// DefaultTemplates returns the embedded template set: values.yaml.tmpl,
+// sealed-secrets.yaml.tmpl and conf.yaml.tmpl.
+func DefaultTemplates() fs.FS {
+	sub, _ := fs.Sub(templates, "templates")
+	return sub
+}
+
+// componentName matches the names allowed for components, which are used as
+// Kubernetes resource and Helm release names.
+var componentName = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// Options configures the files generated for a new component.
+type Options struct {
+	// Root is the repository root containing the qcs directory.
+	Root string
+	// EnvironmentsRoot is the gitops-environments checkout the conf.yaml files are
+	// written to. No conf.yaml is written when empty.
+	EnvironmentsRoot string
+	Inventory        *inventory.Inventory
+	Component        string
+	// ChartURL is linked in the header comment of the values file.
+	ChartURL string
+	// Version is the chart version written to every conf.yaml.
+	Version string
+	// Namespace is the namespace written to every conf.yaml, the component name by default.
+	Namespace    string
+	SlackChannel string
+	// SealedSecrets includes the sealed-secrets file of the region in the values
+	// and creates it for every environment using sealed secrets.
+	SealedSecrets bool
+	// Templates replaces the default template set. They may quote values
+	// with the quote function.
+	Templates fs.FS
+	// Force overwrites the values of an existing component. The conf.yaml and
+	// sealed-secrets files of the component are never overwritten.
+	Force bool
+	// RegistryURL is rendered as CONTAINER_REGISTRY_URL when checking the values.
+	RegistryURL string
+}
+
+// data is passed to the templates.
+type data struct {
+	Component     string
+	ChartURL      string
+	Version       string
+	Namespace     string
+	SlackChannel  string
+	SealedSecrets bool
+	Environment   string
+	Tier          string
+	Region        string
+}
+
+// RenderError is an environment the generated values do not render for.
+type RenderError struct {
+	Environment string
+	Err         error
+}
+
+// Result lists the generated files and the environments the values do not render for.
+type Result struct {
+	// Files are relative to Root, conf.yaml files to EnvironmentsRoot.
+	Files []string
+	// ConfFiles are the conf.yaml files relative to EnvironmentsRoot.
+	ConfFiles []string
+	// ExistingConfFiles are the conf.yaml files relative to EnvironmentsRoot
+	// that already existed and were left untouched.
+	ExistingConfFiles []string
+	// Rendered are the environments the values were rendered for, the known
+	// environments of the inventory.
+	Rendered     []string
+	RenderErrors []RenderError
+}
+
+// Generate writes qcs/<component>/values.yaml, a sealed-secrets.yaml for every
+// tier and region using sealed secrets and a conf.yaml for every environment,
+// then renders the values for every environment of the inventory.
+func Generate(opts Options) (*Result, error) {
+	if opts.Inventory == nil {
+		return nil, fmt.Errorf("an inventory is required")
+	}
+	if !componentName.MatchString(opts.Component) {
+		return nil, fmt.Errorf("invalid component name %q: use lowercase letters, digits and dashes", opts.Component)
+	}
+	if opts.Templates == nil {
+		opts.Templates = DefaultTemplates()
+	}
+	if opts.Namespace == "" {
+		opts.Namespace = opts.Component
+	}
+	if opts.Version == "" {
+		opts.Version = "0.0.1"
+	}
+	if opts.RegistryURL == "" {
+		opts.RegistryURL = "registry.com"
+	}
+	valuesFile := path.Join("qcs", opts.Component, "values.yaml")
+	if _, err := os.Stat(filepath.Join(opts.Root, valuesFile)); err == nil && !opts.Force {
+		return nil, fmt.Errorf("%s already exists", valuesFile)
+	}
+
+	d := data{
+		Component:     opts.Component,
+		ChartURL:      opts.ChartURL,
+		Version:       opts.Version,
+		Namespace:     opts.Namespace,
+		SlackChannel:  opts.SlackChannel,
+		SealedSecrets: opts.SealedSecrets,
+	}
+	values, err := execute(opts.Templates, "values.yaml.tmpl", d)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	if err := write(opts.Root, valuesFile, values); err != nil {
+		return nil, err
+	}
+	result.Files = append(result.Files, valuesFile)
+
+	envs := opts.Inventory.List()
+	secrets := map[string]bool{}
+	for _, env := range envs {
+		if !env.Known() {
+			continue
+		}
+		uses, err := sealedsecrets.UsesSealedSecrets(values, env)
+		if err != nil {
+			return nil, err
+		}
+		if uses {
+			secrets[filepath.ToSlash(sealedsecrets.File(opts.Component, env.Tier, env.Region))] = true
+		}
+		if opts.EnvironmentsRoot != "" {
+			confFile := inventory.ConfPath(opts.Component, env.Name)
+			if _, err := os.Stat(filepath.Join(opts.EnvironmentsRoot, filepath.FromSlash(confFile))); err == nil {
+				// live conf.yaml files are never reset, even with Force
+				result.ExistingConfFiles = append(result.ExistingConfFiles, confFile)
+				continue
+			}
+			d.Environment, d.Tier, d.Region = env.Name, env.Tier, env.Region
+			conf, err := execute(opts.Templates, "conf.yaml.tmpl", d)
+			if err != nil {
+				return nil, err
+			}
+			if err := write(opts.EnvironmentsRoot, confFile, conf); err != nil {
+				return nil, err
+			}
+			result.ConfFiles = append(result.ConfFiles, confFile)
+		}
+	}
+	files := make([]string, 0, len(secrets))
+	for file := range secrets {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		if _, err := os.Stat(filepath.Join(opts.Root, file)); err == nil {
+			// sealed values are never overwritten, even with Force
+			continue
+		}
+		content, err := execute(opts.Templates, "sealed-secrets.yaml.tmpl", d)
+		if err != nil {
+			return nil, err
+		}
+		if err := write(opts.Root, file, content); err != nil {
+			return nil, err
+		}
+		result.Files = append(result.Files, file)
+	}
+
+	root := os.DirFS(opts.Root)
+	for _, env := range envs {
+		if !env.Known() {
+			continue
+		}
+		result.Rendered = append(result.Rendered, env.Name)
+		if _, err := validate.RenderValues(root, opts.Component, values, env, opts.RegistryURL); err != nil {
+			result.RenderErrors = append(result.RenderErrors, RenderError{Environment: env.Name, Err: err})
+		}
+	}
+	return result, nil
+}
+
+// funcs are the functions of the templates. quote writes a YAML string
+// scalar, e.g. for channels starting with the # of a comment.
+var funcs = template.FuncMap{"quote": strconv.Quote}
+
+func execute(templates fs.FS, name string, d data) (string, error) {
+	text, err := fs.ReadFile(templates, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read template %s: %w", name, err)
+	}
+	tmpl, err := template.New(name).Delims("[[", "]]").Option("missingkey=error").Funcs(funcs).Parse(string(text))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, d); err != nil {
+		return "", fmt.Errorf("failed to execute template %s: %w", name, err)
+	}
+	return out.String(), nil
+}
+
+func write(root, file, content string) error {
+	target := filepath.Join(root, filepath.FromSlash(file))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", file, err)
+	}
+	if err := os.WriteFile(target, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return nil
+}
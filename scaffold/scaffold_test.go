@@ -0,0 +1,163 @@
+package scaffold_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"gitpkg/deploycheck"
+	"gitpkg/inventory"
+	"gitpkg/scaffold"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testInventory(t *testing.T) *inventory.Inventory {
+	t.Helper()
+	inv, err := inventory.Parse([]byte(`pipeline-environments:
+  - qlik-cloud-services-int-env
+  - qcs-stage-us-east-1
+  - qcs-prod-eu-west-1
+  - name: qcs-prod-fedramp-us-east-1
+    environment: prod
+    region: us-east-1
+    provider: fedramp
+`))
+	assert.NoError(t, err)
+	return inv
+}
+
+func TestGenerate(t *testing.T) {
+	t.Run("Generate writes the component files and renders them for every environment", func(t *testing.T) {
+		// Arrange
+		root, envRoot := t.TempDir(), t.TempDir()
+
+		// Act
+		result, err := scaffold.Generate(scaffold.Options{
+			Root:             root,
+			EnvironmentsRoot: envRoot,
+			Inventory:        testInventory(t),
+			Component:        "hello",
+			ChartURL:         "https://github.com/org/hello/tree/main/chart",
+			Version:          "1.2.3",
+			SealedSecrets:    true,
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Empty(t, result.RenderErrors)
+		assert.Equal(t, []string{"qlik-cloud-services-int-env", "qcs-stage-us-east-1", "qcs-prod-eu-west-1", "qcs-prod-fedramp-us-east-1"}, result.Rendered)
+		assert.Equal(t, []string{
+			"qcs/hello/values.yaml",
+			"qcs/hello/sealed-secrets/prod/eu-west-1/sealed-secrets.yaml",
+			"qcs/hello/sealed-secrets/qcs-int/eu-central-1/sealed-secrets.yaml",
+			"qcs/hello/sealed-secrets/stage/us-east-1/sealed-secrets.yaml",
+		}, result.Files)
+		assert.Len(t, result.ConfFiles, 4)
+		values, err := os.ReadFile(filepath.Join(root, "qcs", "hello", "values.yaml"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(values), "## chart: https://github.com/org/hello/tree/main/chart\n")
+		assert.Contains(t, string(values), `{{ file.Read (path.Join "hello-values/qcs/hello/sealed-secrets/" $environment $region "sealed-secrets.yaml") }}`)
+		conf, err := os.ReadFile(filepath.Join(envRoot, inventory.ConfPath("hello", "qcs-prod-eu-west-1")))
+		assert.NoError(t, err)
+		assert.Equal(t, "version: 1.2.3\nnamespace: hello\nonboarded: \"false\"\n", string(conf))
+	})
+
+	t.Run("Generate refuses to overwrite an existing component", func(t *testing.T) {
+		root := t.TempDir()
+		opts := scaffold.Options{Root: root, Inventory: testInventory(t), Component: "hello", ChartURL: "https://chart"}
+		_, err := scaffold.Generate(opts)
+		assert.NoError(t, err)
+
+		_, err = scaffold.Generate(opts)
+
+		assert.ErrorContains(t, err, "qcs/hello/values.yaml already exists")
+	})
+
+	t.Run("Generate keeps existing conf.yaml files", func(t *testing.T) {
+		for _, force := range []bool{false, true} {
+			// Arrange
+			root, envRoot := t.TempDir(), t.TempDir()
+			live := filepath.Join(envRoot, inventory.ConfPath("hello", "qcs-prod-eu-west-1"))
+			assert.NoError(t, os.MkdirAll(filepath.Dir(live), 0755))
+			assert.NoError(t, os.WriteFile(live, []byte("version: 2.0.0\nnamespace: hello\n"), 0644))
+			if force {
+				_, err := scaffold.Generate(scaffold.Options{Root: root, Inventory: testInventory(t), Component: "hello", ChartURL: "https://chart"})
+				assert.NoError(t, err)
+			}
+
+			// Act
+			result, err := scaffold.Generate(scaffold.Options{Root: root, EnvironmentsRoot: envRoot, Inventory: testInventory(t), Component: "hello", ChartURL: "https://chart", Force: force})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, []string{inventory.ConfPath("hello", "qcs-prod-eu-west-1")}, result.ExistingConfFiles)
+			assert.Len(t, result.ConfFiles, 3)
+			conf, err := os.ReadFile(live)
+			assert.NoError(t, err)
+			assert.Equal(t, "version: 2.0.0\nnamespace: hello\n", string(conf), "force: %t", force)
+		}
+	})
+
+	t.Run("Generate writes conf.yaml files deploy-check parses back", func(t *testing.T) {
+		// Arrange
+		root, envRoot := t.TempDir(), t.TempDir()
+
+		// Act
+		_, err := scaffold.Generate(scaffold.Options{
+			Root:             root,
+			EnvironmentsRoot: envRoot,
+			Inventory:        testInventory(t),
+			Component:        "hello",
+			ChartURL:         "https://chart",
+			Version:          "1.10.0",
+			Namespace:        "hello-ns",
+			SlackChannel:     "#hello",
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		data, err := os.ReadFile(filepath.Join(envRoot, inventory.ConfPath("hello", "qcs-stage-us-east-1")))
+		assert.NoError(t, err)
+		conf, err := deploycheck.ParseConfig(data)
+		assert.NoError(t, err)
+		assert.Equal(t, &deploycheck.ConfigFile{
+			Version:            "1.10.0",
+			Namespace:          "hello-ns",
+			Onboarded:          "false",
+			SlackNotifyChannel: "#hello",
+		}, conf)
+	})
+
+	t.Run("Generate renders the values only for known environments", func(t *testing.T) {
+		inv, err := inventory.Parse([]byte("pipeline-environments:\n  - qcs-stage-us-east-1\n  - sandbox\n"))
+		assert.NoError(t, err)
+
+		result, err := scaffold.Generate(scaffold.Options{Root: t.TempDir(), Inventory: inv, Component: "hello", ChartURL: "https://chart"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"qcs-stage-us-east-1"}, result.Rendered)
+	})
+
+	t.Run("Generate rejects invalid component names", func(t *testing.T) {
+		_, err := scaffold.Generate(scaffold.Options{Root: t.TempDir(), Inventory: testInventory(t), Component: "Hello_World"})
+
+		assert.ErrorContains(t, err, `invalid component name "Hello_World"`)
+	})
+
+	t.Run("Generate reports environments custom templates do not render for", func(t *testing.T) {
+		templates := fstest.MapFS{
+			"values.yaml.tmpl":         {Data: []byte(`{{ if eq (getenv "ENVIRONMENT") "prod" }}{{ file.Read "missing.yaml" }}{{ end }}name: [[ .Component ]]` + "\n")},
+			"sealed-secrets.yaml.tmpl": {Data: []byte("")},
+			"conf.yaml.tmpl":           {Data: []byte("")},
+		}
+
+		result, err := scaffold.Generate(scaffold.Options{Root: t.TempDir(), Inventory: testInventory(t), Component: "hello", Templates: templates})
+
+		assert.NoError(t, err)
+		assert.Len(t, result.RenderErrors, 2)
+		assert.Equal(t, "qcs-prod-eu-west-1", result.RenderErrors[0].Environment)
+		assert.Equal(t, "qcs-prod-fedramp-us-east-1", result.RenderErrors[1].Environment)
+	})
+}
@@ -0,0 +1,107 @@
+package sealedsecrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+)
+
+// gcmTagSize is the size of the authentication tag appended by AES-GCM.
+const gcmTagSize = 16
+
+// ValidateBlob checks that a value looks like a sealed blob produced by kubeseal:
+// base64 of a two byte big-endian length, the RSA-OAEP encrypted session key of
+// that length and the AES-GCM encrypted payload.
+func ValidateBlob(value string) error {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return fmt.Errorf("value is not valid base64: %w", err)
+	}
+	if len(data) < 2 {
+		return fmt.Errorf("sealed blob is too short")
+	}
+	keyLen := int(binary.BigEndian.Uint16(data[:2]))
+	if keyLen < 128 || keyLen%64 != 0 {
+		return fmt.Errorf("sealed blob declares an invalid session key length %d", keyLen)
+	}
+	if len(data) < 2+keyLen+gcmTagSize {
+		return fmt.Errorf("sealed blob is truncated: %d bytes for a %d byte session key", len(data), keyLen)
+	}
+	return nil
+}
+
+// HybridEncrypt encrypts plaintext the way the sealed-secrets controller expects:
+// a random AES-256 session key is RSA-OAEP encrypted with the controller key and
+// the label, and the payload is AES-GCM encrypted with the session key.
+func HybridEncrypt(rnd io.Reader, pubKey *rsa.PublicKey, plaintext, label []byte) ([]byte, error) {
+	sessionKey := make([]byte, 32)
+	if _, err := io.ReadFull(rnd, sessionKey); err != nil {
+		return nil, fmt.Errorf("failed to generate session key: %w", err)
+	}
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	rsaCiphertext, err := rsa.EncryptOAEP(sha256.New(), rnd, pubKey, sessionKey, label)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt session key: %w", err)
+	}
+
+	ciphertext := make([]byte, 2, 2+len(rsaCiphertext)+len(plaintext)+aead.Overhead())
+	binary.BigEndian.PutUint16(ciphertext, uint16(len(rsaCiphertext)))
+	ciphertext = append(ciphertext, rsaCiphertext...)
+	// The session key is never reused, so a zero nonce is safe.
+	zeroNonce := make([]byte, aead.NonceSize())
+	return aead.Seal(ciphertext, zeroNonce, plaintext, nil), nil
+}
+
+// LoadPublicKey reads a controller public key from a PEM file holding either the
+// controller certificate (kubeseal --fetch-cert) or a PKIX / PKCS#1 public key.
+func LoadPublicKey(file string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key %s: %w", file, err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s does not contain a PEM block", file)
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate %s: %w", file, err)
+		}
+		key = cert.PublicKey
+	case "PUBLIC KEY":
+		if key, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			return nil, fmt.Errorf("failed to parse public key %s: %w", file, err)
+		}
+	case "RSA PUBLIC KEY":
+		if key, err = x509.ParsePKCS1PublicKey(block.Bytes); err != nil {
+			return nil, fmt.Errorf("failed to parse public key %s: %w", file, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q in %s", block.Type, file)
+	}
+
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s does not hold an RSA public key", file)
+	}
+	return rsaKey, nil
+}
@@ -0,0 +1,169 @@
+package sealedsecrets
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// NamespaceWideAnnotation scopes a sealed secret to its namespace instead of its name.
+const NamespaceWideAnnotation = "sealedsecrets.bitnami.com/namespace-wide"
+
+// ClusterWideAnnotation lets a sealed secret be unsealed in any namespace.
+const ClusterWideAnnotation = "sealedsecrets.bitnami.com/cluster-wide"
+
+// Values is the sealedSecrets block of a sealed-secrets.yaml values fragment.
+type Values struct {
+	SealedSecrets struct {
+		Annotations   map[string]string `yaml:"annotations"`
+		EncryptedData map[string]string `yaml:"encryptedData"`
+	} `yaml:"sealedSecrets"`
+}
+
+// ParseValues decodes a sealed-secrets.yaml file.
+func ParseValues(data []byte) (*Values, error) {
+	v := &Values{}
+	if err := yaml.Unmarshal(data, v); err != nil {
+		return nil, fmt.Errorf("failed to parse sealed secrets: %w", err)
+	}
+	return v, nil
+}
+
+// LoadValues reads and decodes a sealed-secrets.yaml file.
+func LoadValues(file string) (*Values, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	v, err := ParseValues(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return v, nil
+}
+
+// FindingKind classifies a consistency problem.
+type FindingKind string
+
+const (
+	FindingMissingKey         FindingKind = "missing-key"
+	FindingAnnotationMismatch FindingKind = "annotation-mismatch"
+	FindingMalformedValue     FindingKind = "malformed-value"
+	FindingInvalidFile        FindingKind = "invalid-file"
+)
+
+// Finding is a problem in one region file.
+type Finding struct {
+	Kind    FindingKind
+	Path    string
+	Key     string
+	Message string
+}
+
+func (f Finding) String() string {
+	if f.Key != "" {
+		return fmt.Sprintf("%s: %s: %s: %s", f.Path, f.Kind, f.Key, f.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", f.Path, f.Kind, f.Message)
+}
+
+// ConsistencyReport lists the differences between the region files of a component.
+type ConsistencyReport struct {
+	Component string
+	// Keys is the union of the encryptedData keys of all region files.
+	Keys []string
+	// Annotations is the annotation set shared by most region files.
+	Annotations map[string]string
+	Files       []string
+	Findings    []Finding
+}
+
+// CheckConsistency verifies that all sealed-secrets.yaml files of a component share
+// the same encryptedData keys and annotations and that every value is a sealed blob.
+func CheckConsistency(root, component string) (*ConsistencyReport, error) {
+	entries, _, err := Scan(root, component)
+	if err != nil {
+		return nil, err
+	}
+	report := &ConsistencyReport{Component: component}
+
+	parsed := map[string]*Values{}
+	keys := map[string]bool{}
+	annotationVotes := map[string]int{}
+	annotationSets := map[string]map[string]string{}
+	for _, e := range entries {
+		report.Files = append(report.Files, e.Path)
+		v, err := LoadValues(filepath.Join(root, e.Path))
+		if err != nil {
+			report.Findings = append(report.Findings, Finding{Kind: FindingInvalidFile, Path: e.Path, Message: err.Error()})
+			continue
+		}
+		parsed[e.Path] = v
+		for k := range v.SealedSecrets.EncryptedData {
+			keys[k] = true
+		}
+		id := annotationID(v.SealedSecrets.Annotations)
+		annotationVotes[id]++
+		annotationSets[id] = v.SealedSecrets.Annotations
+	}
+
+	for k := range keys {
+		report.Keys = append(report.Keys, k)
+	}
+	sort.Strings(report.Keys)
+
+	var majority string
+	for id, votes := range annotationVotes {
+		if votes > annotationVotes[majority] || (votes == annotationVotes[majority] && id < majority) {
+			majority = id
+		}
+	}
+	report.Annotations = annotationSets[majority]
+
+	for _, file := range report.Files {
+		v, ok := parsed[file]
+		if !ok {
+			continue
+		}
+		for _, k := range report.Keys {
+			value, ok := v.SealedSecrets.EncryptedData[k]
+			if !ok {
+				report.Findings = append(report.Findings, Finding{Kind: FindingMissingKey, Path: file, Key: k, Message: "key is present in other regions"})
+				continue
+			}
+			if err := ValidateBlob(value); err != nil {
+				report.Findings = append(report.Findings, Finding{Kind: FindingMalformedValue, Path: file, Key: k, Message: err.Error()})
+			}
+		}
+		if id := annotationID(v.SealedSecrets.Annotations); id != majority {
+			report.Findings = append(report.Findings, Finding{
+				Kind:    FindingAnnotationMismatch,
+				Path:    file,
+				Message: fmt.Sprintf("annotations {%s} differ from {%s}", id, majority),
+			})
+		}
+	}
+	return report, nil
+}
+
+func annotationID(annotations map[string]string) string {
+	var pairs []string
+	for k, v := range annotations {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Write writes the keys of the component and its findings.
+func (r *ConsistencyReport) Write(w io.Writer) {
+	fmt.Fprintf(w, "%s: %d region files, keys [%s]\n", r.Component, len(r.Files), strings.Join(r.Keys, ", "))
+	for _, f := range r.Findings {
+		fmt.Fprintf(w, "  %s\n", f)
+	}
+}
@@ -0,0 +1,100 @@
+package sealedsecrets_test
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitpkg/sealedsecrets"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeSealedSecrets(t *testing.T, root, component, tier, region, content string) {
+	t.Helper()
+	file := filepath.Join(root, sealedsecrets.File(component, tier, region))
+	assert.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+	assert.NoError(t, os.WriteFile(file, []byte(content), 0600))
+}
+
+func fakeBlob(keyLen int) string {
+	data := make([]byte, 2+keyLen+32)
+	data[0], data[1] = byte(keyLen>>8), byte(keyLen)
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func TestValidateBlob(t *testing.T) {
+	t.Run("ValidateBlob accepts the checked-in sealed values", func(t *testing.T) {
+		v, err := sealedsecrets.LoadValues("../qcs/dataprep-proxy/sealed-secrets/stage/us-east-1/sealed-secrets.yaml")
+		assert.NoError(t, err)
+		assert.NoError(t, sealedsecrets.ValidateBlob(v.SealedSecrets.EncryptedData["mongodbUri"]))
+	})
+
+	tests := map[string]string{
+		"not base64":         "not base64!",
+		"plain text":         base64.StdEncoding.EncodeToString([]byte("password")),
+		"truncated":          fakeBlob(512)[:100],
+		"invalid key length": fakeBlob(100),
+	}
+	for name, value := range tests {
+		t.Run("ValidateBlob rejects "+name, func(t *testing.T) {
+			assert.Error(t, sealedsecrets.ValidateBlob(value))
+		})
+	}
+}
+
+func TestCheckConsistency(t *testing.T) {
+	t.Run("CheckConsistency reports per region differences", func(t *testing.T) {
+		// Arrange
+		root := t.TempDir()
+		blob := fakeBlob(512)
+		writeSealedSecrets(t, root, "c", "stage", "us-east-1", `sealedSecrets:
+    annotations:
+        sealedsecrets.bitnami.com/namespace-wide: "true"
+    encryptedData:
+        redisPassword: `+blob+`
+        tokenEncryptionkey: `+blob+`
+`)
+		writeSealedSecrets(t, root, "c", "prod", "us-east-1", `sealedSecrets:
+    annotations:
+        sealedsecrets.bitnami.com/namespace-wide: "true"
+    encryptedData:
+        redisPassword: `+blob+`
+        tokenEncryptionkey: plaintext
+`)
+		writeSealedSecrets(t, root, "c", "prod", "eu-west-1", `sealedSecrets:
+    encryptedData:
+        redisPassword: `+blob+`
+`)
+
+		// Act
+		report, err := sealedsecrets.CheckConsistency(root, "c")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"redisPassword", "tokenEncryptionkey"}, report.Keys)
+		assert.Equal(t, map[string]string{sealedsecrets.NamespaceWideAnnotation: "true"}, report.Annotations)
+
+		kinds := map[string]sealedsecrets.FindingKind{}
+		for _, f := range report.Findings {
+			kinds[f.Path+":"+f.Key] = f.Kind
+		}
+		assert.Equal(t, map[string]sealedsecrets.FindingKind{
+			sealedsecrets.File("c", "prod", "us-east-1") + ":tokenEncryptionkey": sealedsecrets.FindingMalformedValue,
+			sealedsecrets.File("c", "prod", "eu-west-1") + ":tokenEncryptionkey": sealedsecrets.FindingMissingKey,
+			sealedsecrets.File("c", "prod", "eu-west-1") + ":":                   sealedsecrets.FindingAnnotationMismatch,
+		}, kinds)
+	})
+
+	t.Run("CheckConsistency flags the incomplete data-connector-odbc region", func(t *testing.T) {
+		report, err := sealedsecrets.CheckConsistency("..", "data-connector-odbc")
+
+		assert.NoError(t, err)
+		assert.Len(t, report.Findings, 3)
+		for _, f := range report.Findings {
+			assert.Equal(t, sealedsecrets.FindingMissingKey, f.Kind)
+			assert.Equal(t, sealedsecrets.File("data-connector-odbc", "prod", "ap-south-1"), f.Path)
+		}
+	})
+}
@@ -0,0 +1,205 @@
+package sealedsecrets
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gitpkg/inventory"
+)
+
+// Status of a component/environment cell in the coverage matrix.
+type Status string
+
+const (
+	// StatusOK means the environment uses sealed secrets and the region file exists.
+	StatusOK Status = "ok"
+	// StatusMissing means the environment uses sealed secrets but the region file does not exist.
+	StatusMissing Status = "missing"
+	// StatusExtra means a region file exists although the environment does not use sealed secrets.
+	StatusExtra Status = "extra"
+	// StatusNotRequired means the environment does not use sealed secrets and has no region file.
+	StatusNotRequired Status = "not-required"
+)
+
+// Cell is the coverage of one component in one inventory environment.
+type Cell struct {
+	Component   string
+	Environment inventory.Environment
+	// Deployable is false when the component has no conf.yaml for the environment.
+	Deployable bool
+	Status     Status
+	Path       string
+}
+
+// CoverageReport is the sealed-secrets coverage of a set of components.
+type CoverageReport struct {
+	Cells []Cell
+	// Orphaned are region files whose tier and region match no inventory environment.
+	Orphaned []Entry
+	// Stray are files in a sealed-secrets tree that do not follow the <tier>/<region>/sealed-secrets.yaml layout.
+	Stray []string
+}
+
+// Missing returns the deployable cells which would render without secrets.
+func (r *CoverageReport) Missing() []Cell {
+	var cells []Cell
+	for _, c := range r.Cells {
+		if c.Status == StatusMissing && c.Deployable {
+			cells = append(cells, c)
+		}
+	}
+	return cells
+}
+
+// Failed reports whether a deployable environment would render without secrets.
+func (r *CoverageReport) Failed() bool {
+	return len(r.Missing()) > 0
+}
+
+// CoverageOptions configures Coverage.
+type CoverageOptions struct {
+	// Root is the repository root containing the qcs directory.
+	Root string
+	// Components to check, all components under qcs when empty.
+	Components []string
+	// EnvironmentsRoot is an optional gitops-environments checkout. When set, only
+	// environments with a components/<component>/<env>/conf.yaml are deployable.
+	EnvironmentsRoot string
+}
+
+// Coverage cross-references the sealed-secrets trees against the inventory and the
+// useSealedSecrets condition of each values template.
+func Coverage(inv *inventory.Inventory, opts CoverageOptions) (*CoverageReport, error) {
+	components := opts.Components
+	if len(components) == 0 {
+		var err error
+		if components, err = Components(opts.Root); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &CoverageReport{}
+	for _, component := range components {
+		values, err := os.ReadFile(filepath.Join(opts.Root, "qcs", component, "values.yaml"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read values of %s: %w", component, err)
+		}
+		entries, stray, err := Scan(opts.Root, component)
+		if err != nil {
+			return nil, err
+		}
+		report.Stray = append(report.Stray, stray...)
+
+		existing := map[string]Entry{}
+		for _, e := range entries {
+			existing[e.Tier+"/"+e.Region] = e
+		}
+		matched := map[string]bool{}
+
+		for _, env := range inv.List() {
+			if !env.Known() {
+				continue
+			}
+			uses, err := UsesSealedSecrets(string(values), env)
+			if err != nil {
+				return nil, fmt.Errorf("%s in %s: %w", component, env.Name, err)
+			}
+			key := env.Tier + "/" + env.Region
+			entry, exists := existing[key]
+			matched[key] = true
+
+			cell := Cell{
+				Component:   component,
+				Environment: env,
+				Deployable:  deployable(opts.EnvironmentsRoot, component, env.Name),
+				Path:        File(component, env.Tier, env.Region),
+			}
+			switch {
+			case uses && exists:
+				cell.Status, cell.Path = StatusOK, entry.Path
+			case uses:
+				cell.Status = StatusMissing
+			case exists:
+				cell.Status, cell.Path = StatusExtra, entry.Path
+			default:
+				cell.Status = StatusNotRequired
+			}
+			report.Cells = append(report.Cells, cell)
+		}
+
+		for _, e := range entries {
+			if !matched[e.Tier+"/"+e.Region] {
+				report.Orphaned = append(report.Orphaned, e)
+			}
+		}
+	}
+	return report, nil
+}
+
+func deployable(environmentsRoot, component, environment string) bool {
+	if environmentsRoot == "" {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(environmentsRoot, inventory.ConfPath(component, environment)))
+	return err == nil
+}
+
+// WriteMatrix writes the coverage as a component by environment table followed by
+// the problems found.
+func (r *CoverageReport) WriteMatrix(w io.Writer) {
+	byComponent := map[string][]Cell{}
+	var components []string
+	for _, c := range r.Cells {
+		if _, ok := byComponent[c.Component]; !ok {
+			components = append(components, c.Component)
+		}
+		byComponent[c.Component] = append(byComponent[c.Component], c)
+	}
+	sort.Strings(components)
+
+	for _, component := range components {
+		fmt.Fprintf(w, "%s\n", component)
+		for _, c := range byComponent[component] {
+			status := string(c.Status)
+			if !c.Deployable {
+				status += " (not deployed)"
+			}
+			fmt.Fprintf(w, "  %-40s %-8s %-16s %s\n", c.Environment.Name, c.Environment.Tier, c.Environment.Region, status)
+		}
+	}
+
+	var problems []string
+	for _, c := range r.Missing() {
+		problems = append(problems, fmt.Sprintf("missing: %s has no %s", c.Environment.Name, c.Path))
+	}
+	for _, c := range r.Cells {
+		if c.Status == StatusExtra {
+			problems = append(problems, fmt.Sprintf("extra: %s is not used in %s", c.Path, c.Environment.Name))
+		}
+	}
+	for _, e := range r.Orphaned {
+		problems = append(problems, fmt.Sprintf("orphaned: %s matches no inventory environment", e.Path))
+	}
+	for _, p := range r.Stray {
+		problems = append(problems, fmt.Sprintf("orphaned: %s is not a <env>/<region>/%s file", p, FileName))
+	}
+	if len(problems) > 0 {
+		fmt.Fprintf(w, "\n%s\n", strings.Join(dedupe(problems), "\n"))
+	}
+}
+
+func dedupe(lines []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, l := range lines {
+		if !seen[l] {
+			seen[l] = true
+			out = append(out, l)
+		}
+	}
+	return out
+}
@@ -0,0 +1,110 @@
+package sealedsecrets_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitpkg/inventory"
+	"gitpkg/sealedsecrets"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testInventory(t *testing.T) *inventory.Inventory {
+	t.Helper()
+	inv, err := inventory.Parse([]byte(`pipeline-environments:
+  - qlik-cloud-services-int-env
+  - qcs-stage-us-east-1
+  - qcs-stage-us-west-2
+  - qcs-prod-ap-south-1
+  - qcs-prod-eu-west-1
+`))
+	assert.NoError(t, err)
+	return inv
+}
+
+func statuses(report *sealedsecrets.CoverageReport, component string) map[string]sealedsecrets.Status {
+	out := map[string]sealedsecrets.Status{}
+	for _, c := range report.Cells {
+		if c.Component == component {
+			out[c.Environment.Name] = c.Status
+		}
+	}
+	return out
+}
+
+func TestUsesSealedSecrets(t *testing.T) {
+	values, err := os.ReadFile("../qcs/dataprep-proxy/values.yaml")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		env      inventory.Environment
+		expected bool
+	}{
+		{inventory.FromName("qcs-stage-us-east-1"), true},
+		{inventory.FromName("qlik-cloud-services-int-env"), true},
+		{inventory.FromName("qcs-prod-us-east-1"), false},
+		{inventory.Environment{Name: "fed", Tier: "stage", Region: "us-east-1", Provider: "fedramp"}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.env.Name, func(t *testing.T) {
+			uses, err := sealedsecrets.UsesSealedSecrets(string(values), tc.env)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, uses)
+		})
+	}
+
+	t.Run("templates without the variable never use sealed secrets", func(t *testing.T) {
+		uses, err := sealedsecrets.UsesSealedSecrets("{{ $region := getenv \"REGION\" -}}\n", inventory.FromName("qcs-stage-us-east-1"))
+		assert.NoError(t, err)
+		assert.False(t, uses)
+	})
+}
+
+func TestCoverage(t *testing.T) {
+	t.Run("Coverage reports missing, extra and orphaned region files", func(t *testing.T) {
+		// Act
+		report, err := sealedsecrets.Coverage(testInventory(t), sealedsecrets.CoverageOptions{Root: ".."})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]sealedsecrets.Status{
+			"qlik-cloud-services-int-env": sealedsecrets.StatusOK,
+			"qcs-stage-us-east-1":         sealedsecrets.StatusOK,
+			"qcs-stage-us-west-2":         sealedsecrets.StatusMissing,
+			"qcs-prod-ap-south-1":         sealedsecrets.StatusExtra,
+			"qcs-prod-eu-west-1":          sealedsecrets.StatusNotRequired,
+		}, statuses(report, "dataprep-proxy"))
+		assert.Equal(t, sealedsecrets.StatusNotRequired, statuses(report, "bonjour-world")["qcs-prod-eu-west-1"])
+
+		orphaned := map[string]bool{}
+		for _, e := range report.Orphaned {
+			orphaned[e.Component+":"+e.Tier+"/"+e.Region] = true
+		}
+		assert.True(t, orphaned["dataprep-proxy:stage/eu-west-1"])
+		assert.True(t, orphaned["data-connector-odbc:prod/eu-north-1"])
+		assert.True(t, report.Failed())
+	})
+
+	t.Run("Coverage only fails for deployable environments", func(t *testing.T) {
+		// Arrange
+		envRoot := t.TempDir()
+		for _, env := range []string{"qcs-stage-us-east-1", "qcs-prod-ap-south-1"} {
+			dir := filepath.Join(envRoot, "components", "dataprep-proxy", env)
+			assert.NoError(t, os.MkdirAll(dir, 0755))
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "conf.yaml"), []byte("version: 1.0.0\n"), 0600))
+		}
+
+		// Act
+		report, err := sealedsecrets.Coverage(testInventory(t), sealedsecrets.CoverageOptions{
+			Root:             "..",
+			Components:       []string{"dataprep-proxy"},
+			EnvironmentsRoot: envRoot,
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.False(t, report.Failed())
+	})
+}
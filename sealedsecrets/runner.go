@@ -0,0 +1,195 @@
+package sealedsecrets
+
+import (
+	"crypto/rsa"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gitpkg/inventory"
+	"gitpkg/qgit"
+	"gitpkg/utilities"
+
+	"gopkg.in/yaml.v2"
+)
+
+// SetupCoverage registers the flags of the sealed-secrets-coverage command;
+// the command fails when a deployable environment would render without secrets.
+func SetupCoverage(fs *flag.FlagSet) func(args []string) error {
+	root := fs.String("root", ".", "Repository root containing the qcs directory")
+	inventoryFile := fs.String("inventory", "gitops-environments/"+inventory.DefaultFile, "Path of the environment inventory")
+	environmentsRoot := fs.String("environments-root", "", "gitops-environments checkout used to find deployed components")
+	components := fs.String("components", "", "Comma separated components to check, all when empty")
+	return func(args []string) error {
+
+		inv, err := inventory.Load(*inventoryFile)
+		if err != nil {
+			return err
+		}
+		opts := CoverageOptions{Root: *root, EnvironmentsRoot: *environmentsRoot}
+		if *components != "" {
+			opts.Components = strings.Split(*components, ",")
+		}
+		report, err := Coverage(inv, opts)
+		if err != nil {
+			return err
+		}
+		report.WriteMatrix(os.Stdout)
+		if report.Failed() {
+			return fmt.Errorf("%d deployable environments would render without sealed secrets", len(report.Missing()))
+		}
+		return nil
+	}
+}
+
+// SetupCheck registers the flags of the sealed-secrets-check command; the command fails when
+// the region files of a component differ in keys or annotations or hold malformed values.
+func SetupCheck(fs *flag.FlagSet) func(args []string) error {
+	root := fs.String("root", ".", "Repository root containing the qcs directory")
+	components := fs.String("components", "", "Comma separated components to check, all when empty")
+	return func(args []string) error {
+
+		names, err := Components(*root)
+		if err != nil {
+			return err
+		}
+		if *components != "" {
+			names = strings.Split(*components, ",")
+		}
+		findings := 0
+		for _, component := range names {
+			report, err := CheckConsistency(*root, component)
+			if err != nil {
+				return err
+			}
+			if len(report.Files) == 0 {
+				continue
+			}
+			report.Write(os.Stdout)
+			findings += len(report.Findings)
+		}
+		if findings > 0 {
+			return fmt.Errorf("%d sealed secrets consistency problems found", findings)
+		}
+		return nil
+	}
+}
+
+// SetupSeal registers the flags of the sealed-secrets-seal command; the command seals plaintext
+// values with the controller certificate of each region and writes them into the tree.
+func SetupSeal(fs *flag.FlagSet) func(args []string) error {
+	root := fs.String("root", ".", "Repository root containing the qcs directory")
+	component := fs.String("component", "", "Component whose sealed secrets are updated")
+	namespace := fs.String("namespace", "", "Namespace of the sealed secret")
+	name := fs.String("name", "", "Name of the sealed secret, <component>-sealed-secrets by default")
+	certsDir := fs.String("certs-dir", "", "Directory of controller certificates laid out as <env>/<region>.pem")
+	input := fs.String("input", "", "YAML file mapping encryptedData keys to plaintext values")
+	scope := fs.String("scope", string(ScopeNamespaceWide), "Scope of region files that do not exist yet: strict, namespace-wide or cluster-wide")
+	var certs, values utilities.MultiFlag
+	fs.Var(&certs, "cert", "Controller certificate as <env>/<region>=<file.pem>, repeatable")
+	fs.Var(&values, "set", "Plaintext value as <key>=<value>, repeatable")
+	return func(args []string) error {
+		if *component == "" {
+			return utilities.NewUsageError("--component is required")
+		}
+		defaultScope, err := ParseScope(*scope)
+		if err != nil {
+			return utilities.NewUsageError("invalid --scope: %v", err)
+		}
+
+		opts := SealOptions{
+			Root:         *root,
+			Component:    *component,
+			Namespace:    *namespace,
+			Name:         *name,
+			DefaultScope: defaultScope,
+			Certs:        map[string]*rsa.PublicKey{},
+			Secrets:      map[string]string{},
+		}
+
+		if *certsDir != "" {
+			files, err := filepath.Glob(filepath.Join(*certsDir, "*", "*.pem"))
+			if err != nil {
+				return err
+			}
+			for _, file := range files {
+				region := filepath.Base(filepath.Dir(file)) + "/" + strings.TrimSuffix(filepath.Base(file), ".pem")
+				certs = append(certs, region+"="+file)
+			}
+		}
+		for _, c := range certs {
+			region, file, found := strings.Cut(c, "=")
+			if !found {
+				return utilities.NewUsageError("invalid --cert %q, expected <env>/<region>=<file.pem>", c)
+			}
+			key, err := LoadPublicKey(file)
+			if err != nil {
+				return err
+			}
+			opts.Certs[region] = key
+		}
+		if len(opts.Certs) == 0 {
+			return utilities.NewUsageError("no controller certificates given, use --cert or --certs-dir")
+		}
+
+		if *input != "" {
+			data, err := os.ReadFile(*input)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", *input, err)
+			}
+			if err := yaml.Unmarshal(data, &opts.Secrets); err != nil {
+				return fmt.Errorf("failed to parse %s: %w", *input, err)
+			}
+		}
+		for _, v := range values {
+			key, value, found := strings.Cut(v, "=")
+			if !found {
+				return utilities.NewUsageError("invalid --set %q, expected <key>=<value>", v)
+			}
+			opts.Secrets[key] = value
+		}
+
+		written, err := SealTree(opts)
+		for _, file := range written {
+			fmt.Printf("sealed %d keys into %s\n", len(opts.Secrets), file)
+		}
+		return err
+	}
+}
+
+// SetupScan registers the flags of the sealed-secrets-scan command; the command fails when a
+// file under qcs/ holds material that looks like an unsealed secret.
+func SetupScan(fs *flag.FlagSet) func(args []string) error {
+	root := fs.String("root", ".", "Repository root containing the qcs directory")
+	ref := fs.String("ref", "", "Scan the files committed at this reference or commit instead of the working tree")
+	return func(args []string) error {
+
+		fsys := os.DirFS(*root)
+		if *ref != "" {
+			client, err := qgit.NewClient(qgit.WithRepoPath(*root))
+			if err != nil {
+				return err
+			}
+			if err := client.Open(); err != nil {
+				return err
+			}
+			if fsys, err = client.FS(*ref); err != nil {
+				return err
+			}
+		}
+		leaks, err := ScanLeaks(fsys, "qcs")
+		if err != nil {
+			return err
+		}
+		for _, leak := range leaks {
+			fmt.Println(leak)
+		}
+		if len(leaks) > 0 {
+			return fmt.Errorf("%d possible unsealed secrets found; seal them or mark false positives with # %s", len(leaks), IgnoreMarker)
+		}
+		fmt.Println("no unsealed secrets found")
+		return nil
+	}
+}
@@ -0,0 +1,160 @@
+package sealedsecrets
+
+import (
+	"fmt"
+	"io/fs"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// IgnoreMarker is the comment that excludes a line from the leak scan, for
+// values that only look like secrets.
+const IgnoreMarker = "secrets-scan:ignore"
+
+// Leak is committed material that looks like an unsealed secret.
+type Leak struct {
+	File string
+	Line int
+	Kind string
+}
+
+func (l Leak) String() string {
+	return fmt.Sprintf("%s:%d: %s", l.File, l.Line, l.Kind)
+}
+
+var (
+	// secretPatterns are well-known formats of private keys and tokens.
+	secretPatterns = []struct {
+		kind    string
+		pattern *regexp.Regexp
+	}{
+		{"private key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY( BLOCK)?-----`)},
+		{"AWS access key", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+		{"GitHub token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+		{"Slack token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+		{"JSON web token", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+		{"credentials in URL", regexp.MustCompile(`[a-z][a-z0-9+.-]*://[^\s:/@"']+:[^\s:/@"'{}]+@`)},
+	}
+	// secretManifest matches the kind of an unsealed Kubernetes Secret.
+	secretManifest = regexp.MustCompile(`^\s*kind:\s*["']?Secret["']?\s*$`)
+	// keyValue matches a `key: value` line, including list items.
+	keyValue = regexp.MustCompile(`^\s*(?:-\s+)?["']?([\w.-]+)["']?\s*:\s+(.+?)\s*$`)
+	// secretKey matches keys expected to hold a credential.
+	secretKey = regexp.MustCompile(`(?i)(password|passwd|secret|token|api_?key|private_?key|credential)`)
+	// plainValue matches literals that are not credentials: names and settings
+	// without digits, Kubernetes resource names, booleans and numbers.
+	plainValue = regexp.MustCompile(`^([A-Za-z_.]+|[a-z0-9]+(-[a-z0-9]+)+|true|false|yes|no|~|null|[0-9.]+[A-Za-z]*)$`)
+	// idKey matches keys holding random identifiers such as tenant ids, which
+	// are not checked for entropy.
+	idKey = regexp.MustCompile(`(Id|ID|_id|Ids|IDs|_ids|uuid|UUID)$`)
+	// token matches base64 and url-safe character runs checked for entropy.
+	token = regexp.MustCompile(`[A-Za-z0-9+/=_-]{32,}`)
+)
+
+// minEntropy is the Shannon entropy in bits per character above which a long
+// token is considered random. Hex digests stay below it, base64 keys above.
+const minEntropy = 4.3
+
+// ScanLeaks reports every file under dir in fsys holding material that looks like an
+// unsealed secret: private keys, well-known token formats, Secret manifests,
+// literal values of credential keys and long random strings. Sealed blobs and
+// template expressions are not reported.
+func ScanLeaks(fsys fs.FS, dir string) ([]Leak, error) {
+	var leaks []Leak
+	err := fs.WalkDir(fsys, dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		leaks = append(leaks, ScanLeaksText(file, string(data))...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(leaks, func(i, j int) bool { return leaks[i].File < leaks[j].File })
+	return leaks, nil
+}
+
+// ScanLeaksText reports the lines of a file that look like unsealed secrets.
+func ScanLeaksText(file, text string) []Leak {
+	var leaks []Leak
+	for i, line := range strings.Split(text, "\n") {
+		if strings.Contains(line, IgnoreMarker) {
+			continue
+		}
+		if kind := scanLine(line); kind != "" {
+			leaks = append(leaks, Leak{File: file, Line: i + 1, Kind: kind})
+		}
+	}
+	return leaks
+}
+
+func scanLine(line string) string {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return ""
+	}
+	for _, p := range secretPatterns {
+		if p.pattern.MatchString(line) {
+			return p.kind
+		}
+	}
+	if secretManifest.MatchString(line) {
+		return "unsealed Secret manifest"
+	}
+	// template expressions render their value from a datasource at deploy time
+	line = templateAction.ReplaceAllString(line, "")
+	if m := keyValue.FindStringSubmatch(line); m != nil {
+		value := strings.Trim(stripComment(m[2]), `"'`)
+		if isSealed(value) || idKey.MatchString(m[1]) {
+			return ""
+		}
+		if secretKey.MatchString(m[1]) && value != "" && !strings.HasPrefix(value, "|") && !strings.HasPrefix(value, ">") && !plainValue.MatchString(value) {
+			return fmt.Sprintf("literal value for %s", m[1])
+		}
+	}
+	for _, t := range token.FindAllString(line, -1) {
+		if !isSealed(t) && entropy(t) >= minEntropy {
+			return "high entropy string"
+		}
+	}
+	return ""
+}
+
+// templateAction matches a gomplate action.
+var templateAction = regexp.MustCompile(`\{\{.*?\}\}`)
+
+func stripComment(value string) string {
+	if i := strings.Index(value, " #"); i >= 0 {
+		return strings.TrimSpace(value[:i])
+	}
+	return value
+}
+
+func isSealed(value string) bool {
+	return len(value) > 100 && ValidateBlob(value) == nil
+}
+
+// entropy returns the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+	var h float64
+	n := float64(len(s))
+	for _, c := range counts {
+		p := float64(c) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
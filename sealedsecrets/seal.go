@@ -0,0 +1,285 @@
+package sealedsecrets
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Scope restricts where a sealed secret may be unsealed.
+type Scope string
+
+const (
+	// ScopeStrict binds the secret to its name and namespace.
+	ScopeStrict Scope = "strict"
+	// ScopeNamespaceWide binds the secret to its namespace only.
+	ScopeNamespaceWide Scope = "namespace-wide"
+	// ScopeClusterWide allows the secret to be unsealed anywhere.
+	ScopeClusterWide Scope = "cluster-wide"
+)
+
+// ParseScope parses strict, namespace-wide or cluster-wide.
+func ParseScope(s string) (Scope, error) {
+	switch scope := Scope(s); scope {
+	case ScopeStrict, ScopeNamespaceWide, ScopeClusterWide:
+		return scope, nil
+	default:
+		return "", fmt.Errorf("unknown scope %q: expected %s, %s or %s", s, ScopeStrict, ScopeNamespaceWide, ScopeClusterWide)
+	}
+}
+
+// ScopeFromAnnotations returns the scope selected by the sealed-secrets annotations.
+func ScopeFromAnnotations(annotations map[string]string) Scope {
+	switch {
+	case annotations[ClusterWideAnnotation] == "true":
+		return ScopeClusterWide
+	case annotations[NamespaceWideAnnotation] == "true":
+		return ScopeNamespaceWide
+	default:
+		return ScopeStrict
+	}
+}
+
+// Label returns the OAEP label the controller expects for the scope.
+func (s Scope) Label(namespace, name string) []byte {
+	switch s {
+	case ScopeClusterWide:
+		return nil
+	case ScopeNamespaceWide:
+		return []byte(namespace)
+	default:
+		return []byte(namespace + "/" + name)
+	}
+}
+
+// Annotations returns the annotations that select the scope.
+func (s Scope) Annotations() map[string]string {
+	switch s {
+	case ScopeClusterWide:
+		return map[string]string{ClusterWideAnnotation: "true"}
+	case ScopeNamespaceWide:
+		return map[string]string{NamespaceWideAnnotation: "true"}
+	default:
+		return nil
+	}
+}
+
+// Seal encrypts a single value and returns it base64 encoded for encryptedData.
+func Seal(rnd io.Reader, pubKey *rsa.PublicKey, scope Scope, namespace, name, plaintext string) (string, error) {
+	ciphertext, err := HybridEncrypt(rnd, pubKey, []byte(plaintext), scope.Label(namespace, name))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// SealOptions configures SealTree.
+type SealOptions struct {
+	// Root is the repository root containing the qcs directory.
+	Root      string
+	Component string
+	// Namespace and Name identify the Secret the controller creates; Name defaults
+	// to "<component>-sealed-secrets".
+	Namespace string
+	Name      string
+	// Certs maps "<env>/<region>" to the public key of the controller in that region.
+	Certs map[string]*rsa.PublicKey
+	// Secrets are the plaintext values to seal, keyed by encryptedData key.
+	Secrets map[string]string
+	// DefaultScope is used for region files that do not exist yet.
+	DefaultScope Scope
+	// Rand is the entropy source, crypto/rand when nil.
+	Rand io.Reader
+}
+
+// SealTree seals the secrets for every region with a certificate and writes them
+// into qcs/<component>/sealed-secrets/<env>/<region>/sealed-secrets.yaml. Keys that
+// are not being sealed are preserved. It returns the files written.
+func SealTree(opts SealOptions) ([]string, error) {
+	if opts.Name == "" {
+		opts.Name = opts.Component + "-sealed-secrets"
+	}
+	if opts.DefaultScope == "" {
+		opts.DefaultScope = ScopeNamespaceWide
+	}
+	if opts.Rand == nil {
+		opts.Rand = rand.Reader
+	}
+	if len(opts.Secrets) == 0 {
+		return nil, fmt.Errorf("no secrets to seal")
+	}
+
+	regions := make([]string, 0, len(opts.Certs))
+	for r := range opts.Certs {
+		regions = append(regions, r)
+	}
+	sort.Strings(regions)
+
+	var written []string
+	for _, region := range regions {
+		tier, name, found := strings.Cut(region, "/")
+		if !found {
+			return written, fmt.Errorf("invalid region %q, expected <env>/<region>", region)
+		}
+		rel := File(opts.Component, tier, name)
+		file := filepath.Join(opts.Root, rel)
+		existing, err := os.ReadFile(file)
+		if err != nil && !os.IsNotExist(err) {
+			return written, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		out, err := updateValues(existing, opts.DefaultScope, func(scope Scope, key, plaintext string) (string, error) {
+			if scope != ScopeClusterWide && opts.Namespace == "" {
+				return "", fmt.Errorf("a namespace is required for %s sealed secrets", scope)
+			}
+			return Seal(opts.Rand, opts.Certs[region], scope, opts.Namespace, opts.Name, plaintext)
+		}, opts.Secrets)
+		if err != nil {
+			return written, fmt.Errorf("%s: %w", rel, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return written, err
+		}
+		if err := os.WriteFile(file, out, 0644); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", file, err)
+		}
+		written = append(written, rel)
+	}
+	return written, nil
+}
+
+type sealFunc func(scope Scope, key, plaintext string) (string, error)
+
+// updateValues replaces the given encryptedData entries of a sealed-secrets.yaml
+// document, keeping its other content, annotations and indentation.
+func updateValues(existing []byte, defaultScope Scope, seal sealFunc, secrets map[string]string) ([]byte, error) {
+	doc := &yamlv3.Node{}
+	if len(bytes.TrimSpace(existing)) > 0 {
+		if err := yamlv3.Unmarshal(existing, doc); err != nil {
+			return nil, fmt.Errorf("failed to parse sealed secrets: %w", err)
+		}
+	}
+	if doc.Kind == 0 {
+		doc.Kind = yamlv3.DocumentNode
+		doc.Content = []*yamlv3.Node{{Kind: yamlv3.MappingNode}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yamlv3.MappingNode {
+		return nil, fmt.Errorf("sealed secrets document is not a mapping")
+	}
+
+	sealed, created := mappingValue(root, "sealedSecrets")
+	annotations, _ := mappingValue(sealed, "annotations")
+	if created {
+		for k, v := range defaultScope.Annotations() {
+			setScalar(annotations, k, v, yamlv3.DoubleQuotedStyle)
+		}
+	}
+	current := map[string]string{}
+	if err := annotations.Decode(&current); err != nil {
+		return nil, fmt.Errorf("failed to decode annotations: %w", err)
+	}
+	scope := ScopeFromAnnotations(current)
+	if len(annotations.Content) == 0 {
+		removeKey(sealed, "annotations")
+	}
+
+	encrypted, _ := mappingValue(sealed, "encryptedData")
+	keys := make([]string, 0, len(secrets))
+	for k := range secrets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, err := seal(scope, key, secrets[key])
+		if err != nil {
+			return nil, fmt.Errorf("failed to seal %s: %w", key, err)
+		}
+		setScalar(encrypted, key, value, 0)
+	}
+	sortMapping(encrypted)
+
+	var out bytes.Buffer
+	enc := yamlv3.NewEncoder(&out)
+	enc.SetIndent(detectIndent(existing))
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to encode sealed secrets: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// mappingValue returns the mapping stored under key, creating it when absent.
+func mappingValue(m *yamlv3.Node, key string) (node *yamlv3.Node, created bool) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			v := m.Content[i+1]
+			if v.Kind != yamlv3.MappingNode {
+				// `encryptedData: ~` and similar placeholders become empty mappings.
+				*v = yamlv3.Node{Kind: yamlv3.MappingNode}
+			}
+			return v, false
+		}
+	}
+	v := &yamlv3.Node{Kind: yamlv3.MappingNode}
+	m.Content = append(m.Content, &yamlv3.Node{Kind: yamlv3.ScalarNode, Value: key}, v)
+	return v, true
+}
+
+func setScalar(m *yamlv3.Node, key, value string, style yamlv3.Style) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1].Kind = yamlv3.ScalarNode
+			m.Content[i+1].Tag = ""
+			m.Content[i+1].Value = value
+			return
+		}
+	}
+	m.Content = append(m.Content,
+		&yamlv3.Node{Kind: yamlv3.ScalarNode, Value: key},
+		&yamlv3.Node{Kind: yamlv3.ScalarNode, Value: value, Style: style},
+	)
+}
+
+func removeKey(m *yamlv3.Node, key string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+func sortMapping(m *yamlv3.Node) {
+	pairs := make([][2]*yamlv3.Node, 0, len(m.Content)/2)
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		pairs = append(pairs, [2]*yamlv3.Node{m.Content[i], m.Content[i+1]})
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i][0].Value < pairs[j][0].Value })
+	m.Content = m.Content[:0]
+	for _, p := range pairs {
+		m.Content = append(m.Content, p[0], p[1])
+	}
+}
+
+// detectIndent returns the indentation of the first nested line, 4 when unknown.
+func detectIndent(data []byte) int {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed != "" && len(trimmed) < len(line) {
+			return len(line) - len(trimmed)
+		}
+	}
+	return 4
+}
@@ -0,0 +1,186 @@
+package sealedsecrets_test
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gitpkg/sealedsecrets"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	return key
+}
+
+func writeCert(t *testing.T, key *rsa.PrivateKey, file string) {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sealed-secret"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+	assert.NoError(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+}
+
+// unseal reverses sealedsecrets.HybridEncrypt the way the controller does.
+func unseal(t *testing.T, key *rsa.PrivateKey, value string, label []byte) string {
+	t.Helper()
+	data, err := base64.StdEncoding.DecodeString(value)
+	assert.NoError(t, err)
+	keyLen := int(binary.BigEndian.Uint16(data))
+	sessionKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, data[2:2+keyLen], label)
+	assert.NoError(t, err)
+	block, err := aes.NewCipher(sessionKey)
+	assert.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	assert.NoError(t, err)
+	plaintext, err := aead.Open(nil, make([]byte, aead.NonceSize()), data[2+keyLen:], nil)
+	assert.NoError(t, err)
+	return string(plaintext)
+}
+
+func TestScope_Label(t *testing.T) {
+	assert.Equal(t, []byte("ns/name"), sealedsecrets.ScopeStrict.Label("ns", "name"))
+	assert.Equal(t, []byte("ns"), sealedsecrets.ScopeNamespaceWide.Label("ns", "name"))
+	assert.Nil(t, sealedsecrets.ScopeClusterWide.Label("ns", "name"))
+	assert.Equal(t, sealedsecrets.ScopeNamespaceWide, sealedsecrets.ScopeFromAnnotations(map[string]string{sealedsecrets.NamespaceWideAnnotation: "true"}))
+	assert.Equal(t, sealedsecrets.ScopeStrict, sealedsecrets.ScopeFromAnnotations(nil))
+}
+
+func TestParseScope(t *testing.T) {
+	scope, err := sealedsecrets.ParseScope("cluster-wide")
+	assert.NoError(t, err)
+	assert.Equal(t, sealedsecrets.ScopeClusterWide, scope)
+
+	_, err = sealedsecrets.ParseScope("namespacewide")
+	assert.EqualError(t, err, `unknown scope "namespacewide": expected strict, namespace-wide or cluster-wide`)
+}
+
+func TestLoadPublicKey(t *testing.T) {
+	key := generateKey(t)
+	file := filepath.Join(t.TempDir(), "cert.pem")
+	writeCert(t, key, file)
+
+	pub, err := sealedsecrets.LoadPublicKey(file)
+
+	assert.NoError(t, err)
+	assert.True(t, key.PublicKey.Equal(pub))
+}
+
+func TestSealTree(t *testing.T) {
+	t.Run("SealTree updates existing files and preserves other keys", func(t *testing.T) {
+		// Arrange
+		root := t.TempDir()
+		key := generateKey(t)
+		writeSealedSecrets(t, root, "c", "stage", "us-east-1", `sealedSecrets:
+  annotations:
+    sealedsecrets.bitnami.com/namespace-wide: "true"
+  encryptedData:
+    mongodbUri: keep-me
+    redisPassword: old
+`)
+
+		// Act
+		written, err := sealedsecrets.SealTree(sealedsecrets.SealOptions{
+			Root:      root,
+			Component: "c",
+			Namespace: "default",
+			Certs:     map[string]*rsa.PublicKey{"stage/us-east-1": &key.PublicKey},
+			Secrets:   map[string]string{"redisPassword": "s3cr3t", "apiKey": "k3y"},
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{sealedsecrets.File("c", "stage", "us-east-1")}, written)
+
+		data, err := os.ReadFile(filepath.Join(root, written[0]))
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "  annotations:\n    sealedsecrets.bitnami.com/namespace-wide: \"true\"\n")
+
+		v, err := sealedsecrets.ParseValues(data)
+		assert.NoError(t, err)
+		assert.Equal(t, "keep-me", v.SealedSecrets.EncryptedData["mongodbUri"])
+		assert.NoError(t, sealedsecrets.ValidateBlob(v.SealedSecrets.EncryptedData["redisPassword"]))
+		assert.Equal(t, "s3cr3t", unseal(t, key, v.SealedSecrets.EncryptedData["redisPassword"], []byte("default")))
+		assert.Equal(t, "k3y", unseal(t, key, v.SealedSecrets.EncryptedData["apiKey"], []byte("default")))
+	})
+
+	t.Run("SealTree follows the strict scope of an existing file", func(t *testing.T) {
+		// Arrange
+		root := t.TempDir()
+		key := generateKey(t)
+		writeSealedSecrets(t, root, "c", "prod", "eu-west-1", "sealedSecrets:\n    encryptedData:\n        redisPassword: old\n")
+
+		// Act
+		written, err := sealedsecrets.SealTree(sealedsecrets.SealOptions{
+			Root:      root,
+			Component: "c",
+			Namespace: "default",
+			Certs:     map[string]*rsa.PublicKey{"prod/eu-west-1": &key.PublicKey},
+			Secrets:   map[string]string{"redisPassword": "s3cr3t"},
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		v, err := sealedsecrets.LoadValues(filepath.Join(root, written[0]))
+		assert.NoError(t, err)
+		assert.Empty(t, v.SealedSecrets.Annotations)
+		assert.Equal(t, "s3cr3t", unseal(t, key, v.SealedSecrets.EncryptedData["redisPassword"], []byte("default/c-sealed-secrets")))
+	})
+
+	t.Run("SealTree creates missing region files with the default scope", func(t *testing.T) {
+		// Arrange
+		root := t.TempDir()
+		key := generateKey(t)
+
+		// Act
+		written, err := sealedsecrets.SealTree(sealedsecrets.SealOptions{
+			Root:      root,
+			Component: "c",
+			Namespace: "default",
+			Certs:     map[string]*rsa.PublicKey{"prod/ap-south-1": &key.PublicKey},
+			Secrets:   map[string]string{"redisPassword": "s3cr3t"},
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		v, err := sealedsecrets.LoadValues(filepath.Join(root, written[0]))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{sealedsecrets.NamespaceWideAnnotation: "true"}, v.SealedSecrets.Annotations)
+		assert.Equal(t, "s3cr3t", unseal(t, key, v.SealedSecrets.EncryptedData["redisPassword"], []byte("default")))
+	})
+
+	t.Run("SealTree requires a namespace for scoped secrets", func(t *testing.T) {
+		key := generateKey(t)
+
+		_, err := sealedsecrets.SealTree(sealedsecrets.SealOptions{
+			Root:      t.TempDir(),
+			Component: "c",
+			Certs:     map[string]*rsa.PublicKey{"prod/ap-south-1": &key.PublicKey},
+			Secrets:   map[string]string{"redisPassword": "s3cr3t"},
+		})
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,112 @@
+package sealedsecrets
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gitpkg/inventory"
+	"gitpkg/render"
+)
+
+// FileName is the name of the file holding the sealed secrets of a region.
+const FileName = "sealed-secrets.yaml"
+
+// Entry is a file found in a qcs/<component>/sealed-secrets tree.
+type Entry struct {
+	Component string
+	Tier      string
+	Region    string
+	// Path is relative to the repository root.
+	Path string
+}
+
+// Dir returns the sealed-secrets directory of a component relative to the repository root.
+func Dir(component string) string {
+	return filepath.Join("qcs", component, "sealed-secrets")
+}
+
+// File returns the sealed-secrets file of a component for a tier and region.
+func File(component, tier, region string) string {
+	return filepath.Join(Dir(component), tier, region, FileName)
+}
+
+// Components lists the components under <root>/qcs that have a values.yaml.
+func Components(root string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(root, "qcs", "*", "values.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	var components []string
+	for _, file := range files {
+		components = append(components, filepath.Base(filepath.Dir(file)))
+	}
+	sort.Strings(components)
+	return components, nil
+}
+
+// Scan lists every file below qcs/<component>/sealed-secrets. Files which are not a
+// <tier>/<region>/sealed-secrets.yaml are returned as stray paths.
+func Scan(root, component string) (entries []Entry, stray []string, err error) {
+	dir := filepath.Join(root, Dir(component))
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	err = filepath.WalkDir(dir, func(file string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		// qcs/<component>/sealed-secrets/<tier>/<region>/sealed-secrets.yaml
+		if len(parts) != 6 || parts[5] != FileName {
+			stray = append(stray, rel)
+			return nil
+		}
+		entries = append(entries, Entry{Component: component, Tier: parts[3], Region: parts[4], Path: rel})
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+	return entries, stray, nil
+}
+
+// declPattern matches the variable declarations in the header of a values template,
+// e.g. `{{ $useSealedSecrets := (ne $provider "fedramp") -}}`.
+var declPattern = regexp.MustCompile(`^\s*\{\{-?\s*\$\w+\s*:=.*\}\}\s*$`)
+
+// UsesSealedSecrets evaluates the `$useSealedSecrets` condition of a values template
+// for an environment. Templates that do not declare the variable never use sealed secrets.
+func UsesSealedSecrets(values string, env inventory.Environment) (bool, error) {
+	var decls []string
+	declared := false
+	for _, line := range strings.Split(values, "\n") {
+		if !declPattern.MatchString(line) {
+			continue
+		}
+		decls = append(decls, line)
+		if strings.Contains(line, "$useSealedSecrets") {
+			declared = true
+		}
+	}
+	if !declared {
+		return false, nil
+	}
+
+	r, err := render.NewRenderer(render.WithEnv(env.Vars()))
+	if err != nil {
+		return false, err
+	}
+	out, err := r.Render("useSealedSecrets", strings.Join(decls, "\n")+"\n{{- $useSealedSecrets }}")
+	if err != nil {
+		return false, fmt.Errorf("failed to evaluate useSealedSecrets: %w", err)
+	}
+	return strings.TrimSpace(out) == "true", nil
+}
@@ -0,0 +1,145 @@
+// Package slack builds Block Kit messages and posts them to incoming webhooks.
+package slack
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Message is the payload of an incoming webhook. Text is shown in
+// notifications and by clients that do not render blocks.
+type Message struct {
+	Channel string  `json:"channel,omitempty"`
+	Text    string  `json:"text"`
+	Blocks  []Block `json:"blocks,omitempty"`
+}
+
+// Block is a Block Kit layout block. Only the fields of header, section,
+// context and divider blocks are supported.
+type Block struct {
+	Type     string `json:"type"`
+	Text     *Text  `json:"text,omitempty"`
+	Fields   []Text `json:"fields,omitempty"`
+	Elements []Text `json:"elements,omitempty"`
+}
+
+// Text is a plain_text or mrkdwn text object.
+type Text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Plain returns a plain_text object.
+func Plain(text string) Text {
+	return Text{Type: "plain_text", Text: text}
+}
+
+// Markdown returns a mrkdwn text object.
+func Markdown(text string) Text {
+	return Text{Type: "mrkdwn", Text: text}
+}
+
+// Header returns a header block.
+func Header(text string) Block {
+	t := Plain(text)
+	return Block{Type: "header", Text: &t}
+}
+
+// Section returns a section block with a mrkdwn text.
+func Section(text string) Block {
+	t := Markdown(text)
+	return Block{Type: "section", Text: &t}
+}
+
+// Fields returns a section block laying out mrkdwn fields in two columns.
+func Fields(fields ...string) Block {
+	b := Block{Type: "section"}
+	for _, f := range fields {
+		b.Fields = append(b.Fields, Markdown(f))
+	}
+	return b
+}
+
+// Context returns a context block of mrkdwn elements.
+func Context(elements ...string) Block {
+	b := Block{Type: "context"}
+	for _, e := range elements {
+		b.Elements = append(b.Elements, Markdown(e))
+	}
+	return b
+}
+
+// Divider returns a divider block.
+func Divider() Block {
+	return Block{Type: "divider"}
+}
+
+// Escape escapes the characters with a meaning in mrkdwn text.
+func Escape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// Link returns a mrkdwn link.
+func Link(url, text string) string {
+	return fmt.Sprintf("<%s|%s>", url, Escape(text))
+}
+
+// Webhook posts messages to an incoming webhook URL.
+type Webhook struct {
+	URL string
+	// Client sends the requests, a client with a 10 second timeout when nil.
+	Client *http.Client
+}
+
+// Post sends the message. It fails when the webhook does not answer with 200.
+func (w *Webhook) Post(msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode slack message: %w", err)
+	}
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Post(w.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// the webhook URL is a credential, keep it out of the error
+		return fmt.Errorf("failed to post slack message: %w", redactURL(err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("slack webhook answered %s: %s", resp.Status, strings.TrimSpace(string(text)))
+	}
+	return nil
+}
+
+// WriteFile writes the JSON payload of the message to file instead of
+// posting it, for dry runs.
+func WriteFile(file string, msg Message) error {
+	data, err := json.MarshalIndent(msg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode slack message: %w", err)
+	}
+	if err := os.WriteFile(file, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return nil
+}
+
+// redactURL drops the request URL from the errors of the HTTP client.
+func redactURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%s webhook: %w", urlErr.Op, urlErr.Err)
+	}
+	return err
+}
@@ -0,0 +1,76 @@
+package slack_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitpkg/slack"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhook_Post(t *testing.T) {
+	msg := slack.Message{Channel: "#deployments", Text: "hello", Blocks: []slack.Block{slack.Header("hello"), slack.Fields("*a*", "*b*")}}
+
+	t.Run("Post sends the message as JSON", func(t *testing.T) {
+		// Arrange
+		var got slack.Message
+		var contentType string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			contentType = r.Header.Get("Content-Type")
+			body, _ := io.ReadAll(r.Body)
+			assert.NoError(t, json.Unmarshal(body, &got))
+			w.Write([]byte("ok"))
+		}))
+		defer server.Close()
+
+		// Act
+		err := (&slack.Webhook{URL: server.URL}).Post(msg)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, "application/json", contentType)
+		assert.Equal(t, msg, got)
+	})
+
+	t.Run("Post fails when the webhook rejects the message", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "invalid_payload", http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		err := (&slack.Webhook{URL: server.URL}).Post(msg)
+
+		assert.EqualError(t, err, "slack webhook answered 400 Bad Request: invalid_payload")
+	})
+
+	t.Run("Post keeps the webhook URL out of connection errors", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		url := server.URL + "/services/T000/B000/secret"
+		server.Close()
+
+		err := (&slack.Webhook{URL: url}).Post(msg)
+
+		assert.Error(t, err)
+		assert.NotContains(t, err.Error(), "secret")
+	})
+}
+
+func TestWriteFile(t *testing.T) {
+	// Arrange
+	file := filepath.Join(t.TempDir(), "slack.json")
+
+	// Act
+	err := slack.WriteFile(file, slack.Message{Text: "a < b", Blocks: []slack.Block{slack.Section(slack.Escape("a < b"))}})
+
+	// Assert
+	assert.NoError(t, err)
+	data, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"text":"a < b","blocks":[{"type":"section","text":{"type":"mrkdwn","text":"a &lt; b"}}]}`, string(data))
+}
@@ -0,0 +1,28 @@
+package utilities
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MultiFlag is a flag.Value collecting every occurrence of a repeated string flag.
+type MultiFlag []string
+
+func (m *MultiFlag) String() string     { return strings.Join(*m, ",") }
+func (m *MultiFlag) Set(v string) error { *m = append(*m, v); return nil }
+
+// UsageError is an invalid flag or argument of a command. The go-tools
+// commands, including the ones parsing their own flags, return it for every
+// command line they cannot run with, and go-tools exits with ExitUsage.
+type UsageError struct {
+	Err error
+}
+
+// NewUsageError formats a UsageError.
+func NewUsageError(format string, args ...interface{}) error {
+	return &UsageError{Err: fmt.Errorf(format, args...)}
+}
+
+func (e *UsageError) Error() string { return e.Err.Error() }
+
+func (e *UsageError) Unwrap() error { return e.Err }
@@ -0,0 +1,141 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// ErrChartNotFound is returned when neither the chart directory nor the artifact
+// cache hold the chart of a component.
+var ErrChartNotFound = errors.New("helm chart not found")
+
+// ChartRenderer renders the manifests of a component chart with the given values.
+type ChartRenderer interface {
+	Render(component, version string, values []byte) (string, error)
+}
+
+// HelmRenderer renders charts in-process with the Helm template engine, like
+// `helm template --include-crds`.
+type HelmRenderer struct {
+	// ChartDir holds unpacked charts as <ChartDir>/<component>.
+	ChartDir string
+	// ChartCache holds packaged charts as <ChartCache>/<component>-<version>.tgz,
+	// the layout produced by `helm pull oci://...`.
+	ChartCache string
+	// KubeVersion is the Kubernetes version reported to the chart, "1.25.0" by default.
+	KubeVersion string
+	// APIVersions are added to the default capabilities, e.g. "cert-manager.io/v1".
+	APIVersions []string
+}
+
+// DefaultAPIVersions are the extra API versions the validation workflows pass to helm template.
+var DefaultAPIVersions = []string{"apiregistration.k8s.io/v1", "cert-manager.io/v1"}
+
+// Locate returns the chart path of a component.
+func (h *HelmRenderer) Locate(component, version string) (string, error) {
+	if h.ChartDir != "" {
+		dir := filepath.Join(h.ChartDir, component)
+		if _, err := os.Stat(filepath.Join(dir, "Chart.yaml")); err == nil {
+			return dir, nil
+		}
+	}
+	if h.ChartCache != "" && version != "" {
+		archive := filepath.Join(h.ChartCache, fmt.Sprintf("%s-%s.tgz", component, version))
+		if _, err := os.Stat(archive); err == nil {
+			return archive, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s version %q", ErrChartNotFound, component, version)
+}
+
+// Render renders the chart of the component and returns the manifests as a
+// multi-document YAML stream.
+func (h *HelmRenderer) Render(component, version string, values []byte) (string, error) {
+	file, err := h.Locate(component, version)
+	if err != nil {
+		return "", err
+	}
+	ch, err := loader.Load(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to load chart %s: %w", file, err)
+	}
+	vals, err := chartutil.ReadValues(values)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse values: %w", err)
+	}
+	if err := chartutil.ProcessDependencies(ch, vals); err != nil {
+		return "", fmt.Errorf("failed to process chart dependencies: %w", err)
+	}
+
+	caps, err := h.capabilities()
+	if err != nil {
+		return "", err
+	}
+	options := chartutil.ReleaseOptions{
+		Name:      component + "-release",
+		Namespace: "default",
+		Revision:  1,
+		IsInstall: true,
+	}
+	renderValues, err := chartutil.ToRenderValues(ch, vals, options, caps)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute render values: %w", err)
+	}
+	files, err := engine.Render(ch, renderValues)
+	if err != nil {
+		return "", fmt.Errorf("helm template failed: %w", err)
+	}
+	return joinManifests(ch, files, caps)
+}
+
+func (h *HelmRenderer) capabilities() (*chartutil.Capabilities, error) {
+	kubeVersion := h.KubeVersion
+	if kubeVersion == "" {
+		kubeVersion = "1.25.0"
+	}
+	kv, err := chartutil.ParseKubeVersion(kubeVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kube version %q: %w", kubeVersion, err)
+	}
+	caps := chartutil.DefaultCapabilities.Copy()
+	caps.KubeVersion = *kv
+	caps.APIVersions = append(caps.APIVersions, h.APIVersions...)
+	return caps, nil
+}
+
+// joinManifests orders the rendered templates the way helm template prints them,
+// CRDs first and hooks last, skipping NOTES.txt and empty documents.
+func joinManifests(ch *chart.Chart, files map[string]string, caps *chartutil.Capabilities) (string, error) {
+	var sb strings.Builder
+	for _, crd := range ch.CRDObjects() {
+		fmt.Fprintf(&sb, "---\n# Source: %s\n%s\n", crd.Filename, strings.TrimSpace(string(crd.File.Data)))
+	}
+
+	for name := range files {
+		if strings.HasSuffix(name, "NOTES.txt") {
+			delete(files, name)
+		}
+	}
+	hooks, manifests, err := releaseutil.SortManifests(files, caps.APIVersions, releaseutil.InstallOrder)
+	if err != nil {
+		return "", fmt.Errorf("failed to sort manifests: %w", err)
+	}
+	for _, m := range manifests {
+		fmt.Fprintf(&sb, "---\n# Source: %s\n%s\n", m.Name, strings.TrimSpace(m.Content))
+	}
+	sort.SliceStable(hooks, func(i, j int) bool { return hooks[i].Weight < hooks[j].Weight })
+	for _, hook := range hooks {
+		fmt.Fprintf(&sb, "---\n# Source: %s\n%s\n", hook.Path, strings.TrimSpace(hook.Manifest))
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,148 @@
+package validate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gitpkg/inventory"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultExceptionsFile is the exceptions file at the root of the values repository.
+const DefaultExceptionsFile = "helmchart-validation-exceptions.yaml"
+
+// dateLayout is the layout of the expires field.
+const dateLayout = "2006-01-02"
+
+// Exception excludes a component from validation. Environment and Region
+// narrow the scope; when both are empty the exception applies everywhere.
+type Exception struct {
+	Component   string `yaml:"component"`
+	Environment string `yaml:"environment,omitempty"`
+	Region      string `yaml:"region,omitempty"`
+	Reason      string `yaml:"reason"`
+	Owner       string `yaml:"owner"`
+	// Expires is the last day the exception applies, as YYYY-MM-DD.
+	Expires string `yaml:"expires"`
+
+	// legacy is set for plain component names from the old exceptions list.
+	legacy bool
+}
+
+// UnmarshalYAML accepts the typed mapping and the legacy plain component name,
+// which is kept so it can be reported as invalid.
+func (e *Exception) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		*e = Exception{Component: name, legacy: true}
+		return nil
+	}
+	type plain Exception
+	return unmarshal((*plain)(e))
+}
+
+// String describes the scope of the exception, e.g. "c in qcs-prod-eu-west-1".
+func (e Exception) String() string {
+	scope := []string{e.Component}
+	if e.Environment != "" {
+		scope = append(scope, "in "+e.Environment)
+	}
+	if e.Region != "" {
+		scope = append(scope, "in region "+e.Region)
+	}
+	return strings.Join(scope, " ")
+}
+
+// Check returns an error if the exception is incomplete or expired at now.
+func (e Exception) Check(now time.Time) error {
+	if e.legacy {
+		return fmt.Errorf("exception %s is a plain component name, a reason, owner and expiry are required", e.Component)
+	}
+	var missing []string
+	for field, value := range map[string]string{"component": e.Component, "reason": e.Reason, "owner": e.Owner, "expires": e.Expires} {
+		if strings.TrimSpace(value) == "" {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("exception %s is missing %s", e, strings.Join(missing, ", "))
+	}
+	expires, err := time.Parse(dateLayout, e.Expires)
+	if err != nil {
+		return fmt.Errorf("exception %s has an invalid expiry %q, expected YYYY-MM-DD", e, e.Expires)
+	}
+	if now.After(expires.AddDate(0, 0, 1)) {
+		return fmt.Errorf("exception %s owned by %s expired on %s", e, e.Owner, e.Expires)
+	}
+	return nil
+}
+
+// Matches reports whether the exception covers the component in the environment.
+func (e Exception) Matches(component string, env inventory.Environment) bool {
+	if e.Component != component {
+		return false
+	}
+	if e.Environment != "" && e.Environment != env.Name {
+		return false
+	}
+	return e.Region == "" || e.Region == env.Region
+}
+
+// Exceptions is the parsed content of helmchart-validation-exceptions.yaml.
+type Exceptions struct {
+	Exceptions []Exception `yaml:"exceptions"`
+	// File is the file the exceptions were loaded from, DefaultExceptionsFile when empty.
+	File string `yaml:"-"`
+}
+
+// ParseExceptions parses an exceptions file.
+func ParseExceptions(data []byte) (*Exceptions, error) {
+	exceptions := &Exceptions{}
+	if err := yaml.Unmarshal(data, exceptions); err != nil {
+		return nil, fmt.Errorf("failed to parse exceptions: %w", err)
+	}
+	return exceptions, nil
+}
+
+// LoadExceptions reads an exceptions file. A missing file yields no exceptions,
+// like the validation workflows do.
+func LoadExceptions(file string) (*Exceptions, error) {
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return &Exceptions{File: file}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	exceptions, err := ParseExceptions(data)
+	if err != nil {
+		return nil, err
+	}
+	exceptions.File = file
+	return exceptions, nil
+}
+
+// file returns the file the exceptions were loaded from.
+func (x *Exceptions) file() string {
+	if x.File == "" {
+		return DefaultExceptionsFile
+	}
+	return filepath.ToSlash(x.File)
+}
+
+// Match returns the first valid exception covering the component in the
+// environment, or nil.
+func (x *Exceptions) Match(component string, env inventory.Environment, now time.Time) *Exception {
+	for i, e := range x.Exceptions {
+		if e.Matches(component, env) && e.Check(now) == nil {
+			return &x.Exceptions[i]
+		}
+	}
+	return nil
+}
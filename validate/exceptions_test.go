@@ -0,0 +1,152 @@
+package validate_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gitpkg/inventory"
+	"gitpkg/report"
+	"gitpkg/validate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+
+func TestParseExceptions(t *testing.T) {
+	exceptions, err := validate.ParseExceptions([]byte(`exceptions:
+  - legacy-component
+  - component: hello
+    environment: qcs-prod-eu-west-1
+    reason: chart 2.0 migration
+    owner: team-a
+    expires: "2026-07-01"
+`))
+
+	assert.NoError(t, err)
+	assert.Len(t, exceptions.Exceptions, 2)
+	assert.Equal(t, "legacy-component", exceptions.Exceptions[0].Component)
+	assert.ErrorContains(t, exceptions.Exceptions[0].Check(now), "plain component name")
+	assert.Equal(t, "qcs-prod-eu-west-1", exceptions.Exceptions[1].Environment)
+	assert.NoError(t, exceptions.Exceptions[1].Check(now))
+}
+
+func TestException_Check(t *testing.T) {
+	valid := validate.Exception{Component: "c", Reason: "r", Owner: "o", Expires: "2026-06-15"}
+
+	tests := []struct {
+		name      string
+		exception validate.Exception
+		expected  string
+	}{
+		{"valid until the end of the expiry day", valid, ""},
+		{"expired", validate.Exception{Component: "c", Reason: "r", Owner: "o", Expires: "2026-06-14"}, "expired on 2026-06-14"},
+		{"missing fields", validate.Exception{Component: "c", Expires: "2026-07-01"}, "missing owner, reason"},
+		{"invalid expiry", validate.Exception{Component: "c", Reason: "r", Owner: "o", Expires: "next week"}, "invalid expiry"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.exception.Check(now)
+			if tc.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.expected)
+			}
+		})
+	}
+}
+
+func TestException_Matches(t *testing.T) {
+	env := inventory.FromName("qcs-prod-eu-west-1")
+
+	assert.True(t, validate.Exception{Component: "c"}.Matches("c", env))
+	assert.True(t, validate.Exception{Component: "c", Region: "eu-west-1"}.Matches("c", env))
+	assert.True(t, validate.Exception{Component: "c", Environment: "qcs-prod-eu-west-1"}.Matches("c", env))
+	assert.False(t, validate.Exception{Component: "c", Region: "us-east-1"}.Matches("c", env))
+	assert.False(t, validate.Exception{Component: "c", Environment: "qcs-stage-us-east-1"}.Matches("c", env))
+	assert.False(t, validate.Exception{Component: "cc"}.Matches("c", env))
+}
+
+func TestRun_Exceptions(t *testing.T) {
+	t.Run("Run skips excepted pairs and lists the applied exceptions", func(t *testing.T) {
+		// Arrange
+		root, envRoot := testTree(t, "replicas: two\n")
+		exception := validate.Exception{Component: "hello", Region: "us-east-1", Reason: "r", Owner: "o", Expires: "2026-07-01"}
+
+		// Act
+		rep, err := validate.Run(validate.Options{
+			Root:             root,
+			EnvironmentsRoot: envRoot,
+			Inventory:        testInventory(t),
+			Components:       []string{"hello"},
+			Charts:           &validate.HelmRenderer{ChartDir: "testdata/charts"},
+			Exceptions:       &validate.Exceptions{Exceptions: []validate.Exception{exception}},
+			Now:              now,
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.False(t, rep.Failed())
+		stage := result(rep, "qcs-stage-us-east-1")
+		assert.Equal(t, report.StatusSkip, stage.Status)
+		assert.Equal(t, validate.StageExceptions, stage.Stage)
+		assert.Contains(t, stage.Message, "excepted until 2026-07-01 by o: r")
+		assert.Equal(t, []validate.Exception{exception}, rep.Applied)
+	})
+
+	t.Run("Run fails on expired and legacy exceptions and does not apply them", func(t *testing.T) {
+		// Arrange
+		root, envRoot := testTree(t, "replicas: 2\n")
+		exceptions, err := validate.ParseExceptions([]byte(`exceptions:
+  - hello
+  - component: hello
+    reason: r
+    owner: o
+    expires: "2026-01-01"
+`))
+		assert.NoError(t, err)
+
+		// Act
+		rep, err := validate.Run(validate.Options{
+			Root:             root,
+			EnvironmentsRoot: envRoot,
+			Inventory:        testInventory(t),
+			Components:       []string{"hello"},
+			Exceptions:       exceptions,
+			Now:              now,
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.True(t, rep.Failed())
+		assert.Equal(t, 2, rep.Count(report.StatusFail))
+		assert.Empty(t, rep.Applied)
+		assert.Equal(t, report.StatusPass, result(rep, "qcs-stage-us-east-1").Status)
+		assert.Equal(t, validate.DefaultExceptionsFile, rep.Results[0].File)
+	})
+
+	t.Run("Run reports expired exceptions at the file they were loaded from", func(t *testing.T) {
+		// Arrange
+		root, envRoot := testTree(t, "replicas: 2\n")
+		file := filepath.Join(t.TempDir(), "exceptions.yaml")
+		writeFile(t, file, "exceptions:\n  - component: hello\n    reason: r\n    owner: o\n    expires: \"2026-01-01\"\n")
+		exceptions, err := validate.LoadExceptions(file)
+		assert.NoError(t, err)
+
+		// Act
+		rep, err := validate.Run(validate.Options{
+			Root:             root,
+			EnvironmentsRoot: envRoot,
+			Inventory:        testInventory(t),
+			Components:       []string{"hello"},
+			Exceptions:       exceptions,
+			Now:              now,
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, report.StatusFail, rep.Results[0].Status)
+		assert.Equal(t, filepath.ToSlash(file), rep.Results[0].File)
+	})
+}
@@ -0,0 +1,152 @@
+package validate
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gitpkg/inventory"
+	"gitpkg/logging"
+	"gitpkg/render"
+	"gitpkg/report"
+	"gitpkg/sealedsecrets"
+	"gitpkg/utilities"
+	"gitpkg/vaultmock"
+)
+
+// Setup registers the flags of the validate command on fs and returns the
+// function running it.
+func Setup(fs *flag.FlagSet) func(args []string) error {
+	root := fs.String("root", ".", "Repository root containing the qcs directory")
+	inventoryFile := fs.String("inventory", "gitops-environments/"+inventory.DefaultFile, "Path of the environment inventory")
+	environmentsRoot := fs.String("environments-root", "gitops-environments", "gitops-environments checkout holding the conf.yaml files")
+	components := fs.String("components", "", "Comma separated components to validate, all when empty")
+	vaultMock := fs.String("vault-mock", "", "Mock directory generated by vault-mock, used for every referenced datasource")
+	registry := fs.String("registry-url", "registry.com", "Value rendered as CONTAINER_REGISTRY_URL")
+	chartDir := fs.String("chart-dir", "", "Directory of unpacked charts as <dir>/<component>")
+	chartCache := fs.String("chart-cache", "", "Directory of pulled charts as <dir>/<component>-<version>.tgz")
+	schemaDir := fs.String("schema-dir", "", "Directory of Kubernetes JSON schemas; the schema stage is skipped when empty")
+	kubeVersion := fs.String("kube-version", "1.25.0", "Kubernetes version used for templating and schemas")
+	strict := fs.Bool("strict", false, "Reject properties that are not part of the schema")
+	exceptionsFile := fs.String("exceptions", DefaultExceptionsFile, "Validation exceptions file, ignored when missing")
+	jsonOut := fs.String("json", "", "Write the report as JSON to this file")
+	var outputs report.Outputs
+	outputs.BindFlags(fs)
+	var datasources utilities.MultiFlag
+	fs.Var(&datasources, "datasource", "Datasource as name=file:///dir?type=application/json, repeatable")
+	return func(args []string) error {
+
+		inv, err := inventory.Load(*inventoryFile)
+		if err != nil {
+			return err
+		}
+		exceptions, err := LoadExceptions(*exceptionsFile)
+		if err != nil {
+			return err
+		}
+		opts := Options{
+			Root:             *root,
+			EnvironmentsRoot: *environmentsRoot,
+			Inventory:        inv,
+			RegistryURL:      *registry,
+			Exceptions:       exceptions,
+			Secrets:          render.NewSecrets(),
+		}
+		if _, err := os.Stat(*environmentsRoot); err != nil {
+			opts.EnvironmentsRoot = ""
+		}
+		if *components != "" {
+			opts.Components = strings.Split(*components, ",")
+		} else if opts.Components, err = sealedsecrets.Components(*root); err != nil {
+			return err
+		}
+
+		for _, spec := range datasources {
+			opts.RenderOptions = append(opts.RenderOptions, render.WithDatasourceSpec(spec))
+		}
+		if *vaultMock != "" {
+			refs, err := vaultmock.ScanFiles(*root, vaultmock.DefaultGlob)
+			if err != nil {
+				return err
+			}
+			opts.RenderOptions = append(opts.RenderOptions, vaultmock.Datasources(*vaultMock, refs)...)
+		}
+
+		if *chartDir != "" || *chartCache != "" {
+			opts.Charts = &HelmRenderer{
+				ChartDir:    *chartDir,
+				ChartCache:  *chartCache,
+				KubeVersion: *kubeVersion,
+				APIVersions: DefaultAPIVersions,
+			}
+		}
+		if *schemaDir != "" {
+			if opts.Schemas, err = NewKubeconformValidator(*schemaDir, *kubeVersion, *strict); err != nil {
+				return err
+			}
+		}
+
+		rep, err := Run(opts)
+		if err != nil {
+			return err
+		}
+		opts.Secrets.WriteMasks(os.Stdout)
+		rep.Annotate(logging.NewAnnotator(os.Stdout))
+		rep.WriteSummary(os.Stdout)
+		if err := outputs.Write(&rep.Report); err != nil {
+			return err
+		}
+		if *jsonOut != "" {
+			data, err := json.MarshalIndent(rep, "", "  ")
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(*jsonOut, data, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", *jsonOut, err)
+			}
+		}
+		if rep.Failed() {
+			return fmt.Errorf("%d validations failed", rep.Count(report.StatusFail))
+		}
+		return nil
+	}
+}
+
+// WriteSummary writes the failed, skipped and passed results, mirroring the
+// summaries the validation workflows print.
+func (r *Report) WriteSummary(w io.Writer) {
+	sections := []struct {
+		status report.Status
+		title  string
+	}{
+		{report.StatusFail, "Validation errors found in the following components:"},
+		{report.StatusSkip, "The following components were skipped:"},
+		{report.StatusPass, "The following components passed validation:"},
+	}
+	for _, s := range sections {
+		if r.Count(s.status) == 0 {
+			continue
+		}
+		fmt.Fprintln(w, s.title)
+		for _, res := range r.Results {
+			if res.Status != s.status {
+				continue
+			}
+			fmt.Fprintf(w, "Component: %s, Environment: %s, Version: %s, Stage: %s", res.Component, res.Environment, res.Version, res.Stage)
+			if res.Message != "" {
+				fmt.Fprintf(w, ", %s", res.Message)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+	if len(r.Applied) > 0 {
+		fmt.Fprintln(w, "The following exceptions were applied:")
+		for _, e := range r.Applied {
+			fmt.Fprintf(w, "Exception: %s, Owner: %s, Expires: %s, Reason: %s\n", e, e.Owner, e.Expires, e.Reason)
+		}
+	}
+	fmt.Fprintf(w, "passed: %d, failed: %d, skipped: %d\n", r.Count(report.StatusPass), r.Count(report.StatusFail), r.Count(report.StatusSkip))
+}
@@ -0,0 +1,70 @@
+package validate
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/yannh/kubeconform/pkg/validator"
+)
+
+// SchemaValidator validates a rendered manifest stream.
+type SchemaValidator interface {
+	Validate(name, manifests string) error
+}
+
+// KubeconformValidator validates manifests against Kubernetes JSON schemas read
+// from a local directory, so no network access is needed.
+type KubeconformValidator struct {
+	v validator.Validator
+}
+
+// NewKubeconformValidator creates a validator for the schemas in schemaDir.
+//
+// Parameters:
+//   - schemaDir: A directory laid out like yannh/kubernetes-json-schema, e.g.
+//     <schemaDir>/v1.25.0-standalone/deployment-apps-v1.json, or a full kubeconform
+//     schema location template ending in .json.
+//   - kubeVersion: The Kubernetes version whose schemas are used, e.g. "1.25.0".
+//   - strict: Reject properties that are not part of the schema.
+func NewKubeconformValidator(schemaDir, kubeVersion string, strict bool) (*KubeconformValidator, error) {
+	if schemaDir == "" {
+		return nil, fmt.Errorf("a schema directory is required")
+	}
+	if kubeVersion == "" {
+		kubeVersion = "1.25.0"
+	}
+	v, err := validator.New([]string{schemaDir}, validator.Opts{
+		KubernetesVersion:    kubeVersion,
+		Strict:               strict,
+		IgnoreMissingSchemas: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema validator: %w", err)
+	}
+	return &KubeconformValidator{v: v}, nil
+}
+
+// Validate validates every resource in the manifest stream and returns an error
+// listing the invalid resources.
+func (k *KubeconformValidator) Validate(name, manifests string) error {
+	var problems []string
+	for _, res := range k.v.Validate(name, io.NopCloser(strings.NewReader(manifests))) {
+		if res.Status != validator.Invalid && res.Status != validator.Error {
+			continue
+		}
+		resource := name
+		if sig, err := res.Resource.Signature(); err == nil {
+			resource = fmt.Sprintf("%s %s/%s", sig.Kind, sig.Namespace, sig.Name)
+		}
+		msg := "invalid"
+		if res.Err != nil {
+			msg = res.Err.Error()
+		}
+		problems = append(problems, fmt.Sprintf("%s: %s", resource, msg))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d invalid resources:\n%s", len(problems), strings.Join(problems, "\n"))
+	}
+	return nil
+}
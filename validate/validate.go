@@ -0,0 +1,245 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"gitpkg/deploycheck"
+	"gitpkg/inventory"
+	"gitpkg/render"
+	"gitpkg/report"
+	"gitpkg/sealedsecrets"
+
+	"gopkg.in/yaml.v2"
+)
+
+// StageExceptions holds the results of exceptions: skipped pairs and invalid
+// or expired entries.
+const StageExceptions report.Stage = "exceptions"
+
+// valuesLine extracts the line of a template error in a values file.
+var valuesLine = regexp.MustCompile(`values\.yaml:(\d+)`)
+
+// Report holds the results of a validation run.
+type Report struct {
+	report.Report
+	// Applied lists the exceptions that skipped at least one component/environment pair.
+	Applied []Exception
+}
+
+// Options configures a validation run.
+type Options struct {
+	// Root is the repository root containing the qcs directory.
+	Root string
+	// EnvironmentsRoot is the gitops-environments checkout holding the conf.yaml files.
+	// When empty every inventory environment is validated with an unknown chart version.
+	EnvironmentsRoot string
+	Inventory        *inventory.Inventory
+	Components       []string
+	// RenderOptions are applied to every values render, e.g. the vault datasources.
+	RenderOptions []render.Option
+	// RegistryURL is rendered as CONTAINER_REGISTRY_URL.
+	RegistryURL string
+	Charts      ChartRenderer
+	// Schemas validates the rendered manifests; the schema stage is skipped when nil.
+	Schemas SchemaValidator
+	// Exceptions skip the components they cover. Invalid or expired exceptions fail the run.
+	Exceptions *Exceptions
+	// Now is the time exceptions are checked against, time.Now() by default.
+	Now time.Time
+	// Secrets tracks the values read from datasources, which are redacted from
+	// the result messages. A new set is used when nil.
+	Secrets *render.Secrets
+}
+
+// Run validates every component in every inventory environment.
+func Run(opts Options) (*Report, error) {
+	if opts.Inventory == nil {
+		return nil, fmt.Errorf("an inventory is required")
+	}
+	if opts.RegistryURL == "" {
+		opts.RegistryURL = "registry.com"
+	}
+	if opts.Exceptions == nil {
+		opts.Exceptions = &Exceptions{}
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if opts.Secrets == nil {
+		opts.Secrets = render.NewSecrets()
+	}
+	opts.RenderOptions = append(append([]render.Option{}, opts.RenderOptions...), render.WithSecrets(opts.Secrets))
+	rep := &Report{Report: report.Report{Name: "Helm chart validation"}}
+	for _, e := range opts.Exceptions.Exceptions {
+		if err := e.Check(opts.Now); err != nil {
+			rep.Add(report.Result{
+				Component:   e.Component,
+				Environment: e.Environment,
+				Region:      e.Region,
+				Stage:       StageExceptions,
+				Status:      report.StatusFail,
+				Message:     err.Error(),
+				File:        opts.Exceptions.file(),
+			})
+		}
+	}
+	applied := map[*Exception]bool{}
+	for _, component := range opts.Components {
+		file := path.Join("qcs", component, "values.yaml")
+		values, err := os.ReadFile(filepath.Join(opts.Root, file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read values of %s: %w", component, err)
+		}
+		for _, env := range opts.Inventory.List() {
+			if e := opts.Exceptions.Match(component, env, opts.Now); e != nil {
+				if !applied[e] {
+					applied[e] = true
+					rep.Applied = append(rep.Applied, *e)
+				}
+				rep.Add(report.Result{
+					Component:   component,
+					Environment: env.Name,
+					Region:      env.Region,
+					Stage:       StageExceptions,
+					Status:      report.StatusSkip,
+					Message:     fmt.Sprintf("excepted until %s by %s: %s", e.Expires, e.Owner, e.Reason),
+				})
+				continue
+			}
+			start := time.Now()
+			res := validateOne(opts, component, file, string(values), env)
+			res.Duration = time.Since(start)
+			rep.Add(res)
+		}
+	}
+	// values read for a later environment may show up in earlier messages
+	for i := range rep.Results {
+		rep.Results[i].Message = opts.Secrets.Redact(rep.Results[i].Message)
+	}
+	return rep, nil
+}
+
+func validateOne(opts Options, component, file, values string, env inventory.Environment) report.Result {
+	res := report.Result{Component: component, Environment: env.Name, Region: env.Region, Stage: report.StageRender, File: file}
+	skip := func(msg string) report.Result {
+		res.Status, res.Message = report.StatusSkip, msg
+		return res
+	}
+	fail := func(stage report.Stage, err error) report.Result {
+		res.Stage, res.Status, res.Message = stage, report.StatusFail, err.Error()
+		if m := valuesLine.FindStringSubmatch(res.Message); m != nil {
+			res.Line, _ = strconv.Atoi(m[1])
+		}
+		return res
+	}
+
+	if !env.Known() {
+		return skip("unknown environment pattern")
+	}
+
+	if opts.EnvironmentsRoot != "" {
+		confFile := inventory.ConfPath(component, env.Name)
+		conf, err := ReadConf(filepath.Join(opts.EnvironmentsRoot, filepath.FromSlash(confFile)))
+		if os.IsNotExist(err) {
+			return skip("no conf.yaml for this environment")
+		}
+		if err != nil {
+			res = fail(report.StageRender, err)
+			// relative to the environments repository, where it is annotated
+			res.File = confFile
+			res.Line, res.Column = deploycheck.PositionOf(err)
+			return res
+		}
+		res.Version = conf.Version
+	}
+
+	uses, err := sealedsecrets.UsesSealedSecrets(values, env)
+	if err != nil {
+		return fail(report.StageRender, err)
+	}
+	if uses {
+		secrets := sealedsecrets.File(component, env.Tier, env.Region)
+		if _, err := os.Stat(filepath.Join(opts.Root, secrets)); os.IsNotExist(err) {
+			return skip(fmt.Sprintf("uses sealed secrets but %s does not exist", secrets))
+		}
+	}
+
+	rendered, err := RenderValues(os.DirFS(opts.Root), component, values, env, opts.RegistryURL, opts.RenderOptions...)
+	if err != nil {
+		return fail(report.StageRender, err)
+	}
+
+	if opts.Charts == nil {
+		res.Status = report.StatusPass
+		return res
+	}
+	res.Stage = report.StageTemplate
+	manifests, err := opts.Charts.Render(component, res.Version, []byte(rendered))
+	if errors.Is(err, ErrChartNotFound) {
+		return skip(err.Error())
+	}
+	if err != nil {
+		return fail(report.StageTemplate, err)
+	}
+
+	if opts.Schemas == nil {
+		res.Status = report.StatusPass
+		return res
+	}
+	res.Stage = report.StageSchema
+	if err := opts.Schemas.Validate(fmt.Sprintf("%s_%s_v%s", component, env.Name, res.Version), manifests); err != nil {
+		return fail(report.StageSchema, err)
+	}
+	res.Status = report.StatusPass
+	return res
+}
+
+// RenderValues renders the values template of a component for an environment
+// the way the validation workflows run gomplate, and checks the result is YAML.
+//
+// Parameters:
+//   - root: The repository root; files read with file.Read are resolved against it.
+//   - values: The content of qcs/<component>/values.yaml.
+//   - registryURL: The value rendered as CONTAINER_REGISTRY_URL.
+//   - opts: Further render options, e.g. the vault datasources.
+func RenderValues(root fs.FS, component, values string, env inventory.Environment, registryURL string, opts ...render.Option) (string, error) {
+	renderOpts := append([]render.Option{
+		render.WithEnv(env.Vars()),
+		render.WithEnv(map[string]string{"CONTAINER_REGISTRY_URL": registryURL}),
+		render.WithFiles(render.ComponentFS(root, component)),
+	}, opts...)
+	r, err := render.NewRenderer(renderOpts...)
+	if err != nil {
+		return "", err
+	}
+	rendered, err := r.Render(path.Join("qcs", component, "values.yaml"), values)
+	if err != nil {
+		return "", err
+	}
+	if err := yaml.Unmarshal([]byte(rendered), &map[string]interface{}{}); err != nil {
+		return "", fmt.Errorf("invalid YAML syntax in rendered values: %w", err)
+	}
+	return rendered, nil
+}
+
+// ReadConf reads a gitops-environments conf.yaml. The error of an invalid
+// file locates the problem, see deploycheck.PositionOf.
+func ReadConf(file string) (*deploycheck.ConfigFile, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	conf, err := deploycheck.ParseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return conf, nil
+}
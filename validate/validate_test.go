@@ -0,0 +1,204 @@
+package validate_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitpkg/inventory"
+	"gitpkg/render"
+	"gitpkg/report"
+	"gitpkg/validate"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, file, content string) {
+	t.Helper()
+	assert.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+	assert.NoError(t, os.WriteFile(file, []byte(content), 0644))
+}
+
+func testInventory(t *testing.T) *inventory.Inventory {
+	t.Helper()
+	inv, err := inventory.Parse([]byte(`pipeline-environments:
+  - qcs-stage-us-east-1
+  - qcs-prod-eu-west-1
+`))
+	assert.NoError(t, err)
+	return inv
+}
+
+// testTree creates a repository root with a hello component and a
+// gitops-environments checkout holding its stage conf.yaml only.
+func testTree(t *testing.T, values string) (string, string) {
+	t.Helper()
+	root := t.TempDir()
+	envRoot := t.TempDir()
+	writeFile(t, filepath.Join(root, "qcs", "hello", "values.yaml"), values)
+	writeFile(t, filepath.Join(envRoot, inventory.ConfPath("hello", "qcs-stage-us-east-1")), "version: 1.0.0\n")
+	return root, envRoot
+}
+
+func result(rep *validate.Report, env string) report.Result {
+	for _, res := range rep.Results {
+		if res.Environment == env {
+			return res
+		}
+	}
+	return report.Result{}
+}
+
+// failingCharts fails with the values in the message, like a chart calling fail.
+type failingCharts struct{}
+
+func (failingCharts) Render(component, version string, values []byte) (string, error) {
+	return "", fmt.Errorf("chart failed for values %s", strings.TrimSpace(string(values)))
+}
+
+func TestRun(t *testing.T) {
+	schemas, err := validate.NewKubeconformValidator("testdata/schemas", "1.25.0", false)
+	assert.NoError(t, err)
+	charts := &validate.HelmRenderer{ChartDir: "testdata/charts"}
+
+	t.Run("Run passes valid components and skips environments without conf.yaml", func(t *testing.T) {
+		// Arrange
+		root, envRoot := testTree(t, `{{ $region := getenv "REGION" -}}
+replicas: 2
+image: {{ getenv "CONTAINER_REGISTRY_URL" }}/hello-{{ $region }}
+`)
+
+		// Act
+		rep, err := validate.Run(validate.Options{
+			Root:             root,
+			EnvironmentsRoot: envRoot,
+			Inventory:        testInventory(t),
+			Components:       []string{"hello"},
+			Charts:           charts,
+			Schemas:          schemas,
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.False(t, rep.Failed())
+		stage := result(rep, "qcs-stage-us-east-1")
+		assert.Equal(t, report.StatusPass, stage.Status)
+		assert.Equal(t, report.StageSchema, stage.Stage)
+		assert.Equal(t, "1.0.0", stage.Version)
+		assert.Equal(t, report.StatusSkip, result(rep, "qcs-prod-eu-west-1").Status)
+	})
+
+	t.Run("Run fails values that break the schema", func(t *testing.T) {
+		// Arrange
+		root, envRoot := testTree(t, "replicas: two\n")
+
+		// Act
+		rep, err := validate.Run(validate.Options{
+			Root:             root,
+			EnvironmentsRoot: envRoot,
+			Inventory:        testInventory(t),
+			Components:       []string{"hello"},
+			Charts:           charts,
+			Schemas:          schemas,
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.True(t, rep.Failed())
+		stage := result(rep, "qcs-stage-us-east-1")
+		assert.Equal(t, report.StatusFail, stage.Status)
+		assert.Equal(t, report.StageSchema, stage.Stage)
+		assert.Contains(t, stage.Message, "Deployment")
+	})
+
+	t.Run("Run fails values that do not render", func(t *testing.T) {
+		root, envRoot := testTree(t, `password: {{ (ds "vault" "redisPassword").value }}`)
+
+		rep, err := validate.Run(validate.Options{
+			Root:             root,
+			EnvironmentsRoot: envRoot,
+			Inventory:        testInventory(t),
+			Components:       []string{"hello"},
+		})
+
+		assert.NoError(t, err)
+		stage := result(rep, "qcs-stage-us-east-1")
+		assert.Equal(t, report.StatusFail, stage.Status)
+		assert.Equal(t, report.StageRender, stage.Stage)
+		assert.Equal(t, "qcs/hello/values.yaml", stage.File)
+		assert.Equal(t, 1, stage.Line)
+	})
+
+	t.Run("Run fails conf.yaml files that do not parse at their position", func(t *testing.T) {
+		root, envRoot := testTree(t, "replicas: 2\n")
+		confFile := filepath.Join(envRoot, inventory.ConfPath("hello", "qcs-stage-us-east-1"))
+		writeFile(t, confFile, "version: 1.0.0\ndeploymentWindow: soon\n")
+
+		rep, err := validate.Run(validate.Options{
+			Root:             root,
+			EnvironmentsRoot: envRoot,
+			Inventory:        testInventory(t),
+			Components:       []string{"hello"},
+		})
+
+		assert.NoError(t, err)
+		stage := result(rep, "qcs-stage-us-east-1")
+		assert.Equal(t, report.StatusFail, stage.Status)
+		assert.Equal(t, "components/hello/qcs-stage-us-east-1/conf.yaml", stage.File)
+		assert.Equal(t, 2, stage.Line)
+		assert.Equal(t, 19, stage.Column)
+		assert.Contains(t, stage.Message, "deploymentWindow: cannot unmarshal !!str `soon` into int")
+	})
+
+	t.Run("Run redacts values read from datasources in the results", func(t *testing.T) {
+		root, envRoot := testTree(t, "replicas: {{ (ds \"vault\" \"replicas\").value }}\n")
+		vault := render.MapDatasource{"replicas": map[string]interface{}{"value": "s3cr3t-value"}}
+
+		rep, err := validate.Run(validate.Options{
+			Root:             root,
+			EnvironmentsRoot: envRoot,
+			Inventory:        testInventory(t),
+			Components:       []string{"hello"},
+			RenderOptions:    []render.Option{render.WithDatasource("vault", vault)},
+			Charts:           failingCharts{},
+		})
+
+		assert.NoError(t, err)
+		stage := result(rep, "qcs-stage-us-east-1")
+		assert.Equal(t, report.StatusFail, stage.Status)
+		assert.Equal(t, "chart failed for values replicas: "+render.Redacted, stage.Message)
+		assert.NotContains(t, stage.Message, "s3cr3t-value")
+	})
+
+	t.Run("Run skips components without a chart", func(t *testing.T) {
+		root, envRoot := testTree(t, "replicas: 2\n")
+		assert.NoError(t, os.Rename(filepath.Join(root, "qcs", "hello"), filepath.Join(root, "qcs", "other")))
+		writeFile(t, filepath.Join(envRoot, inventory.ConfPath("other", "qcs-stage-us-east-1")), "version: 1.0.0\n")
+
+		rep, err := validate.Run(validate.Options{
+			Root:             root,
+			EnvironmentsRoot: envRoot,
+			Inventory:        testInventory(t),
+			Components:       []string{"other"},
+			Charts:           charts,
+		})
+
+		assert.NoError(t, err)
+		stage := result(rep, "qcs-stage-us-east-1")
+		assert.Equal(t, report.StatusSkip, stage.Status)
+		assert.Contains(t, stage.Message, "helm chart not found")
+	})
+}
+
+func TestHelmRenderer_Render(t *testing.T) {
+	h := &validate.HelmRenderer{ChartDir: "testdata/charts"}
+
+	manifests, err := h.Render("hello", "", []byte("replicas: 3\n"))
+
+	assert.NoError(t, err)
+	assert.Contains(t, manifests, "# Source: hello/templates/deployment.yaml")
+	assert.Contains(t, manifests, "name: hello-release")
+	assert.Contains(t, manifests, "replicas: 3")
+}
@@ -0,0 +1,80 @@
+package vaultmock
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"gitpkg/utilities"
+)
+
+// Setup registers the flags of the vault-mock command; the command generates the mock
+// directory and reports keys missing from the checked-in mock.
+func Setup(fs *flag.FlagSet) func(args []string) error {
+	root := fs.String("root", ".", "Repository root containing the qcs directory")
+	glob := fs.String("glob", DefaultGlob, "Glob of the values templates to scan, relative to --root")
+	out := fs.String("out", "", "Directory to write the generated mock into")
+	var checkedIn utilities.MultiFlag
+	fs.Var(&checkedIn, "checked-in", "Checked-in mock (vaultMock.yaml or mock directory) to compare against as [<datasource>=]<path>, repeatable; the datasource is "+DefaultDatasource+" when omitted")
+	return func(args []string) error {
+		return run(os.Stdout, *root, *glob, *out, checkedIn)
+	}
+}
+
+func run(w io.Writer, root, glob, out string, checkedIn []string) error {
+	refs, err := ScanFiles(root, glob)
+	if err != nil {
+		return err
+	}
+	WriteSummary(w, refs)
+
+	if out != "" {
+		if err := Generate(out, refs); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "mock written to %s\n", out)
+	}
+
+	if len(checkedIn) == 0 {
+		return nil
+	}
+	mock := Mock{}
+	for _, spec := range checkedIn {
+		datasource, path, ok := strings.Cut(spec, "=")
+		if !ok {
+			datasource, path = DefaultDatasource, spec
+		}
+		loaded, err := LoadCheckedIn(path, datasource)
+		if err != nil {
+			return err
+		}
+		for key := range loaded[datasource] {
+			mock.Add(datasource, key)
+		}
+	}
+	missing := Missing(refs, mock)
+	for _, r := range missing {
+		fmt.Fprintf(w, "missing from the checked-in mock: %s\n", r)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%d referenced keys are missing from the checked-in mock", len(missing))
+	}
+	return nil
+}
+
+// WriteSummary prints the number of keys referenced from each datasource,
+// sorted by datasource name.
+func WriteSummary(w io.Writer, refs []Reference) {
+	keys := Keys(refs)
+	names := make([]string, 0, len(keys))
+	for ds := range keys {
+		names = append(names, ds)
+	}
+	sort.Strings(names)
+	for _, ds := range names {
+		fmt.Fprintf(w, "datasource %s: %d keys\n", ds, len(keys[ds]))
+	}
+}
@@ -0,0 +1,201 @@
+package vaultmock
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gitpkg/render"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultGlob matches the values templates scanned for datasource references.
+const DefaultGlob = "qcs/*/values.yaml"
+
+// refPattern matches `ds "vault" "key"` and `datasource "name" "key"` calls.
+var refPattern = regexp.MustCompile(`\b(?:ds|datasource)\s+"([^"]+)"\s+"([^"]+)"`)
+
+// Reference is a datasource key used by a values template.
+type Reference struct {
+	Datasource string
+	Key        string
+	File       string
+	Line       int
+}
+
+func (r Reference) String() string {
+	return fmt.Sprintf("%s:%d: %s/%s", r.File, r.Line, r.Datasource, r.Key)
+}
+
+// Scan returns the datasource references found in a template.
+func Scan(file, text string) []Reference {
+	var refs []Reference
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		for _, m := range refPattern.FindAllStringSubmatch(scanner.Text(), -1) {
+			refs = append(refs, Reference{Datasource: m[1], Key: m[2], File: file, Line: line})
+		}
+	}
+	return refs
+}
+
+// ScanFiles scans every file below root matching the glob, e.g. DefaultGlob.
+// File names in the returned references are relative to root.
+func ScanFiles(root, glob string) ([]Reference, error) {
+	files, err := filepath.Glob(filepath.Join(root, glob))
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", glob, err)
+	}
+	sort.Strings(files)
+	var refs []Reference
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			rel = file
+		}
+		refs = append(refs, Scan(filepath.ToSlash(rel), string(data))...)
+	}
+	return refs, nil
+}
+
+// Keys groups the referenced keys by datasource, sorted and de-duplicated.
+func Keys(refs []Reference) map[string][]string {
+	set := map[string]map[string]bool{}
+	for _, r := range refs {
+		if set[r.Datasource] == nil {
+			set[r.Datasource] = map[string]bool{}
+		}
+		set[r.Datasource][r.Key] = true
+	}
+	keys := map[string][]string{}
+	for ds, ks := range set {
+		for k := range ks {
+			keys[ds] = append(keys[ds], k)
+		}
+		sort.Strings(keys[ds])
+	}
+	return keys
+}
+
+// MockValue returns the mock document written for a key, matching the format
+// the workflows use: {"value":"mock-<lowercase key>-value"}.
+func MockValue(key string) []byte {
+	data, _ := json.Marshal(map[string]string{"value": "mock-" + strings.ToLower(key) + "-value"})
+	return data
+}
+
+// Generate writes a mock file for every referenced key into <dir>/<datasource>/<key>,
+// so each datasource can be passed to the renderer as file://<dir>/<datasource>.
+func Generate(dir string, refs []Reference) error {
+	for ds, keys := range Keys(refs) {
+		dsDir := filepath.Join(dir, ds)
+		if err := os.MkdirAll(dsDir, 0755); err != nil {
+			return fmt.Errorf("failed to create mock directory %s: %w", dsDir, err)
+		}
+		for _, key := range keys {
+			file := filepath.Join(dsDir, filepath.FromSlash(key))
+			if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+				return fmt.Errorf("failed to create mock directory for %s: %w", key, err)
+			}
+			if err := os.WriteFile(file, MockValue(key), 0644); err != nil {
+				return fmt.Errorf("failed to write mock %s: %w", file, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Datasources returns render options that read every referenced datasource
+// from a mock directory written by Generate.
+func Datasources(dir string, refs []Reference) []render.Option {
+	var opts []render.Option
+	for ds := range Keys(refs) {
+		opts = append(opts, render.WithDatasource(ds, &render.DirDatasource{Dir: filepath.Join(dir, ds)}))
+	}
+	return opts
+}
+
+// DefaultDatasource is the datasource a checked-in mock holds unless named.
+const DefaultDatasource = "vault"
+
+// Mock is the checked-in keys of each datasource.
+type Mock map[string]map[string]bool
+
+// Has reports whether the key of the datasource is checked in.
+func (m Mock) Has(datasource, key string) bool {
+	return m[datasource][key]
+}
+
+// Add adds the keys of a datasource.
+func (m Mock) Add(datasource string, keys ...string) {
+	if m[datasource] == nil {
+		m[datasource] = map[string]bool{}
+	}
+	for _, key := range keys {
+		m[datasource][key] = true
+	}
+}
+
+// LoadCheckedIn returns the keys of a checked-in mock of a datasource. The
+// path is either a vaultMock.yaml file with a `vault_keys` list or a mock
+// directory such as scripts/vaultMock.
+func LoadCheckedIn(path, datasource string) (Mock, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checked-in mock %s: %w", path, err)
+	}
+	mock := Mock{}
+	if info.IsDir() {
+		err = filepath.WalkDir(path, func(file string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(path, file)
+			if err != nil {
+				return err
+			}
+			mock.Add(datasource, filepath.ToSlash(rel))
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list checked-in mock %s: %w", path, err)
+		}
+		return mock, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checked-in mock %s: %w", path, err)
+	}
+	var file struct {
+		VaultKeys []string `yaml:"vault_keys"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse checked-in mock %s: %w", path, err)
+	}
+	mock.Add(datasource, file.VaultKeys...)
+	return mock, nil
+}
+
+// Missing returns the references whose datasource and key are not part of
+// the checked-in mock.
+func Missing(refs []Reference, checkedIn Mock) []Reference {
+	var missing []Reference
+	for _, r := range refs {
+		if !checkedIn.Has(r.Datasource, r.Key) {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,130 @@
+package vaultmock_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitpkg/vaultmock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScan(t *testing.T) {
+	text := `secrets:
+  data:
+    connectorSettings: |
+{{(datasource "vault" "qcs_secrets_v2").value | base64.Encode | indent 6 }}
+  stringData:
+    redisPassword: {{ ( ds "vault" "redisPassword").value | printf "%q" }}
+    other: {{ (ds "automations_vault" "apiKey").value }}
+`
+	refs := vaultmock.Scan("qcs/c/values.yaml", text)
+
+	assert.Equal(t, []vaultmock.Reference{
+		{Datasource: "vault", Key: "qcs_secrets_v2", File: "qcs/c/values.yaml", Line: 4},
+		{Datasource: "vault", Key: "redisPassword", File: "qcs/c/values.yaml", Line: 6},
+		{Datasource: "automations_vault", Key: "apiKey", File: "qcs/c/values.yaml", Line: 7},
+	}, refs)
+}
+
+func TestScanFiles(t *testing.T) {
+	refs, err := vaultmock.ScanFiles("..", vaultmock.DefaultGlob)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"vault": {"enc_key_for_connector_settings_v1", "mongoURI", "qcs_secrets_v2", "redisPassword", "tokenEncryptionkey"},
+	}, vaultmock.Keys(refs))
+}
+
+func TestWriteSummary(t *testing.T) {
+	// Arrange
+	refs := []vaultmock.Reference{
+		{Datasource: "vault", Key: "redisPassword"},
+		{Datasource: "vault", Key: "mongoURI"},
+		{Datasource: "vault", Key: "redisPassword"},
+		{Datasource: "automations_vault", Key: "apiKey"},
+		{Datasource: "b_vault", Key: "token"},
+	}
+	var buf bytes.Buffer
+
+	// Act
+	vaultmock.WriteSummary(&buf, refs)
+
+	// Assert
+	assert.Equal(t, "datasource automations_vault: 1 keys\n"+
+		"datasource b_vault: 1 keys\n"+
+		"datasource vault: 2 keys\n", buf.String())
+}
+
+func TestGenerate(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	refs := []vaultmock.Reference{
+		{Datasource: "vault", Key: "redisPassword"},
+		{Datasource: "vault", Key: "redisPassword"},
+		{Datasource: "automations_vault", Key: "apiKey"},
+	}
+
+	// Act
+	err := vaultmock.Generate(dir, refs)
+
+	// Assert
+	assert.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(dir, "vault", "redisPassword"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"value":"mock-redispassword-value"}`, string(data))
+	_, err = os.Stat(filepath.Join(dir, "automations_vault", "apiKey"))
+	assert.NoError(t, err)
+}
+
+func TestMissing(t *testing.T) {
+	refs := []vaultmock.Reference{
+		{Datasource: "vault", Key: "redisPassword"},
+		{Datasource: "vault", Key: "mongoURI"},
+	}
+
+	t.Run("Missing compares against a vaultMock.yaml file", func(t *testing.T) {
+		// Arrange
+		file := filepath.Join(t.TempDir(), "vaultMock.yaml")
+		assert.NoError(t, os.WriteFile(file, []byte("vault_keys:\n  - redisPassword\n"), 0600))
+
+		// Act
+		keys, err := vaultmock.LoadCheckedIn(file, "vault")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []vaultmock.Reference{{Datasource: "vault", Key: "mongoURI"}}, vaultmock.Missing(refs, keys))
+	})
+
+	t.Run("Missing compares against a mock directory", func(t *testing.T) {
+		// Arrange
+		dir := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "mongoURI"), []byte(`{"value":"x"}`), 0600))
+
+		// Act
+		keys, err := vaultmock.LoadCheckedIn(dir, "vault")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []vaultmock.Reference{{Datasource: "vault", Key: "redisPassword"}}, vaultmock.Missing(refs, keys))
+	})
+
+	t.Run("Missing matches the datasource of the checked-in keys", func(t *testing.T) {
+		// Arrange
+		refs := []vaultmock.Reference{
+			{Datasource: "vault", Key: "apiKey"},
+			{Datasource: "automations_vault", Key: "apiKey"},
+		}
+		file := filepath.Join(t.TempDir(), "vaultMock.yaml")
+		assert.NoError(t, os.WriteFile(file, []byte("vault_keys:\n  - apiKey\n"), 0600))
+
+		// Act
+		keys, err := vaultmock.LoadCheckedIn(file, "vault")
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, []vaultmock.Reference{{Datasource: "automations_vault", Key: "apiKey"}}, vaultmock.Missing(refs, keys))
+	})
+}